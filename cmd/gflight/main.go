@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -15,6 +16,11 @@ func main() {
 func run(args []string, stderr io.Writer) int {
 	app := cli.NewApp("dev")
 	if err := app.Run(args); err != nil {
+		if cli.WantsJSON(args) {
+			b, _ := json.MarshalIndent(cli.NewErrorReport(err), "", "  ")
+			fmt.Fprintln(stderr, string(b))
+			return cli.ExitCode(err)
+		}
 		fmt.Fprintln(stderr, err)
 		for _, hint := range cli.ErrorHints(err) {
 			fmt.Fprintf(stderr, "next: %s\n", hint)
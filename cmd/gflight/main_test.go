@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
 )
@@ -31,3 +32,25 @@ func TestRunPrintsHintsForWatchRunSelectorError(t *testing.T) {
 		t.Fatalf("expected watch run hint, got: %q", stderr.String())
 	}
 }
+
+func TestRunReportsValidationProblemsAsJSON(t *testing.T) {
+	var stderr bytes.Buffer
+	code := run([]string{"--json", "search", "--from", "SFO", "--to", "ATH", "--depart", "2020-01-01", "--adults", "0"}, &stderr)
+	if code != 2 {
+		t.Fatalf("expected invalid usage code 2, got %d", code)
+	}
+	var report struct {
+		ExitCode int `json:"exit_code"`
+		Problems []struct {
+			Field       string `json:"field"`
+			Message     string `json:"message"`
+			Remediation string `json:"remediation"`
+		} `json:"problems"`
+	}
+	if err := json.Unmarshal(stderr.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON error report, got %q: %v", stderr.String(), err)
+	}
+	if report.ExitCode != 2 || len(report.Problems) != 2 || report.Problems[0].Field != "depart" || report.Problems[1].Field != "adults" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
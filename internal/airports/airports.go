@@ -0,0 +1,148 @@
+package airports
+
+import (
+	_ "embed"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//go:embed airports.tsv
+var airportsTSV string
+
+// Airport is one entry of the embedded dataset. Metro codes are listed too,
+// with the airports they cover in Airports.
+type Airport struct {
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	City      string   `json:"city"`
+	Country   string   `json:"country"`
+	Timezone  string   `json:"timezone"`
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Airports  []string `json:"airports,omitempty"`
+}
+
+var (
+	byCode = parseAirports(airportsTSV)
+	codes  = sortedCodes()
+)
+
+func parseAirports(data string) map[string]Airport {
+	out := map[string]Airport{}
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cols := strings.Split(line, "\t")
+		if len(cols) != 7 {
+			panic("airports: malformed airport line: " + line)
+		}
+		lat, err1 := strconv.ParseFloat(cols[5], 64)
+		lon, err2 := strconv.ParseFloat(cols[6], 64)
+		if err1 != nil || err2 != nil {
+			panic("airports: bad coordinates: " + line)
+		}
+		out[cols[0]] = Airport{Code: cols[0], Name: cols[1], City: cols[2], Country: cols[3], Timezone: cols[4], Latitude: lat, Longitude: lon}
+	}
+	return out
+}
+
+// metroAirport describes a metro code with the location of its first
+// airport and the centre of all of them.
+func metroAirport(m Metro) Airport {
+	first := byCode[m.Airports[0]]
+	a := Airport{Code: m.Code, Name: m.Name + " (all airports)", City: m.Name, Country: first.Country, Timezone: first.Timezone, Airports: m.Airports}
+	for _, code := range m.Airports {
+		a.Latitude += byCode[code].Latitude
+		a.Longitude += byCode[code].Longitude
+	}
+	n := float64(len(m.Airports))
+	a.Latitude = math.Round(a.Latitude/n*100) / 100
+	a.Longitude = math.Round(a.Longitude/n*100) / 100
+	return a
+}
+
+func sortedCodes() []string {
+	out := make([]string, 0, len(byCode)+len(metros))
+	for c := range byCode {
+		out = append(out, c)
+	}
+	for c := range metros {
+		out = append(out, c)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Lookup returns the airport or metro for an upper-case code.
+func Lookup(code string) (Airport, bool) {
+	if a, ok := byCode[code]; ok {
+		return a, true
+	}
+	if m, ok := metros[code]; ok {
+		return metroAirport(m), true
+	}
+	return Airport{}, false
+}
+
+// Codes returns every known airport and metro code, sorted.
+func Codes() []string {
+	return slices.Clone(codes)
+}
+
+// Search finds airports and metros by code, city, name or country code.
+// Exact code matches come first, then city matches, then name matches; a
+// metro sorts before its own airports.
+func Search(text string) []Airport {
+	q := strings.ToLower(strings.TrimSpace(text))
+	if q == "" {
+		return nil
+	}
+	type hit struct {
+		a    Airport
+		rank int
+	}
+	var hits []hit
+	for _, code := range codes {
+		a, _ := Lookup(code)
+		if r := matchRank(a, q); r >= 0 {
+			hits = append(hits, hit{a, r})
+		}
+	}
+	slices.SortStableFunc(hits, func(x, y hit) int {
+		if x.rank != y.rank {
+			return x.rank - y.rank
+		}
+		if (len(x.a.Airports) > 0) != (len(y.a.Airports) > 0) {
+			if len(x.a.Airports) > 0 {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(x.a.Code, y.a.Code)
+	})
+	out := make([]Airport, len(hits))
+	for i, h := range hits {
+		out[i] = h.a
+	}
+	return out
+}
+
+func matchRank(a Airport, q string) int {
+	city, name := strings.ToLower(a.City), strings.ToLower(a.Name)
+	switch {
+	case strings.ToLower(a.Code) == q:
+		return 0
+	case city == q:
+		return 1
+	case strings.HasPrefix(city, q):
+		return 2
+	case strings.Contains(name, q) || strings.Contains(city, q):
+		return 3
+	case strings.ToLower(a.Country) == q:
+		return 4
+	}
+	return -1
+}
@@ -0,0 +1,58 @@
+package airports
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestEmbeddedAirportsAreWellFormed(t *testing.T) {
+	if len(byCode) < 300 {
+		t.Fatalf("expected the full dataset, got %d airports", len(byCode))
+	}
+	for code, a := range byCode {
+		if len(code) != 3 || a.Name == "" || a.City == "" || len(a.Country) != 2 {
+			t.Fatalf("bad airport %q: %+v", code, a)
+		}
+		if a.Latitude < -90 || a.Latitude > 90 || a.Longitude < -180 || a.Longitude > 180 {
+			t.Fatalf("bad coordinates for %s: %+v", code, a)
+		}
+		if _, err := time.LoadLocation(a.Timezone); err != nil {
+			t.Fatalf("bad timezone for %s: %v", code, err)
+		}
+		if _, ok := metros[code]; ok {
+			t.Fatalf("%s is both an airport and a metro code", code)
+		}
+	}
+	for code, m := range metros {
+		for _, a := range m.Airports {
+			if _, ok := byCode[a]; !ok {
+				t.Fatalf("metro %s lists unknown airport %s", code, a)
+			}
+		}
+	}
+}
+
+func TestLookupMetro(t *testing.T) {
+	a, ok := Lookup("LON")
+	if !ok || a.Country != "GB" || a.Timezone != "Europe/London" || len(a.Airports) != 6 {
+		t.Fatalf("Lookup(LON) = %+v, %v", a, ok)
+	}
+	if _, ok := Lookup("SF0"); ok {
+		t.Fatal("expected unknown code")
+	}
+}
+
+func TestSearchRanksCodeThenCity(t *testing.T) {
+	hits := Search("athens")
+	if len(hits) == 0 || hits[0].Code != "ATH" {
+		t.Fatalf("Search(athens) = %+v", hits)
+	}
+	hits = Search("london")
+	if len(hits) < 7 || hits[0].Code != "LON" || hits[1].City != "London" {
+		t.Fatalf("expected the metro before its airports, got %+v", hits)
+	}
+	if hits := Search("jfk"); len(hits) != 1 || hits[0].Code != "JFK" {
+		t.Fatalf("Search(jfk) = %+v", hits)
+	}
+}
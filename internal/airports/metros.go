@@ -0,0 +1,63 @@
+// Package airports holds the embedded airport reference data.
+package airports
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed metros.tsv
+var metrosTSV string
+
+// Metro is a city code that covers several airports, e.g. NYC.
+type Metro struct {
+	Code     string
+	Name     string
+	Airports []string
+}
+
+var metros = parseMetros(metrosTSV)
+
+func parseMetros(data string) map[string]Metro {
+	out := map[string]Metro{}
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cols := strings.Split(line, "\t")
+		if len(cols) != 3 {
+			panic("airports: malformed metro line: " + line)
+		}
+		out[cols[0]] = Metro{Code: cols[0], Name: cols[1], Airports: strings.Split(cols[2], ",")}
+	}
+	return out
+}
+
+// LookupMetro returns the metro for an upper-case city code.
+func LookupMetro(code string) (Metro, bool) {
+	m, ok := metros[code]
+	return m, ok
+}
+
+// Expand replaces metro codes with their airports and drops duplicates,
+// keeping the first occurrence of each code.
+func Expand(codes []string) []string {
+	out := make([]string, 0, len(codes))
+	seen := map[string]bool{}
+	add := func(c string) {
+		if !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	for _, c := range codes {
+		if m, ok := metros[c]; ok {
+			for _, a := range m.Airports {
+				add(a)
+			}
+			continue
+		}
+		add(c)
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package airports
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpandMetroCodes(t *testing.T) {
+	got := Expand([]string{"NYC", "EWR", "ATH"})
+	want := []string{"JFK", "EWR", "LGA", "ATH"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Expand = %v, want %v", got, want)
+	}
+}
+
+func TestEmbeddedMetrosAreWellFormed(t *testing.T) {
+	if len(metros) == 0 {
+		t.Fatal("no metros loaded")
+	}
+	for code, m := range metros {
+		if len(code) != 3 || len(m.Airports) < 2 {
+			t.Fatalf("bad metro %q: %+v", code, m)
+		}
+		for _, a := range m.Airports {
+			if _, ok := metros[a]; ok {
+				t.Fatalf("metro %s lists metro code %s as an airport", code, a)
+			}
+		}
+	}
+}
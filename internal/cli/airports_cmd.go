@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/agisilaos/gflight/internal/airports"
+)
+
+type airportsSearchOutput struct {
+	Query   string             `json:"query"`
+	Total   int                `json:"total"`
+	Results []airports.Airport `json:"results"`
+}
+
+func (a App) cmdAirports(g globalFlags, args []string) error {
+	if len(args) == 0 {
+		return newExitError(ExitInvalidUsage, "usage: gflight airports search <text> [--limit N]")
+	}
+	switch args[0] {
+	case "search":
+		return a.cmdAirportsSearch(g, args[1:])
+	default:
+		if s := suggestClosest(args[0], []string{"search"}); s != "" {
+			return newExitError(ExitInvalidUsage, "unknown airports action %q (did you mean %q?)", args[0], s)
+		}
+		return newExitError(ExitInvalidUsage, "unknown airports action %q", args[0])
+	}
+}
+
+func (a App) cmdAirportsSearch(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("airports search", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	limit := fs.Int("limit", 10, "Maximum results (0 for all)")
+	words, flagArgs := splitSearchText(args)
+	if err := fs.Parse(flagArgs); err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	text := strings.TrimSpace(strings.Join(append(words, fs.Args()...), " "))
+	if text == "" {
+		return newExitError(ExitInvalidUsage, "usage: gflight airports search <text> [--limit N]")
+	}
+	if *limit < 0 {
+		return newExitError(ExitInvalidUsage, "--limit must be >= 0")
+	}
+	hits := airports.Search(text)
+	if len(hits) == 0 {
+		return newExitError(ExitNoMatches, "no airports match %q", text)
+	}
+	out := airportsSearchOutput{Query: text, Total: len(hits), Results: hits}
+	if *limit > 0 && len(hits) > *limit {
+		out.Results = hits[:*limit]
+	}
+	switch {
+	case g.JSON:
+		return writeJSON(out)
+	case g.Plain:
+		writePlainTableHeader("code", "name", "city", "country", "timezone", "latitude", "longitude", "airports")
+		for _, ap := range out.Results {
+			writePlainTableRow(
+				ap.Code,
+				ap.Name,
+				ap.City,
+				ap.Country,
+				ap.Timezone,
+				strconv.FormatFloat(ap.Latitude, 'f', 2, 64),
+				strconv.FormatFloat(ap.Longitude, 'f', 2, 64),
+				strings.Join(ap.Airports, ","),
+			)
+		}
+	default:
+		for _, ap := range out.Results {
+			line := fmt.Sprintf("%-4s %s, %s, %s  %s", ap.Code, ap.Name, ap.City, ap.Country, ap.Timezone)
+			if len(ap.Airports) > 0 {
+				line += "  [" + strings.Join(ap.Airports, " ") + "]"
+			}
+			fmt.Println(line)
+		}
+		if len(out.Results) < out.Total {
+			fmt.Printf("%d of %d matches shown (--limit 0 shows all)\n", len(out.Results), out.Total)
+		}
+	}
+	return nil
+}
+
+// splitSearchText separates the leading free-text words from the flags that
+// follow, so "airports search new york --limit 3" works.
+func splitSearchText(args []string) ([]string, []string) {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			return args[:i], args[i:]
+		}
+	}
+	return args, nil
+}
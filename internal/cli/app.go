@@ -17,6 +17,7 @@ type globalFlags struct {
 	NoColor  bool
 	StateDir string
 	Timeout  string
+	Deadline string
 	Help     bool
 	Version  bool
 }
@@ -51,21 +52,29 @@ func (a App) Run(args []string) error {
 		return nil
 	case "search":
 		return a.cmdSearch(g, argv)
+	case "airports":
+		return a.cmdAirports(g, argv)
 	case "watch":
 		return a.cmdWatch(g, argv)
+	case "plan":
+		return a.cmdPlan(g, argv)
+	case "apply":
+		return a.cmdApply(g, argv)
 	case "notify":
 		return a.cmdNotify(g, argv)
 	case "auth":
 		return a.cmdAuth(g, argv)
 	case "config":
 		return a.cmdConfig(g, argv)
+	case "state":
+		return a.cmdState(g, argv)
 	case "completion":
 		return a.cmdCompletion(g, argv)
 	case "doctor":
 		return a.cmdDoctor(g, argv)
 	default:
 		msg := "unknown command %q"
-		if s := suggestClosest(cmd, []string{"search", "watch", "notify", "auth", "config", "completion", "doctor", "help", "version"}); s != "" {
+		if s := suggestClosest(cmd, []string{"search", "airports", "watch", "plan", "apply", "notify", "auth", "config", "state", "completion", "doctor", "help", "version"}); s != "" {
 			msg = "unknown command %q (did you mean %q?)"
 			return newExitError(ExitInvalidUsage, msg+"\n\n%s", cmd, s, usageText())
 		}
@@ -81,17 +90,27 @@ USAGE:
 
 COMMANDS:
   search             One-shot flight search
+  airports search    Find airport and metro codes by code, city or name
   watch create       Create a watch
+  watch update       Edit an existing watch in place
   watch list         List watches
   watch enable       Enable a watch
   watch disable      Disable a watch
   watch delete       Delete a watch
   watch run          Execute watches and emit notifications
   watch test         Simulate a watch alert
+  watch show         Show a watch with its last evaluation
+  watch history      Show recorded price history for a watch
+  watch runs         Show recent watch run passes
+  watch export       Export watches as JSON
+  watch import       Import watches from an export file
+  plan -f FILE       Preview reconciling a watch manifest
+  apply -f FILE      Reconcile watches with a manifest
   notify test        Test notification channels
   auth login         Store API key interactively
   auth status        Show auth/config status
   config get/set     Read/write config values
+  state migrate      Upgrade the watch store schema
   completion         Generate shell completion script
   doctor             Run automation preflight checks
 
@@ -101,7 +120,8 @@ GLOBAL FLAGS:
   -q, --quiet        Suppress non-essential text
   -v, --verbose      Extra diagnostics to stderr
   --no-input         Disable prompts
-  --timeout DUR      Provider timeout override (e.g. 10s)
+  --timeout DUR      Per-attempt provider timeout override (e.g. 10s)
+  --deadline DUR     Deadline for the whole search or watch run pass (e.g. 2m)
   --state-dir PATH   Override state directory
   --version          Print version
   -h, --help         Show help
@@ -142,6 +162,12 @@ func parseGlobal(args []string) (globalFlags, []string, error) {
 			}
 			i++
 			g.Timeout = args[i]
+		case "--deadline":
+			if i+1 >= len(args) {
+				return g, nil, newExitError(ExitInvalidUsage, "--deadline requires a value like 2m")
+			}
+			i++
+			g.Deadline = args[i]
 		default:
 			rest = append(rest, a)
 		}
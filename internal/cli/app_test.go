@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/agisilaos/gflight/internal/model"
 	"github.com/agisilaos/gflight/internal/watcher"
@@ -16,7 +17,7 @@ func TestWatchEnableDisableAndDelete(t *testing.T) {
 	stateDir := t.TempDir()
 
 	app := NewApp("test")
-	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"}); err != nil {
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}); err != nil {
 		t.Fatalf("create watch: %v", err)
 	}
 
@@ -54,7 +55,7 @@ func TestWatchDeleteRequiresConfirmationOrForce(t *testing.T) {
 	stateDir := t.TempDir()
 
 	app := NewApp("test")
-	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"}); err != nil {
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}); err != nil {
 		t.Fatalf("create watch: %v", err)
 	}
 	id := onlyWatchID(t, stateDir)
@@ -84,6 +85,21 @@ func TestValidateQuery(t *testing.T) {
 	}
 }
 
+func TestQueryProblemsUseUTCDate(t *testing.T) {
+	// 20:00 on June 9 in UTC-7 is already June 10 in UTC, where watch runs
+	// drop the date.
+	now := time.Date(2030, 6, 9, 20, 0, 0, 0, time.FixedZone("PDT", -7*60*60))
+	q := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2030-06-09", Cabin: "economy", Adults: 1, Currency: "USD", SortBy: "price"}
+	problems := queryProblems(q, now)
+	if len(problems) != 1 || problems[0].Field != "depart" || !strings.Contains(problems[0].Message, "in the past") {
+		t.Fatalf("expected the departure past in UTC, got %v", problems)
+	}
+	q.Depart = "2030-06-10"
+	if problems := queryProblems(q, now); len(problems) != 0 {
+		t.Fatalf("expected today in UTC accepted, got %v", problems)
+	}
+}
+
 func TestParseGlobalFlagsAnywhere(t *testing.T) {
 	g, rest, err := parseGlobal([]string{"auth", "status", "--json", "--timeout", "5s"})
 	if err != nil {
@@ -141,7 +157,7 @@ func TestWatchRunReturnsAuthRequiredWhenProviderCredentialsMissing(t *testing.T)
 	stateDir := t.TempDir()
 	app := NewApp("test")
 
-	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"}); err != nil {
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}); err != nil {
 		t.Fatalf("create watch: %v", err)
 	}
 
@@ -157,7 +173,7 @@ func TestWatchCreatePlainPrintsStableID(t *testing.T) {
 	app := NewApp("test")
 
 	out, err := captureStdoutForRun(t, func() error {
-		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"})
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"})
 	})
 	if err != nil {
 		t.Fatalf("watch create failed: %v", err)
@@ -172,7 +188,7 @@ func TestWatchDeletePlainPrintsDeletedID(t *testing.T) {
 	stateDir := t.TempDir()
 	app := NewApp("test")
 
-	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"}); err != nil {
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}); err != nil {
 		t.Fatalf("create watch: %v", err)
 	}
 	id := onlyWatchID(t, stateDir)
@@ -192,7 +208,7 @@ func TestWatchListPlainUsesStableSchema(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 	stateDir := t.TempDir()
 	app := NewApp("test")
-	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"}); err != nil {
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--tag", "trip:summer"}); err != nil {
 		t.Fatalf("create watch: %v", err)
 	}
 	out, err := captureStdoutForRun(t, func() error {
@@ -205,12 +221,12 @@ func TestWatchListPlainUsesStableSchema(t *testing.T) {
 	if len(lines) < 2 {
 		t.Fatalf("expected header+row output, got: %q", out)
 	}
-	if lines[0] != "id\tname\tenabled\ttarget_price\tfrom\tto\tdepart" {
+	if lines[0] != "id\tname\tenabled\ttarget_price\tfrom\tto\tdepart\tschedule\tnext_due_at\ttags" {
 		t.Fatalf("unexpected header: %q", lines[0])
 	}
 	cols := strings.Split(lines[1], "\t")
-	if len(cols) != 7 {
-		t.Fatalf("expected 7 columns in row, got %d (%q)", len(cols), lines[1])
+	if len(cols) != 10 {
+		t.Fatalf("expected 10 columns in row, got %d (%q)", len(cols), lines[1])
 	}
 }
 
@@ -366,7 +382,7 @@ func TestSearchPlainPrintsHeaderAndURL(t *testing.T) {
 		t.Fatalf("auth login: %v", err)
 	}
 	out, err := captureStdoutForRun(t, func() error {
-		return app.Run([]string{"--plain", "search", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"})
+		return app.Run([]string{"--plain", "search", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"})
 	})
 	if err != nil {
 		t.Fatalf("search plain failed: %v", err)
@@ -375,7 +391,7 @@ func TestSearchPlainPrintsHeaderAndURL(t *testing.T) {
 	if len(lines) < 2 {
 		t.Fatalf("expected header and url lines, got: %q", out)
 	}
-	if lines[0] != "price\tcurrency\tairline\tdepart_time\tarrive_time\tstops" {
+	if lines[0] != "price\tcurrency\tairline\tdepart_time\tarrive_time\tstops\tduration_minutes\troute\tco2_grams\treturn_options\tdeep_link" {
 		t.Fatalf("unexpected search plain header: %q", lines[0])
 	}
 	if !strings.HasPrefix(lines[len(lines)-1], "url=") {
@@ -390,7 +406,7 @@ func TestWatchRunPlainPrintsSummaryKeyValues(t *testing.T) {
 	if err := app.Run([]string{"auth", "login", "--provider", "google-url"}); err != nil {
 		t.Fatalf("auth login: %v", err)
 	}
-	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"}); err != nil {
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}); err != nil {
 		t.Fatalf("create watch: %v", err)
 	}
 	out, err := captureStdoutForRun(t, func() error {
@@ -441,3 +457,87 @@ func onlyWatch(t *testing.T, stateDir string) model.Watch {
 	}
 	return ws.Watches[0]
 }
+
+func TestWatchCommandsWithJSONLDirBackend(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GFLIGHT_STORAGE_BACKEND", "jsonl-dir")
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	for _, to := range []string{"ATH", "FCO"} {
+		if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", to, "--depart", "2030-06-10"}); err != nil {
+			t.Fatalf("create %s: %v", to, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "watches.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected no watches.json with jsonl-dir backend, got %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(stateDir, "watches", "*.jsonl"))
+	if len(files) != 2 {
+		t.Fatalf("expected one file per watch, got %v", files)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "delete", "--route", "SFO-FCO", "--force"}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "list"})
+	})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.Contains(lines[1], "SFO-ATH") {
+		t.Fatalf("unexpected list: %q", out)
+	}
+}
+
+func TestSearchRejectsInvalidOptions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := NewApp("test")
+	for _, args := range [][]string{
+		{"search", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--cabin", "steerage"},
+		{"search", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--sort", "cheapest"},
+		{"search", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--stops", "many"},
+		{"search", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--return-options", "2"},
+	} {
+		if err := app.Run(args); ExitCode(err) != ExitInvalidUsage {
+			t.Fatalf("expected invalid usage for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestQueryProblemsListsEveryField(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	q := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-10-01", Cabin: "coach", Adults: 0, Children: -1, Currency: "USDD"}
+	var fields []string
+	for _, p := range queryProblems(q, now) {
+		if p.Remediation == "" {
+			t.Fatalf("expected remediation for %+v", p)
+		}
+		fields = append(fields, p.Field)
+	}
+	if got := strings.Join(fields, ","); got != "depart,adults,children,cabin,currency" {
+		t.Fatalf("unexpected problem fields %q", got)
+	}
+
+	q = model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-11-10", Return: "2026-11-01", Adults: 6, Children: 4}
+	problems := queryProblems(q, now)
+	if len(problems) != 2 || problems[0].Field != "return" || problems[1].Message != "10 passengers exceed the limit of 9 per search" {
+		t.Fatalf("unexpected problems %+v", problems)
+	}
+	if problems := queryProblems(model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026/11/10", Adults: 1}, now); len(problems) != 1 || problems[0].Message != `invalid date "2026/11/10"` {
+		t.Fatalf("expected malformed date problem, got %+v", problems)
+	}
+	if problems := queryProblems(model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-10-17", Adults: 1, Currency: "eur"}, now); len(problems) != 0 {
+		t.Fatalf("expected today and lower-case currency to pass, got %+v", problems)
+	}
+}
+
+func TestValidationErrorHintsListRemediations(t *testing.T) {
+	err := validateQuery(model.SearchQuery{From: "SFO", To: "ATH", Depart: "2030-06-10", Adults: 0, Cabin: "coach"})
+	if !strings.HasPrefix(err.Error(), "invalid query (2 problems):\n  --adults: must be at least 1") {
+		t.Fatalf("unexpected error text %q", err.Error())
+	}
+	hints := ErrorHints(err)
+	if len(hints) != 2 || hints[0] != "pass --adults 1 or more" {
+		t.Fatalf("unexpected hints %v", hints)
+	}
+}
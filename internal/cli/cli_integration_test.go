@@ -35,7 +35,7 @@ func TestCLIIntegrationCoreAgentFlows(t *testing.T) {
 		},
 		{
 			name:     "search json",
-			args:     []string{"--json", "search", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"},
+			args:     []string{"--json", "search", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"},
 			exitCode: ExitSuccess,
 			wantJSON: true,
 		},
@@ -83,7 +83,7 @@ func TestCLIIntegrationWatchLifecycle(t *testing.T) {
 		t.Fatalf("auth login failed code=%d", code)
 	}
 
-	stdout, stderr, code, _ := runCLIWithCapture(t, app, []string{"--state-dir", stateDir, "--json", "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"})
+	stdout, stderr, code, _ := runCLIWithCapture(t, app, []string{"--state-dir", stateDir, "--json", "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"})
 	if code != ExitSuccess {
 		t.Fatalf("watch create failed code=%d stderr=%s", code, stderr)
 	}
@@ -60,10 +60,12 @@ _gflight_completions() {
   local cur prev words cword
   _init_completion -n : || return
 
-  local commands="search watch notify auth config completion doctor help version"
-  local watch_sub="create list enable disable delete run test"
+  local commands="search airports watch plan apply notify auth config state completion doctor help version"
+  local watch_sub="create update list enable disable delete run test show history runs export import"
   local auth_sub="login status"
   local config_sub="get set"
+  local state_sub="migrate"
+  local airports_sub="search"
 
   if [[ ${cword} -eq 1 ]]; then
     COMPREPLY=( $(compgen -W "${commands}" -- "${cur}") )
@@ -74,6 +76,8 @@ _gflight_completions() {
     watch) COMPREPLY=( $(compgen -W "${watch_sub}" -- "${cur}") ) ;;
     auth) COMPREPLY=( $(compgen -W "${auth_sub}" -- "${cur}") ) ;;
     config) COMPREPLY=( $(compgen -W "${config_sub}" -- "${cur}") ) ;;
+    state) COMPREPLY=( $(compgen -W "${state_sub}" -- "${cur}") ) ;;
+    airports) COMPREPLY=( $(compgen -W "${airports_sub}" -- "${cur}") ) ;;
     completion) COMPREPLY=( $(compgen -W "bash zsh fish" -- "${cur}") ) ;;
   esac
 }
@@ -87,10 +91,14 @@ _gflight() {
   local -a commands
   commands=(
     'search:One-shot flight search'
+    'airports:Find airport codes'
     'watch:Manage watches'
+    'plan:Preview a watch manifest'
+    'apply:Apply a watch manifest'
     'notify:Test notifications'
     'auth:Manage provider auth'
     'config:Read or write config'
+    'state:Manage the watch store'
     'completion:Generate shell completion'
     'doctor:Run preflight checks'
     'help:Show help'
@@ -98,11 +106,15 @@ _gflight() {
   )
 
   local -a watch_sub
-  watch_sub=('create' 'list' 'enable' 'disable' 'delete' 'run' 'test')
+  watch_sub=('create' 'update' 'list' 'enable' 'disable' 'delete' 'run' 'test' 'show' 'history' 'runs' 'export' 'import')
   local -a auth_sub
   auth_sub=('login' 'status')
   local -a config_sub
   config_sub=('get' 'set')
+  local -a state_sub
+  state_sub=('migrate')
+  local -a airports_sub
+  airports_sub=('search')
 
   if (( CURRENT == 2 )); then
     _describe 'command' commands
@@ -113,6 +125,8 @@ _gflight() {
     watch) _describe 'watch command' watch_sub ;;
     auth) _describe 'auth command' auth_sub ;;
     config) _describe 'config action' config_sub ;;
+    state) _describe 'state action' state_sub ;;
+    airports) _describe 'airports action' airports_sub ;;
     completion) _values 'shell' bash zsh fish ;;
   esac
 }
@@ -123,18 +137,24 @@ _gflight "$@"
 func fishCompletionScript() string {
 	return `complete -c gflight -f
 complete -c gflight -n '__fish_use_subcommand' -a 'search' -d 'One-shot flight search'
+complete -c gflight -n '__fish_use_subcommand' -a 'airports' -d 'Find airport codes'
 complete -c gflight -n '__fish_use_subcommand' -a 'watch' -d 'Manage watches'
+complete -c gflight -n '__fish_use_subcommand' -a 'plan' -d 'Preview a watch manifest'
+complete -c gflight -n '__fish_use_subcommand' -a 'apply' -d 'Apply a watch manifest'
 complete -c gflight -n '__fish_use_subcommand' -a 'notify' -d 'Test notifications'
 complete -c gflight -n '__fish_use_subcommand' -a 'auth' -d 'Manage provider auth'
 complete -c gflight -n '__fish_use_subcommand' -a 'config' -d 'Read or write config'
+complete -c gflight -n '__fish_use_subcommand' -a 'state' -d 'Manage the watch store'
 complete -c gflight -n '__fish_use_subcommand' -a 'completion' -d 'Generate shell completion'
 complete -c gflight -n '__fish_use_subcommand' -a 'doctor' -d 'Run preflight checks'
 complete -c gflight -n '__fish_use_subcommand' -a 'help' -d 'Show help'
 complete -c gflight -n '__fish_use_subcommand' -a 'version' -d 'Show version'
 
-complete -c gflight -n '__fish_seen_subcommand_from watch' -a 'create list enable disable delete run test'
+complete -c gflight -n '__fish_seen_subcommand_from watch' -a 'create update list enable disable delete run test show history runs export import'
 complete -c gflight -n '__fish_seen_subcommand_from auth' -a 'login status'
 complete -c gflight -n '__fish_seen_subcommand_from config' -a 'get set'
+complete -c gflight -n '__fish_seen_subcommand_from state' -a 'migrate'
+complete -c gflight -n '__fish_seen_subcommand_from airports' -a 'search'
 complete -c gflight -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'
 `
 }
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/watcher"
 )
 
 func configGet(cfg config.Config, key string) (string, bool) {
@@ -32,6 +33,20 @@ func configGet(cfg config.Config, key string) (string, bool) {
 		return strconv.Itoa(cfg.ProviderBackoffMS), true
 	case "webhook_url":
 		return cfg.WebhookURL, true
+	case "check_interval":
+		return cfg.CheckInterval, true
+	case "history_retention_days":
+		return strconv.Itoa(cfg.HistoryRetention), true
+	case "storage_backend":
+		return cfg.StorageBackend, true
+	case "watch_concurrency":
+		return strconv.Itoa(cfg.WatchConcurrency), true
+	case "provider_max_concurrency":
+		return strconv.Itoa(cfg.ProviderMaxConcurrency), true
+	case "provider_max_return_lookups":
+		return strconv.Itoa(cfg.ProviderMaxReturnLookups), true
+	case "provider_max_booking_lookups":
+		return strconv.Itoa(cfg.ProviderMaxBookingLookups), true
 	default:
 		return "", false
 	}
@@ -84,6 +99,46 @@ func configSet(cfg *config.Config, key, value string) error {
 		cfg.ProviderBackoffMS = n
 	case "webhook_url":
 		cfg.WebhookURL = value
+	case "check_interval":
+		if _, err := parseCheckInterval(value); err != nil {
+			return fmt.Errorf("check_interval %v", err)
+		}
+		cfg.CheckInterval = value
+	case "history_retention_days":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("history_retention_days must be positive integer")
+		}
+		cfg.HistoryRetention = n
+	case "storage_backend":
+		if value != watcher.BackendJSON && value != watcher.BackendJSONLDir {
+			return fmt.Errorf("storage_backend must be %s or %s", watcher.BackendJSON, watcher.BackendJSONLDir)
+		}
+		cfg.StorageBackend = value
+	case "watch_concurrency":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > config.MaxWatchConcurrency {
+			return fmt.Errorf("watch_concurrency must be an integer from 1 to %d", config.MaxWatchConcurrency)
+		}
+		cfg.WatchConcurrency = n
+	case "provider_max_concurrency":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("provider_max_concurrency must be integer >= 0 (0 uses the provider default)")
+		}
+		cfg.ProviderMaxConcurrency = n
+	case "provider_max_return_lookups":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("provider_max_return_lookups must be integer >= 0 (0 uses the provider default)")
+		}
+		cfg.ProviderMaxReturnLookups = n
+	case "provider_max_booking_lookups":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("provider_max_booking_lookups must be integer >= 0 (0 uses the provider default)")
+		}
+		cfg.ProviderMaxBookingLookups = n
 	default:
 		return fmt.Errorf("unknown key %q", key)
 	}
@@ -70,3 +70,42 @@ func TestConfigSetWebhookURL(t *testing.T) {
 		t.Fatalf("expected webhook_url from configGet, got ok=%t v=%q", ok, v)
 	}
 }
+
+func TestConfigSetCheckIntervalValidation(t *testing.T) {
+	cfg := config.Config{}
+	if err := configSet(&cfg, "check_interval", "5s"); err == nil {
+		t.Fatalf("expected error for interval below minimum")
+	}
+	if err := configSet(&cfg, "check_interval", "30m"); err != nil {
+		t.Fatalf("set check_interval: %v", err)
+	}
+	if v, _ := configGet(cfg, "check_interval"); v != "30m" {
+		t.Fatalf("expected check_interval 30m, got %q", v)
+	}
+}
+
+func TestConfigSetStorageBackendValidation(t *testing.T) {
+	cfg := config.Config{}
+	if err := configSet(&cfg, "storage_backend", "sqlite"); err == nil {
+		t.Fatalf("expected invalid storage_backend error")
+	}
+	if err := configSet(&cfg, "storage_backend", "jsonl-dir"); err != nil || cfg.StorageBackend != "jsonl-dir" {
+		t.Fatalf("expected jsonl-dir backend, got %q err=%v", cfg.StorageBackend, err)
+	}
+}
+
+func TestWatchConcurrencyEnvStaysWithinLimit(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GFLIGHT_WATCH_CONCURRENCY", "1000")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.WatchConcurrency != 4 {
+		t.Fatalf("expected an out-of-range env value ignored, got %d", cfg.WatchConcurrency)
+	}
+	t.Setenv("GFLIGHT_WATCH_CONCURRENCY", "64")
+	if cfg, _ := config.Load(); cfg.WatchConcurrency != config.MaxWatchConcurrency {
+		t.Fatalf("expected the limit itself accepted, got %d", cfg.WatchConcurrency)
+	}
+}
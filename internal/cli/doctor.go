@@ -6,8 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/watcher"
 )
 
 type doctorCheck struct {
@@ -85,6 +87,19 @@ func runDoctorChecks(cfg config.Config, stateOverride string) doctorReport {
 		add("paths.state", "fail", err.Error())
 	} else {
 		add("paths.state", "ok", dir)
+		if st, err := watcher.Open(dir, cfg.StorageBackend); err != nil {
+			add("state.backend", "fail", err.Error())
+		} else {
+			add("state.backend", "ok", fmt.Sprintf("%s (%s)", firstOr(cfg.StorageBackend, watcher.BackendJSON), st.Watches.Location()))
+			addStoreChecks(add, watcher.WithLockTimeout(st.Watches, time.Second))
+			if other, n, err := watcher.StrayWatches(dir, cfg.StorageBackend); err != nil {
+				add("state.stray", "warn", fmt.Sprintf("%s storage: %v", other, err))
+			} else if n > 0 {
+				add("state.stray", "warn", fmt.Sprintf("%d watch(es) stored with the %s backend are hidden; run gflight state migrate --from-backend %s", n, other, other))
+			} else {
+				add("state.stray", "ok", "no watches stored with another backend")
+			}
+		}
 	}
 
 	report := doctorReport{Checks: checks}
@@ -100,6 +115,22 @@ func runDoctorChecks(cfg config.Config, stateOverride string) doctorReport {
 	return report
 }
 
+func addStoreChecks(add func(name, status, message string), store watcher.Backend) {
+	if unlock, err := store.Lock(); err != nil {
+		add("state.lock", "warn", err.Error())
+	} else {
+		unlock()
+		add("state.lock", "ok", "watch store lock is free")
+	}
+	if res, err := store.PendingMigrations(); err != nil {
+		add("state.schema", "fail", err.Error())
+	} else if len(res.Pending) > 0 {
+		add("state.schema", "warn", fmt.Sprintf("watch store schema v%d is older than v%d; run gflight state migrate", res.FromVersion, res.ToVersion))
+	} else {
+		add("state.schema", "ok", fmt.Sprintf("watch store schema v%d", res.ToVersion))
+	}
+}
+
 func ensureWritableDir(dir string) error {
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
@@ -1,11 +1,13 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/agisilaos/gflight/internal/provider"
+	"github.com/agisilaos/gflight/internal/watcher"
 )
 
 const (
@@ -70,6 +72,12 @@ func wrapProviderError(err error) error {
 	if errors.Is(err, provider.ErrAuthRequired) || errors.Is(err, errProviderAuthMissing) {
 		return wrapExitError(ExitAuthRequired, err)
 	}
+	if errors.Is(err, provider.ErrUnsupportedQuery) {
+		return wrapExitError(ExitInvalidUsage, err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return wrapExitError(ExitGenericFailure, err)
+	}
 	if errors.Is(err, provider.ErrRateLimited) || errors.Is(err, provider.ErrTransient) {
 		return wrapExitError(ExitProviderFailure, err)
 	}
@@ -93,11 +101,41 @@ func wrapNotifyError(err error) error {
 	return wrapExitError(ExitNotifyFailure, err)
 }
 
+// ErrorReport is the --json form of a failed command.
+type ErrorReport struct {
+	Error    string       `json:"error"`
+	ExitCode int          `json:"exit_code"`
+	Problems []FieldError `json:"problems,omitempty"`
+	Hints    []string     `json:"hints,omitempty"`
+}
+
+func NewErrorReport(err error) ErrorReport {
+	report := ErrorReport{Error: err.Error(), ExitCode: ExitCode(err), Hints: ErrorHints(err)}
+	var verr ValidationError
+	if errors.As(err, &verr) {
+		report.Problems = verr.Problems
+	}
+	return report
+}
+
+// WantsJSON reports whether args select --json output, so errors can be
+// reported in the same format.
+func WantsJSON(args []string) bool {
+	g, _, _ := parseGlobal(args)
+	return g.JSON
+}
+
 func ErrorHints(err error) []string {
 	if err == nil {
 		return nil
 	}
 	hints := make([]string, 0, 2)
+	var verr ValidationError
+	if errors.As(err, &verr) {
+		for _, p := range verr.Problems {
+			hints = append(hints, p.Remediation)
+		}
+	}
 	switch {
 	case errors.Is(err, errProviderAuthMissing):
 		hints = append(hints,
@@ -106,6 +144,15 @@ func ErrorHints(err error) []string {
 		)
 	case errors.Is(err, errWebhookMissing):
 		hints = append(hints, "gflight config set webhook_url https://example.com/hook")
+	case errors.Is(err, watcher.ErrLocked):
+		hints = append(hints,
+			"retry once the other gflight command finishes (a watch run --daemon pass releases the lock after saving)",
+			"gflight doctor",
+		)
+	case errors.Is(err, context.DeadlineExceeded):
+		hints = append(hints, "raise --deadline (e.g. --deadline 5m) or lower provider_retries")
+	case errors.Is(err, watcher.ErrUnsupportedSchema):
+		hints = append(hints, "install a newer gflight release to read this state file")
 	case errors.Is(err, errSMTPIncomplete):
 		hints = append(hints,
 			"gflight config set smtp_host smtp.gmail.com",
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/agisilaos/gflight/internal/provider"
+	"github.com/agisilaos/gflight/internal/watcher"
 )
 
 func TestExitCode(t *testing.T) {
@@ -80,4 +82,12 @@ func TestErrorHints(t *testing.T) {
 			t.Fatalf("unexpected selector hints: %v", hints)
 		}
 	})
+
+	t.Run("store locked", func(t *testing.T) {
+		err := wrapExitError(ExitGenericFailure, fmt.Errorf("%w (waited 10s for watches.json.lock)", watcher.ErrLocked))
+		hints := ErrorHints(err)
+		if len(hints) != 2 || hints[1] != "gflight doctor" {
+			t.Fatalf("unexpected lock hints: %v", hints)
+		}
+	})
 }
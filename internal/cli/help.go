@@ -33,14 +33,41 @@ func watchRunHelpText() string {
 USAGE:
   gflight watch run --all [--once] [--fail-on-provider-errors] [global flags]
   gflight watch run --id <watch-id> [--once] [--fail-on-provider-errors] [global flags]
+  gflight watch run [--tag <tag>]... [--name-glob <glob>] [--route FROM-TO] [global flags]
+  gflight watch run --due [--id <watch-id>] [global flags]
+  gflight watch run --all --daemon [global flags]
+  gflight watch run --all --concurrency 8 [global flags]
 
 RULES:
-  - Exactly one selector is required: --all or --id
+  - A selection is required: --all, --id, or selectors (--due alone implies --all)
+  - --tag (repeatable), --name-glob and --route narrow the selection; all must match
+  - --all cannot be combined with other selectors
+  - --due only evaluates watches whose schedule or check_interval has come due since last_run_at
+  - --concurrency N runs up to N provider searches in parallel (default config
+    watch_concurrency, 4), capped by the provider limit (serpapi: provider_max_concurrency, default 4)
+  - Reports, alerts and notifications keep watch order regardless of which search finishes first
   - Default provider failure policy exits 4 only when all evaluated provider requests fail
   - --fail-on-provider-errors exits 4 on any provider failure
+  - --deadline bounds the whole pass (each pass with --daemon); Ctrl-C cancels in-flight searches
+  - An interrupted pass saves the watches it finished, reports the rest as skipped, and exits
+    4 on deadline or 1 on cancel
+
+DAEMON:
+  - --daemon keeps running and evaluates each watch on its own schedule or check_interval
+  - A cron schedule (e.g. "0 */4 * * *", @hourly) takes precedence over check_interval
+  - Watches without check_interval use config check_interval (default 15m)
+  - State is saved after every pass; SIGINT/SIGTERM stop the daemon cleanly
+  - Provider and notify failures are reported per pass and do not stop the daemon
+
+ALERTS:
+  - Each watch moves armed -> fired -> rearmed; a fired watch re-alerts only when the
+    price drops below the fired price or after rebounding above its rearm band
+  - Watches with a cooldown never alert more than once per cooldown window
+  - Suppressed alerts are reported separately and do not notify
 
 OUTPUT:
-  - --json: emits summary object with evaluated/triggered/provider_failures/notify_failures/alerts
+  - --json: emits summary object with evaluated/triggered/suppressed/provider_failures/notify_failures/skipped/interrupted/alerts/suppressed_alerts
+  - --json --daemon: emits one compact summary object per line for each pass
   - human: emits summary line and any alert notifications
 `
 }
@@ -54,6 +81,7 @@ USAGE:
 CHECKS:
   - provider authentication readiness
   - config/state path writability
+  - storage backend, watch store lock and schema version
   - email/webhook notification readiness
 
 BEHAVIOR:
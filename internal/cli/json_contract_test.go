@@ -17,11 +17,11 @@ func TestJSONContractCommandsProduceParseableStdout(t *testing.T) {
 	mustJSONRun(t, func() error { return app.Run([]string{"--json", "auth", "login", "--provider", "google-url"}) })
 	mustJSONRun(t, func() error { return app.Run([]string{"--json", "doctor"}) })
 	mustJSONRun(t, func() error {
-		return app.Run([]string{"--json", "search", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10"})
+		return app.Run([]string{"--json", "search", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"})
 	})
 
 	mustJSONRun(t, func() error {
-		return app.Run([]string{"--json", "--state-dir", stateDir, "watch", "create", "--name", "a", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10", "--notify-terminal"})
+		return app.Run([]string{"--json", "--state-dir", stateDir, "watch", "create", "--name", "a", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--notify-terminal"})
 	})
 
 	store := watcher.Store{Path: filepath.Join(stateDir, "watches.json")}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/config"
+)
+
+type manifestOutput struct {
+	manifestPlan
+	Manifest string `json:"manifest"`
+	Applied  bool   `json:"applied"`
+}
+
+func (a App) cmdPlan(g globalFlags, args []string) error {
+	return a.runManifest(g, "plan", args)
+}
+
+func (a App) cmdApply(g globalFlags, args []string) error {
+	return a.runManifest(g, "apply", args)
+}
+
+func (a App) runManifest(g globalFlags, name string, args []string) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	file := fs.String("f", "", "Manifest file (- for stdin)")
+	fs.StringVar(file, "file", "", "Manifest file (- for stdin)")
+	force := new(bool)
+	if name == "apply" {
+		force = fs.Bool("force", false, "Allow the apply to delete watches")
+	}
+	if err := fs.Parse(args); err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if *file == "" {
+		return newExitError(ExitInvalidUsage, "-f <manifest.json> is required")
+	}
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return newExitError(ExitInvalidUsage, "%v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	m, err := parseWatchManifest(r)
+	if err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	st, err := openWatchStorage(g.StateDir, cfg)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	unlock, err := lockStorage(st)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	ws, err := st.Watches.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	plan, err := planManifest(m, ws.Watches, cfg, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, act := range plan.Actions {
+		if act.Action == manifestSkip {
+			fmt.Fprintf(os.Stderr, "warning: manifest watch %q skipped: %s\n", act.Key, act.Reason)
+		}
+	}
+	apply := name == "apply"
+	if apply && plan.Delete > 0 && !*force {
+		return newExitError(ExitInvalidUsage, "destructive action: plan deletes %d watch(es); review with gflight plan and pass --force", plan.Delete)
+	}
+	if apply {
+		ws.Watches = plan.watches
+		if err := st.Watches.Save(ws); err != nil {
+			return wrapExitError(ExitGenericFailure, err)
+		}
+		deleted := append([]string(nil), plan.reset...)
+		for _, act := range plan.Actions {
+			if act.Action == manifestDelete {
+				deleted = append(deleted, act.WatchID)
+			}
+		}
+		if err := deleteWatchArtifacts(st, deleted); err != nil {
+			return err
+		}
+	} else {
+		// IDs for new watches are assigned when the plan is applied.
+		for i := range plan.Actions {
+			if plan.Actions[i].Action == manifestCreate {
+				plan.Actions[i].WatchID = ""
+			}
+		}
+	}
+
+	out := manifestOutput{manifestPlan: plan, Manifest: *file, Applied: apply}
+	if g.JSON {
+		return writeJSON(out)
+	}
+	if g.Plain {
+		writePlainKV(
+			"create", strconv.Itoa(plan.Create),
+			"update", strconv.Itoa(plan.Update),
+			"delete", strconv.Itoa(plan.Delete),
+			"unchanged", strconv.Itoa(plan.Unchanged),
+			"skipped", strconv.Itoa(plan.Skipped),
+			"applied", strconv.FormatBool(apply),
+		)
+		for _, act := range plan.Actions {
+			writePlainKV("action", act.Action, "key", act.Key, "watch_id", act.WatchID, "history_reset", strconv.FormatBool(act.HistoryReset))
+			for _, c := range act.Changes {
+				writePlainKV("key", act.Key, "field", c.Field, "old", c.Old, "new", c.New)
+			}
+		}
+		return nil
+	}
+	writeManifestPlanHuman(out)
+	return nil
+}
+
+func writeManifestPlanHuman(out manifestOutput) {
+	if len(out.Actions) == 0 {
+		fmt.Printf("No changes. %d watch(es) in %s match the store.\n", out.Unchanged, out.Manifest)
+		return
+	}
+	for _, act := range out.Actions {
+		switch act.Action {
+		case manifestCreate:
+			fmt.Printf("  + create %s", act.Key)
+		case manifestUpdate:
+			fmt.Printf("  ~ update %s", act.Key)
+		case manifestDelete:
+			fmt.Printf("  - delete %s", act.Key)
+		case manifestSkip:
+			fmt.Printf("  ! skip   %s", act.Key)
+		}
+		if act.WatchID != "" {
+			fmt.Printf(" (%s)", act.WatchID)
+		}
+		fmt.Println()
+		for _, c := range act.Changes {
+			fmt.Printf("      %s: %s -> %s\n", c.Field, firstOr(c.Old, `""`), firstOr(c.New, `""`))
+		}
+		if act.HistoryReset {
+			fmt.Println("      fare changed: price history, snapshot and alert state are deleted")
+		}
+	}
+	if out.Applied {
+		fmt.Printf("Applied: %d created, %d updated, %d deleted, %d unchanged, %d skipped.\n", out.Create, out.Update, out.Delete, out.Unchanged, out.Skipped)
+		return
+	}
+	fmt.Printf("Plan: %d to create, %d to update, %d to delete, %d unchanged, %d skipped.\n", out.Create, out.Update, out.Delete, out.Unchanged, out.Skipped)
+}
@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+const (
+	manifestCreate = "create"
+	manifestUpdate = "update"
+	manifestDelete = "delete"
+	manifestSkip   = "skip"
+)
+
+// watchManifest is the desired set of managed watches. Watches are matched to
+// stored ones by Key; stored watches without a key are never touched.
+type watchManifest struct {
+	Watches []manifestWatch `json:"watches"`
+}
+
+type manifestWatch struct {
+	Key            string            `json:"key"`
+	Name           string            `json:"name"`
+	Tags           []string          `json:"tags"`
+	Enabled        *bool             `json:"enabled"`
+	Query          model.SearchQuery `json:"query"`
+	TargetPrice    int               `json:"target_price"`
+	Rules          []string          `json:"rules"`
+	Cooldown       string            `json:"cooldown"`
+	RearmPercent   *float64          `json:"rearm_percent"`
+	NotifyTerminal *bool             `json:"notify_terminal"`
+	NotifyEmail    bool              `json:"notify_email"`
+	NotifyWebhook  bool              `json:"notify_webhook"`
+	EmailTo        string            `json:"email_to"`
+	WebhookURL     string            `json:"webhook_url"`
+	CheckInterval  string            `json:"check_interval"`
+	Schedule       string            `json:"schedule"`
+	MaxRequests    int               `json:"max_requests"`
+}
+
+type manifestAction struct {
+	Action  string             `json:"action"`
+	Key     string             `json:"key"`
+	WatchID string             `json:"watch_id,omitempty"`
+	Name    string             `json:"name"`
+	Changes []watchFieldChange `json:"changes,omitempty"`
+	Reason  string             `json:"reason,omitempty"`
+	// HistoryReset marks an update whose fare changed, which deletes the
+	// watch's price history, snapshot and run state.
+	HistoryReset bool `json:"history_reset,omitempty"`
+}
+
+type manifestPlan struct {
+	Create    int              `json:"create"`
+	Update    int              `json:"update"`
+	Delete    int              `json:"delete"`
+	Unchanged int              `json:"unchanged"`
+	Skipped   int              `json:"skipped"`
+	Actions   []manifestAction `json:"actions"`
+
+	watches []model.Watch
+	// reset lists updated watches whose query changed, whose price history
+	// and snapshot no longer apply.
+	reset []string
+}
+
+func parseWatchManifest(r io.Reader) (watchManifest, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return watchManifest{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var m watchManifest
+	if err := dec.Decode(&m); err != nil {
+		return watchManifest{}, fmt.Errorf("parse manifest: %w", err)
+	}
+	if m.Watches == nil {
+		return watchManifest{}, fmt.Errorf("parse manifest: missing \"watches\" list")
+	}
+	seen := map[string]bool{}
+	for i, mw := range m.Watches {
+		if mw.Key == "" {
+			return watchManifest{}, fmt.Errorf("manifest watch #%d: key is required", i+1)
+		}
+		if seen[mw.Key] {
+			return watchManifest{}, fmt.Errorf("manifest watch %q: duplicate key", mw.Key)
+		}
+		seen[mw.Key] = true
+	}
+	return m, nil
+}
+
+// toWatch builds the desired watch with the same defaults as watch create.
+func (mw manifestWatch) toWatch(cfg config.Config) (model.Watch, error) {
+	q := mw.Query
+	// Manifests may leave adults out; exports always carry it.
+	if q.Adults == 0 {
+		q.Adults = 1
+	}
+	w := model.Watch{
+		Key:            mw.Key,
+		Name:           firstOr(mw.Name, mw.Key),
+		Tags:           normalizeTags(mw.Tags),
+		Query:          q,
+		Enabled:        mw.Enabled == nil || *mw.Enabled,
+		TargetPrice:    mw.TargetPrice,
+		Cooldown:       mw.Cooldown,
+		RearmPercent:   defaultRearmPercent,
+		NotifyTerminal: mw.NotifyTerminal == nil || *mw.NotifyTerminal,
+		NotifyEmail:    mw.NotifyEmail,
+		NotifyWebhook:  mw.NotifyWebhook,
+		EmailTo:        firstOr(mw.EmailTo, cfg.DefaultNotifyEmail),
+		WebhookURL:     firstOr(mw.WebhookURL, cfg.WebhookURL),
+		CheckInterval:  mw.CheckInterval,
+		Schedule:       mw.Schedule,
+		MaxRequests:    mw.MaxRequests,
+	}
+	if mw.RearmPercent != nil {
+		w.RearmPercent = *mw.RearmPercent
+	}
+	for _, raw := range mw.Rules {
+		rule, err := parseAlertRule(raw)
+		if err != nil {
+			return model.Watch{}, newExitError(ExitInvalidUsage, "manifest watch %q: %v", mw.Key, err)
+		}
+		w.Rules = append(w.Rules, rule)
+	}
+	// The normalized watch is returned with a validation error too, so the
+	// plan can tell a stale but unchanged entry from a malformed one.
+	if err := prepareWatch(&w, cfg); err != nil {
+		return w, newExitError(ExitInvalidUsage, "manifest watch %q: %w", mw.Key, err)
+	}
+	return w, nil
+}
+
+// prepareWatch normalizes a watch loaded from a file, fills the query
+// defaults watch create applies and validates the result. Manifests and
+// imports share it.
+func prepareWatch(w *model.Watch, cfg config.Config) error {
+	q := &w.Query
+	normalizeQuery(q)
+	if q.Cabin == "" {
+		q.Cabin = model.CabinEconomy
+	}
+	if q.Currency == "" {
+		q.Currency = "USD"
+	}
+	if q.SortBy == "" {
+		q.SortBy = model.SortPrice
+	}
+	return validateWatch(*w, cfg)
+}
+
+// planManifest reconciles the manifest against stored watches. Matched watches
+// keep their ID, creation time and run state unless their query changed;
+// managed watches missing from the manifest are deleted, and so is the history
+// of watches whose query changed. An entry whose only problem is a departure
+// that has passed is skipped when it matches its stored watch, which is kept
+// as it is; any other query problem fails the plan, listing every problem.
+func planManifest(m watchManifest, existing []model.Watch, cfg config.Config, now time.Time) (manifestPlan, error) {
+	plan := manifestPlan{Actions: []manifestAction{}}
+	byKey := map[string]int{}
+	for i, w := range existing {
+		if w.Key == "" {
+			continue
+		}
+		if _, dup := byKey[w.Key]; dup {
+			return plan, newExitError(ExitGenericFailure, "stored watches %s and %s share key %q", existing[byKey[w.Key]].ID, w.ID, w.Key)
+		}
+		byKey[w.Key] = i
+	}
+	desired := map[string]model.Watch{}
+	skipped := map[string]bool{}
+	var problems []FieldError
+	for _, mw := range m.Watches {
+		w, err := mw.toWatch(cfg)
+		var verr ValidationError
+		if errors.As(err, &verr) {
+			i, stored := byKey[mw.Key]
+			if stored && verr.onlyDepartPassed() {
+				changes, err := diffWatches(existing[i], withManifestFields(existing[i], w))
+				if err != nil {
+					return plan, err
+				}
+				if len(changes) == 0 {
+					skipped[mw.Key] = true
+					plan.Skipped++
+					plan.Actions = append(plan.Actions, manifestAction{Action: manifestSkip, Key: mw.Key, WatchID: existing[i].ID, Name: w.Name, Reason: verr.summary()})
+					continue
+				}
+			}
+			problems = append(problems, verr.labelled(fmt.Sprintf("manifest watch %q", mw.Key))...)
+			continue
+		}
+		if err != nil {
+			return plan, err
+		}
+		desired[mw.Key] = w
+	}
+	if len(problems) > 0 {
+		return plan, ExitError{Code: ExitInvalidUsage, Err: ValidationError{Problems: problems}}
+	}
+
+	now = now.UTC()
+	out := make([]model.Watch, 0, len(existing)+len(m.Watches))
+	deletes := []manifestAction{}
+	for _, old := range existing {
+		if old.Key == "" || skipped[old.Key] {
+			out = append(out, old)
+			continue
+		}
+		want, ok := desired[old.Key]
+		if !ok {
+			plan.Delete++
+			deletes = append(deletes, manifestAction{Action: manifestDelete, Key: old.Key, WatchID: old.ID, Name: old.Name})
+			continue
+		}
+		updated := withManifestFields(old, want)
+		reset := !updated.Query.SameFare(old.Query)
+		if reset {
+			resetWatchRunState(&updated)
+			plan.reset = append(plan.reset, old.ID)
+		}
+		changes, err := diffWatches(old, updated)
+		if err != nil {
+			return plan, err
+		}
+		if len(changes) == 0 {
+			plan.Unchanged++
+		} else {
+			updated.UpdatedAt = now
+			plan.Update++
+			plan.Actions = append(plan.Actions, manifestAction{Action: manifestUpdate, Key: old.Key, WatchID: old.ID, Name: updated.Name, Changes: changes, HistoryReset: reset})
+		}
+		out = append(out, updated)
+	}
+	for i, mw := range m.Watches {
+		if _, ok := byKey[mw.Key]; ok || skipped[mw.Key] {
+			continue
+		}
+		w := desired[mw.Key]
+		w.ID = fmt.Sprintf("w_%d", now.UnixNano()+int64(i))
+		w.AlertState = model.AlertState{Status: alertStateArmed}
+		w.CreatedAt = now
+		w.UpdatedAt = now
+		plan.Create++
+		plan.Actions = append(plan.Actions, manifestAction{Action: manifestCreate, Key: w.Key, WatchID: w.ID, Name: w.Name})
+		out = append(out, w)
+	}
+	plan.Actions = append(plan.Actions, deletes...)
+	plan.watches = out
+	return plan, nil
+}
+
+// withManifestFields returns old with every field a manifest manages taken
+// from want.
+func withManifestFields(old, want model.Watch) model.Watch {
+	updated := old
+	updated.Name = want.Name
+	updated.Tags = want.Tags
+	updated.Query = want.Query
+	updated.Enabled = want.Enabled
+	updated.TargetPrice = want.TargetPrice
+	updated.Rules = want.Rules
+	updated.Cooldown = want.Cooldown
+	updated.RearmPercent = want.RearmPercent
+	updated.NotifyTerminal = want.NotifyTerminal
+	updated.NotifyEmail = want.NotifyEmail
+	updated.NotifyWebhook = want.NotifyWebhook
+	updated.EmailTo = want.EmailTo
+	updated.WebhookURL = want.WebhookURL
+	updated.CheckInterval = want.CheckInterval
+	updated.Schedule = want.Schedule
+	updated.MaxRequests = want.MaxRequests
+	return updated
+}
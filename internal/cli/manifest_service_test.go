@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestParseWatchManifestRejectsBadInput(t *testing.T) {
+	cases := map[string]string{
+		"unknown field": `{"watches":[{"key":"a","query":{"from":"SFO","to":"ATH","depart":"2030-06-10"},"target":1}]}`,
+		"missing list":  `{}`,
+		"missing key":   `{"watches":[{"query":{"from":"SFO"}}]}`,
+		"duplicate key": `{"watches":[{"key":"a"},{"key":"a"}]}`,
+	}
+	for name, in := range cases {
+		if _, err := parseWatchManifest(strings.NewReader(in)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestPlanManifestReconcilesByKey(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	lastRun := now.Add(-time.Hour)
+	athens := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2030-06-10", Cabin: "economy", Adults: 1, Currency: "USD", SortBy: "price"}
+	tokyo := model.SearchQuery{From: "SFO", To: "HND", Depart: "2030-07-01", Cabin: "economy", Adults: 1, Currency: "USD", SortBy: "price"}
+	existing := []model.Watch{
+		{ID: "w_manual", Name: "manual", Query: athens, Enabled: true},
+		{ID: "w_athens", Key: "athens", Name: "athens", Query: athens, Enabled: true, TargetPrice: 700, RearmPercent: 5, NotifyTerminal: true, LastLowestPrice: 720, LastRunAt: lastRun, AlertState: model.AlertState{Status: alertStateArmed}},
+		{ID: "w_tokyo", Key: "tokyo", Name: "tokyo", Query: tokyo, Enabled: true, RearmPercent: 5, NotifyTerminal: true, LastLowestPrice: 900, LastRunAt: lastRun},
+		{ID: "w_old", Key: "old", Name: "old", Query: athens, Enabled: true},
+	}
+	m, err := parseWatchManifest(strings.NewReader(`{"watches":[
+		{"key":"athens","query":{"from":"SFO","to":"ATH","depart":"2030-06-10","max_price":900,"sort_by":"duration"},"target_price":650},
+		{"key":"tokyo","query":{"from":"SFO","to":"HND","depart":"2030-07-02"},"max_requests":10},
+		{"key":"rome","query":{"from":"SFO","to":"FCO","depart":"2030-08-01"},"rules":["all_time_low"]}
+	]}`))
+	if err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	plan, err := planManifest(m, existing, config.Config{}, now)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.Create != 1 || plan.Update != 2 || plan.Delete != 1 || plan.Unchanged != 0 {
+		t.Fatalf("unexpected plan counts: %+v", plan)
+	}
+	byID := map[string]model.Watch{}
+	for _, w := range plan.watches {
+		byID[w.ID] = w
+	}
+	if _, ok := byID["w_old"]; ok {
+		t.Fatalf("expected w_old deleted")
+	}
+	if _, ok := byID["w_manual"]; !ok {
+		t.Fatalf("watches without a key must be left alone")
+	}
+	if w := byID["w_athens"]; w.TargetPrice != 650 || w.LastLowestPrice != 720 || !w.LastRunAt.Equal(lastRun) {
+		t.Fatalf("expected run state kept when query is unchanged: %+v", w)
+	}
+	if w := byID["w_tokyo"]; w.LastLowestPrice != 0 || !w.LastRunAt.IsZero() || w.Query.Depart != "2030-07-02" || w.MaxRequests != 10 {
+		t.Fatalf("expected run state reset when query changed: %+v", w)
+	}
+	if len(plan.reset) != 1 || plan.reset[0] != "w_tokyo" {
+		t.Fatalf("expected only w_tokyo history reset, got %v", plan.reset)
+	}
+	for _, act := range plan.Actions {
+		if act.Action == manifestUpdate && act.HistoryReset != (act.WatchID == "w_tokyo") {
+			t.Fatalf("expected only the w_tokyo update flagged as a history reset, got %+v", act)
+		}
+	}
+	last := plan.Actions[len(plan.Actions)-1]
+	if last.Action != manifestDelete || last.WatchID != "w_old" {
+		t.Fatalf("expected deletes listed last, got %+v", plan.Actions)
+	}
+
+	plan.watches[len(plan.watches)-1].ID = "w_rome"
+	again, err := planManifest(m, plan.watches, config.Config{}, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("second plan: %v", err)
+	}
+	if again.Create != 0 || again.Update != 0 || again.Delete != 0 || again.Unchanged != 3 {
+		t.Fatalf("expected applied manifest to be a no-op, got %+v", again)
+	}
+}
+
+func TestApplyManifestCommand(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	manifest := filepath.Join(t.TempDir(), "watches.json")
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(manifest, []byte(body), 0o600); err != nil {
+			t.Fatalf("write manifest: %v", err)
+		}
+	}
+	write(`{"watches":[{"key":"athens","query":{"from":"SFO","to":"ATH","depart":"2030-06-10"},"target_price":700}]}`)
+
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "plan", "-f", manifest})
+	})
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if !strings.HasPrefix(out, "create=1\tupdate=0\tdelete=0\tunchanged=0\tskipped=0\tapplied=false\naction=create\tkey=athens\twatch_id=\thistory_reset=false\n") {
+		t.Fatalf("unexpected plan output: %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "watches.json")); !os.IsNotExist(err) {
+		t.Fatalf("plan must not write the store")
+	}
+
+	if err := app.Run([]string{"--state-dir", stateDir, "apply", "-f", manifest}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	created := onlyWatch(t, stateDir)
+	if created.Key != "athens" || created.TargetPrice != 700 {
+		t.Fatalf("unexpected created watch: %+v", created)
+	}
+
+	write(`{"watches":[]}`)
+	if err := app.Run([]string{"--state-dir", stateDir, "apply", "-f", manifest}); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected deletes to require --force, got %v", err)
+	}
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--json", "--state-dir", stateDir, "apply", "-f", manifest, "--force"})
+	})
+	if err != nil {
+		t.Fatalf("apply with deletes: %v", err)
+	}
+	var payload manifestOutput
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	if !payload.Applied || payload.Delete != 1 || payload.Actions[0].WatchID != created.ID {
+		t.Fatalf("unexpected apply payload: %s", out)
+	}
+}
+
+func TestPlanManifestSkipsStaleEntries(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	past := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2020-06-10", Cabin: "economy", Adults: 1, Currency: "USD", SortBy: "price"}
+	existing := []model.Watch{
+		{ID: "w_athens", Key: "athens", Name: "athens", Query: past, Enabled: true, RearmPercent: 5, NotifyTerminal: true, LastLowestPrice: 720},
+	}
+	m, err := parseWatchManifest(strings.NewReader(`{"watches":[
+		{"key":"athens","query":{"from":"SFO","to":"ATH","depart":"2020-06-10"}},
+		{"key":"rome","query":{"from":"SFO","to":"FCO","depart":"2030-08-01"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	plan, err := planManifest(m, existing, config.Config{}, now)
+	if err != nil {
+		t.Fatalf("expected an unchanged stale entry not to fail the plan: %v", err)
+	}
+	if plan.Create != 1 || plan.Skipped != 1 || plan.Delete != 0 {
+		t.Fatalf("unexpected plan counts: %+v", plan)
+	}
+	if len(plan.watches) != 2 || plan.watches[0].ID != "w_athens" || plan.watches[0].LastLowestPrice != 720 {
+		t.Fatalf("expected the stale stored watch kept as is, got %+v", plan.watches)
+	}
+	skip := plan.Actions[0]
+	if skip.Action != manifestSkip || skip.WatchID != "w_athens" || !strings.Contains(skip.Reason, "is in the past") {
+		t.Fatalf("expected skip action with reason, got %+v", plan.Actions)
+	}
+
+	// A past departure is only tolerated for a stored watch the manifest
+	// leaves unchanged.
+	for name, entry := range map[string]string{
+		"new entry":     `{"key":"lisbon","query":{"from":"SFO","to":"LIS","depart":"2020-07-01"}}`,
+		"changed entry": `{"key":"athens","name":"Athens","query":{"from":"SFO","to":"ATH","depart":"2020-06-10"}}`,
+	} {
+		m, err := parseWatchManifest(strings.NewReader(`{"watches":[` + entry + `]}`))
+		if err != nil {
+			t.Fatalf("%s: parse manifest: %v", name, err)
+		}
+		var verr ValidationError
+		if _, err := planManifest(m, existing, config.Config{}, now); ExitCode(err) != ExitInvalidUsage || !errors.As(err, &verr) {
+			t.Fatalf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestPlanManifestRejectsMalformedEntries(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	m, err := parseWatchManifest(strings.NewReader(`{"watches":[
+		{"key":"athens","query":{"from":"SFO","to":"ATH","depart":"2027-13-01","cabin":"coach"}},
+		{"key":"rome","query":{"from":"SFO","to":"FCO","depart":"2030-08-01","adults":12}}
+	]}`))
+	if err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	_, err = planManifest(m, nil, config.Config{}, now)
+	if ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected invalid usage, got %v", err)
+	}
+	var verr ValidationError
+	if !errors.As(err, &verr) || len(verr.Problems) != 3 {
+		t.Fatalf("expected every problem of every entry listed, got %v", err)
+	}
+	for i, want := range []string{"depart", "cabin", "adults"} {
+		if p := verr.Problems[i]; p.Field != want || !strings.HasPrefix(p.Message, "manifest watch ") {
+			t.Fatalf("problem %d: expected a labelled %s problem, got %+v", i, want, p)
+		}
+	}
+	report := NewErrorReport(err)
+	if len(report.Problems) != 3 || report.ExitCode != ExitInvalidUsage {
+		t.Fatalf("expected the JSON error to list every problem, got %+v", report)
+	}
+}
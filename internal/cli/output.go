@@ -2,6 +2,7 @@ package cli
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"strings"
 )
@@ -27,6 +28,25 @@ func writeJSON(v any) error {
 	return nil
 }
 
+func writeJSONLine(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func flagWasSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 func writePlainKV(pairs ...string) {
 	if len(pairs)%2 != 0 {
 		fmt.Println(strings.Join(pairs, "\t"))
@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/airports"
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+// maxPassengers is the most travellers Google Flights prices in one search.
+const maxPassengers = 9
+
+// FieldError is one problem with a query field. Field is the search flag
+// without its dashes, e.g. "depart".
+type FieldError struct {
+	Field       string `json:"field"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+
+	// departPassed marks a departure that has passed, the one problem a watch
+	// that validated when it was created runs into later.
+	departPassed bool
+}
+
+func (e FieldError) String() string {
+	return "--" + e.Field + ": " + e.Message
+}
+
+// ValidationError lists every problem found in a query, so callers can fix
+// them all in one go.
+type ValidationError struct {
+	Problems []FieldError
+}
+
+func (e ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0].String()
+	}
+	lines := []string{fmt.Sprintf("invalid query (%d problems):", len(e.Problems))}
+	for _, p := range e.Problems {
+		lines = append(lines, "  "+p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// summary lists the problems on one line, for warnings and report fields.
+func (e ValidationError) summary() string {
+	parts := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		parts[i] = p.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// onlyDepartPassed reports whether a departure that has passed is the only
+// problem, so a stored watch can be left alone rather than rejected.
+func (e ValidationError) onlyDepartPassed() bool {
+	for _, p := range e.Problems {
+		if !p.departPassed {
+			return false
+		}
+	}
+	return len(e.Problems) > 0
+}
+
+// labelled returns the problems with label in front of each message, so the
+// problems of several watches can be reported in one ValidationError.
+func (e ValidationError) labelled(label string) []FieldError {
+	out := make([]FieldError, len(e.Problems))
+	for i, p := range e.Problems {
+		p.Message = label + ": " + p.Message
+		out[i] = p
+	}
+	return out
+}
+
+type fieldErrors []FieldError
+
+func (v *fieldErrors) add(field, remediation, format string, args ...any) {
+	*v = append(*v, FieldError{Field: field, Message: fmt.Sprintf(format, args...), Remediation: remediation})
+}
+
+// validateQuery checks a query before any provider call and reports every
+// problem as a ValidationError with exit code 2.
+func validateQuery(q model.SearchQuery) error {
+	if problems := queryProblems(q, time.Now()); len(problems) > 0 {
+		return ExitError{Code: ExitInvalidUsage, Err: ValidationError{Problems: problems}}
+	}
+	return nil
+}
+
+func queryProblems(q model.SearchQuery, now time.Time) []FieldError {
+	var v fieldErrors
+	codesOK := true
+	for _, f := range []struct{ name, codes string }{{"from", q.From}, {"to", q.To}} {
+		if f.codes == "" {
+			v.add(f.name, "gflight airports search <city> lists airport and metro codes", "is required")
+			codesOK = false
+			continue
+		}
+		if !v.checkAirportCodes(f.name, f.codes, q.AllowUnknownAirports) {
+			codesOK = false
+		}
+	}
+	if codesOK && len(routeQueries(q)) == 0 {
+		v.add("to", "use different --from and --to codes", "leaves no route: every origin is also a destination")
+	}
+	v.checkDates(q, utcDate(now))
+	v.checkPassengers(q)
+	if _, err := model.ParseCabin(string(q.Cabin)); err != nil {
+		v.add("cabin", "use one of "+model.JoinVocab(model.Cabins), "unknown cabin %q", q.Cabin)
+	}
+	if _, err := model.ParseSortOrder(string(q.SortBy)); err != nil {
+		v.add("sort", "use one of "+model.JoinVocab(model.SortOrders), "unknown sort order %q", q.SortBy)
+	}
+	stops, err := model.ParseStops(string(q.Stops))
+	if err != nil {
+		v.add("stops", "use one of "+model.JoinVocab(model.StopsLimits), "unknown stops limit %q", q.Stops)
+	} else if q.Nonstop && stops != "" && stops != model.StopsNonstop {
+		v.add("stops", "drop --nonstop or use --stops nonstop", "--nonstop conflicts with --stops %s", stops)
+	}
+	if _, err := model.ParseCurrency(q.Currency); err != nil {
+		remediation := "use an ISO 4217 code such as USD, EUR or GBP"
+		if s := suggestClosest(strings.ToUpper(q.Currency), model.Currencies); s != "" {
+			remediation = "use " + s
+		}
+		v.add("currency", remediation, "unknown currency %q", q.Currency)
+	}
+	return v
+}
+
+// checkAirportCodes checks each code of a --from/--to list against the
+// embedded airport table, so typos fail before a paid provider call. The
+// table is not exhaustive, so allowUnknown lets well-formed codes through.
+func (v *fieldErrors) checkAirportCodes(field, list string, allowUnknown bool) bool {
+	ok := true
+	for _, code := range model.SplitCodes(list) {
+		if _, found := airports.Lookup(code); found || (allowUnknown && wellFormedAirportCode(code)) {
+			continue
+		}
+		ok = false
+		remediation := "gflight airports search <city> lists airport and metro codes"
+		if wellFormedAirportCode(code) {
+			remediation = "if " + code + " is right, pass --allow-unknown-airports; the embedded table lists major airports only"
+		}
+		if s := suggestAirport(code); s != "" {
+			v.add(field, remediation, "unknown airport code %q (did you mean %s?)", code, s)
+			continue
+		}
+		v.add(field, remediation, "unknown airport code %q", code)
+	}
+	return ok
+}
+
+// wellFormedAirportCode reports whether code looks like an IATA code.
+func wellFormedAirportCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// unknownAirportCodes lists the codes of q missing from the airport table,
+// which validateQuery only accepts with AllowUnknownAirports.
+func unknownAirportCodes(q model.SearchQuery) []string {
+	var out []string
+	for _, code := range model.SplitCodes(q.From + "," + q.To) {
+		if _, found := airports.Lookup(code); !found {
+			out = append(out, code)
+		}
+	}
+	return out
+}
+
+// warnUnknownAirports tells the user which codes are searched unchecked.
+func warnUnknownAirports(q model.SearchQuery) {
+	if codes := unknownAirportCodes(q); len(codes) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: airport codes not in the embedded table are searched as given: %s\n", strings.Join(codes, ", "))
+	}
+}
+
+// checkDates requires well-formed dates from today (UTC) on, a return no earlier
+// than the departure and a sane date window.
+func (v *fieldErrors) checkDates(q model.SearchQuery, today string) {
+	departOK := false
+	switch {
+	case q.Depart == "":
+		v.add("depart", "pass --depart YYYY-MM-DD or --depart-range YYYY-MM-DD..YYYY-MM-DD", "is required")
+	case !validDate(q.Depart):
+		v.add("depart", "use YYYY-MM-DD, e.g. "+today, "invalid date %q", q.Depart)
+	case q.Depart < today:
+		v.add("depart", "use "+today+" or later", "%s is in the past", q.Depart)
+		(*v)[len(*v)-1].departPassed = true
+	default:
+		departOK = true
+	}
+	if q.DepartTo != "" {
+		switch {
+		case !validDate(q.DepartTo):
+			v.add("depart-range", "use YYYY-MM-DD..YYYY-MM-DD", "invalid end date %q", q.DepartTo)
+		case departOK && q.DepartTo < q.Depart:
+			v.add("depart-range", "end the window on or after "+q.Depart, "window ends %s, before it starts", q.DepartTo)
+		}
+	}
+	if q.TripMinDays != 0 || q.TripMaxDays != 0 {
+		if q.TripMinDays < 1 || q.TripMaxDays < q.TripMinDays {
+			v.add("trip-length", "use a number of days like 10 or a range like 10-14", "invalid trip length %d-%d days", q.TripMinDays, q.TripMaxDays)
+		}
+	}
+	if q.Return == "" {
+		return
+	}
+	switch {
+	case q.Flexible():
+		v.add("return", "drop --return and pass --trip-length DAYS", "a date window needs --trip-length instead of --return")
+	case !validDate(q.Return):
+		v.add("return", "use YYYY-MM-DD, e.g. "+today, "invalid date %q", q.Return)
+	case departOK && q.Return < q.Depart:
+		v.add("return", "use "+q.Depart+" or later", "%s is before the departure on %s", q.Return, q.Depart)
+	}
+}
+
+func (v *fieldErrors) checkPassengers(q model.SearchQuery) {
+	if q.Adults < 1 {
+		v.add("adults", "pass --adults 1 or more", "must be at least 1 (got %d)", q.Adults)
+	}
+	if q.Children < 0 {
+		v.add("children", "pass --children 0 or more", "cannot be negative (got %d)", q.Children)
+	}
+	if q.Adults >= 1 && q.Children >= 0 && q.Adults+q.Children > maxPassengers {
+		v.add("adults", "split the party into several searches", "%d passengers exceed the limit of %d per search", q.Adults+q.Children, maxPassengers)
+	}
+}
+
+func validDate(s string) bool {
+	_, err := time.Parse(dateLayout, s)
+	return err == nil
+}
@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/agisilaos/gflight/internal/config"
@@ -17,27 +21,94 @@ func newSearchFlagSet(name string) (*flag.FlagSet, *model.SearchQuery) {
 	q := &model.SearchQuery{}
 	fs := flag.NewFlagSet(name, flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
-	fs.StringVar(&q.From, "from", "", "Departure airport/city code")
-	fs.StringVar(&q.To, "to", "", "Arrival airport/city code")
+	fs.Var(codesFlag{&q.From}, "from", "Departure airport/city codes, comma-separated (e.g. SFO,OAK or NYC)")
+	fs.Var(codesFlag{&q.To}, "to", "Arrival airport/city codes, comma-separated (e.g. ATH,SKG or LON)")
 	fs.StringVar(&q.Depart, "depart", "", "Outbound date YYYY-MM-DD")
 	fs.StringVar(&q.Return, "return", "", "Return date YYYY-MM-DD")
-	fs.StringVar(&q.Cabin, "cabin", "economy", "Cabin class")
+	fs.Var(departRangeFlag{q}, "depart-range", "Departure window YYYY-MM-DD..YYYY-MM-DD; searches every date and keeps the cheapest")
+	fs.Var(tripLengthFlag{q}, "trip-length", "Round-trip length in days with a date window, e.g. 10 or 10-14")
+	q.Cabin = model.CabinEconomy
+	fs.Var(vocabFlag[model.Cabin]{&q.Cabin, model.ParseCabin}, "cabin", "Cabin class: economy, premium_economy, business, first")
 	fs.IntVar(&q.Adults, "adults", 1, "Number of adults")
 	fs.IntVar(&q.Children, "children", 0, "Number of children")
-	fs.BoolVar(&q.Nonstop, "nonstop", false, "Nonstop only")
+	fs.BoolVar(&q.Nonstop, "nonstop", false, "Nonstop only (same as --stops nonstop)")
+	fs.Var(vocabFlag[model.Stops]{&q.Stops, model.ParseStops}, "stops", "Maximum stops: any, nonstop, 1, 2")
 	fs.IntVar(&q.MaxPrice, "max-price", 0, "Maximum acceptable price")
 	fs.StringVar(&q.Currency, "currency", "USD", "Currency code")
-	fs.StringVar(&q.SortBy, "sort", "price", "Sort mode")
+	q.SortBy = model.SortPrice
+	fs.Var(vocabFlag[model.SortOrder]{&q.SortBy, model.ParseSortOrder}, "sort", "Sort order: best, price, departure_time, arrival_time, duration, emissions")
+	fs.BoolVar(&q.Booking, "booking", false, "Resolve booking options and deep links for the top results (extra provider requests)")
+	fs.BoolVar(&q.AllowUnknownAirports, "allow-unknown-airports", false, "Search well-formed codes missing from the embedded airport table, with a warning")
 	return fs, q
 }
 
-func validateQuery(q model.SearchQuery) error {
-	if q.From == "" || q.To == "" || q.Depart == "" {
-		return newExitError(ExitInvalidUsage, "--from, --to, and --depart are required")
+// vocabFlag canonicalizes a typed query value as it is parsed. Unknown values
+// are kept as given for validateQuery to report with any other problems.
+type vocabFlag[T ~string] struct {
+	p     *T
+	parse func(string) (T, error)
+}
+
+func (f vocabFlag[T]) String() string {
+	if f.p == nil {
+		return ""
+	}
+	return string(*f.p)
+}
+
+func (f vocabFlag[T]) Set(s string) error {
+	v, err := f.parse(s)
+	if err != nil {
+		v = T(s)
 	}
+	*f.p = v
 	return nil
 }
 
+// codesFlag canonicalizes a comma-separated airport/city code list.
+type codesFlag struct{ p *string }
+
+func (f codesFlag) String() string {
+	if f.p == nil {
+		return ""
+	}
+	return *f.p
+}
+
+func (f codesFlag) Set(s string) error {
+	*f.p = model.NormalizeCodes(s)
+	return nil
+}
+
+// checkDateFlags rejects date flags that would overwrite each other.
+func checkDateFlags(fs *flag.FlagSet) error {
+	if flagWasSet(fs, "depart") && flagWasSet(fs, "depart-range") {
+		return newExitError(ExitInvalidUsage, "use either --depart or --depart-range")
+	}
+	if flagWasSet(fs, "return") && flagWasSet(fs, "trip-length") {
+		return newExitError(ExitInvalidUsage, "use either --return or --trip-length")
+	}
+	return nil
+}
+
+// normalizeQuery canonicalizes codes and cabin, sort, stops and currency spellings; invalid
+// values are left for validateQuery to report.
+func normalizeQuery(q *model.SearchQuery) {
+	q.From, q.To = model.NormalizeCodes(q.From), model.NormalizeCodes(q.To)
+	if v, err := model.ParseCabin(string(q.Cabin)); err == nil {
+		q.Cabin = v
+	}
+	if v, err := model.ParseSortOrder(string(q.SortBy)); err == nil {
+		q.SortBy = v
+	}
+	if v, err := model.ParseStops(string(q.Stops)); err == nil {
+		q.Stops = v
+	}
+	if v, err := model.ParseCurrency(q.Currency); err == nil {
+		q.Currency = v
+	}
+}
+
 func (a App) resolveProvider(cfg config.Config, g globalFlags) (provider.Provider, error) {
 	timeout := time.Duration(cfg.ProviderTimeoutSec) * time.Second
 	if g.Timeout != "" {
@@ -47,30 +118,107 @@ func (a App) resolveProvider(cfg config.Config, g globalFlags) (provider.Provide
 		}
 		timeout = parsed
 	}
-	backoff := time.Duration(cfg.ProviderBackoffMS) * time.Millisecond
+	return configuredProvider(cfg, timeout), nil
+}
 
+// configuredProvider builds the provider cfg selects.
+func configuredProvider(cfg config.Config, timeout time.Duration) provider.Provider {
 	switch strings.ToLower(cfg.Provider) {
 	case "google-url", "google":
-		return provider.GoogleURLProvider{}, nil
+		return provider.GoogleURLProvider{}
 	default:
 		return provider.SerpAPIProvider{
-			APIKey:  cfg.SerpAPIKey,
-			Timeout: timeout,
-			Retries: cfg.ProviderRetries,
-			Backoff: backoff,
-			BaseURL: "https://serpapi.com",
-		}, nil
+			APIKey:            cfg.SerpAPIKey,
+			Timeout:           timeout,
+			Retries:           cfg.ProviderRetries,
+			Backoff:           time.Duration(cfg.ProviderBackoffMS) * time.Millisecond,
+			BaseURL:           "https://serpapi.com",
+			MaxConcurrent:     cfg.ProviderMaxConcurrency,
+			MaxReturnLookups:  cfg.ProviderMaxReturnLookups,
+			MaxBookingLookups: cfg.ProviderMaxBookingLookups,
+		}
+	}
+}
+
+// parseDeadline reads --deadline; zero means no overall deadline.
+func parseDeadline(g globalFlags) (time.Duration, error) {
+	if g.Deadline == "" {
+		return 0, nil
 	}
+	d, err := time.ParseDuration(g.Deadline)
+	if err != nil || d <= 0 {
+		return 0, newExitError(ExitInvalidUsage, "invalid --deadline value %q (use duration like 2m)", g.Deadline)
+	}
+	return d, nil
+}
+
+// signalContext is cancelled on SIGINT/SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// withDeadline bounds ctx by the --deadline duration, if any.
+func withDeadline(ctx context.Context, deadline time.Duration) (context.Context, context.CancelFunc) {
+	if deadline <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, deadline)
+}
+
+// checkSearchRequests bounds a search by --max-requests before any provider
+// call. Each route costs one request plus, for SerpAPI, the return and
+// booking lookups it follows.
+func checkSearchRequests(p provider.Provider, q model.SearchQuery, maxRequests int) error {
+	routes := len(routeQueries(q))
+	if n := routes * requestsPerSearch(p, q); n > maxRequests {
+		return newExitError(ExitInvalidUsage, "%d route(s) need up to %d provider requests with return and booking lookups, over --max-requests %d (list fewer airports, lower --return-options or raise --max-requests)", routes, n, maxRequests)
+	}
+	return nil
+}
+
+// requestsPerSearch is the most provider requests one search of q makes:
+// one, plus the return and booking lookups SerpAPI follows.
+func requestsPerSearch(p provider.Provider, q model.SearchQuery) int {
+	if sp, ok := p.(provider.SerpAPIProvider); ok {
+		return sp.RequestsFor(q)
+	}
+	return 1
 }
 
 func (a App) cmdSearch(g globalFlags, args []string) error {
 	fs, q := newSearchFlagSet("search")
+	returnOptions := fs.Int("return-options", 0, "Fetch return flights for the top N outbound options of a round trip (one extra provider request each)")
+	maxRequests := fs.Int("max-requests", defaultCalendarRequests, "Most provider requests a search may use, counting return and booking lookups")
 	if err := fs.Parse(args); err != nil {
 		return newExitError(ExitInvalidUsage, "%v", err)
 	}
+	if err := checkDateFlags(fs); err != nil {
+		return err
+	}
+	if *maxRequests < 1 || *maxRequests > maxCalendarRequests {
+		return newExitError(ExitInvalidUsage, "--max-requests must be from 1 to %d", maxCalendarRequests)
+	}
+	if q.Flexible() {
+		if *returnOptions > 0 || q.Booking {
+			return newExitError(ExitInvalidUsage, "--return-options and --booking cannot be combined with --depart-range or --trip-length")
+		}
+		return a.cmdSearchCalendar(g, *q, *maxRequests)
+	}
 	if err := validateQuery(*q); err != nil {
 		return err
 	}
+	warnUnknownAirports(*q)
+	if *returnOptions < 0 {
+		return newExitError(ExitInvalidUsage, "--return-options must be >= 0")
+	}
+	if *returnOptions > 0 && q.Return == "" {
+		return newExitError(ExitInvalidUsage, "--return-options requires --return")
+	}
+	// The base searches alone can be checked before loading config; lookups
+	// depend on provider limits and are counted once the provider is known.
+	if n := len(routeQueries(*q)); n > 1 && n > *maxRequests {
+		return newExitError(ExitInvalidUsage, "%d routes need %d provider requests, over --max-requests %d (list fewer airports or raise --max-requests)", n, n, *maxRequests)
+	}
 	cfg, err := config.Load()
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
@@ -82,13 +230,34 @@ func (a App) cmdSearch(g globalFlags, args []string) error {
 	if err != nil {
 		return err
 	}
-	res, err := p.Search(*q)
+	if sp, ok := p.(provider.SerpAPIProvider); ok && *returnOptions > 0 {
+		sp.ReturnLookups = *returnOptions
+		if limit := sp.ReturnLookupLimit(); limit < *returnOptions {
+			fmt.Fprintf(os.Stderr, "--return-options capped at %d by provider_max_return_lookups\n", limit)
+		}
+		p = sp
+	}
+	if err := checkSearchRequests(p, *q, *maxRequests); err != nil {
+		return err
+	}
+	deadline, err := parseDeadline(g)
+	if err != nil {
+		return err
+	}
+	sigCtx, stop := signalContext()
+	defer stop()
+	ctx, cancel := withDeadline(sigCtx, deadline)
+	defer cancel()
+	res, err := multiRouteSearch(p.Search, watchConcurrency(cfg.WatchConcurrency, p))(ctx, *q)
 	if err != nil {
 		return wrapProviderError(err)
 	}
 	if g.JSON {
 		return writeJSON(res)
 	}
+	for _, w := range res.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
 	if g.Plain {
 		flights := append([]model.Flight(nil), res.Flights...)
 		sort.SliceStable(flights, func(i, j int) bool {
@@ -103,7 +272,7 @@ func (a App) cmdSearch(g globalFlags, args []string) error {
 			}
 			return flights[i].Airline < flights[j].Airline
 		})
-		writePlainTableHeader("price", "currency", "airline", "depart_time", "arrive_time", "stops")
+		writePlainTableHeader("price", "currency", "airline", "depart_time", "arrive_time", "stops", "duration_minutes", "route", "co2_grams", "return_options", "deep_link")
 		for _, f := range flights {
 			writePlainTableRow(
 				fmt.Sprintf("%d", f.Price),
@@ -112,13 +281,41 @@ func (a App) cmdSearch(g globalFlags, args []string) error {
 				f.DepartTime,
 				f.ArriveTime,
 				fmt.Sprintf("%d", f.Stops),
+				fmt.Sprintf("%d", f.TotalDurationMinutes),
+				flightRoute(f),
+				fmt.Sprintf("%d", emissionGrams(f)),
+				fmt.Sprintf("%d", len(f.ReturnOptions)),
+				bookingTarget(f).DeepLink,
+			)
+		}
+		if in := res.Insights; in != nil {
+			writePlainKV(
+				"price_level", in.PriceLevel,
+				"lowest_price", strconv.Itoa(in.LowestPrice),
+				"typical_low", strconv.Itoa(in.TypicalLow),
+				"typical_high", strconv.Itoa(in.TypicalHigh),
+			)
+		}
+		for _, r := range res.Routes {
+			writePlainKV(
+				"route", r.From+"-"+r.To,
+				"lowest_price", strconv.Itoa(r.LowestPrice),
+				"currency", r.Currency,
+				"flight_count", strconv.Itoa(r.FlightCount),
+				"error", r.Error,
+				"route_url", r.URL,
 			)
 		}
 		writePlainKV("url", res.URL)
 		return nil
 	}
 	if len(res.Flights) == 0 {
-		fmt.Printf("No priced flights returned. Open Google Flights:\n%s\n", res.URL)
+		if len(res.Routes) == 0 {
+			fmt.Printf("No priced flights returned. Open Google Flights:\n%s\n", res.URL)
+			return nil
+		}
+		fmt.Println("No priced flights returned. Open Google Flights:")
+		printRoutes(res.Routes)
 		return nil
 	}
 	limit := len(res.Flights)
@@ -126,10 +323,180 @@ func (a App) cmdSearch(g globalFlags, args []string) error {
 		limit = 10
 	}
 	fmt.Printf("Top %d flight options for %s -> %s on %s\n", limit, q.From, q.To, q.Depart)
+	if in := res.Insights; in != nil {
+		fmt.Println(formatInsights(*in))
+	}
 	for i := 0; i < limit; i++ {
 		f := res.Flights[i]
-		fmt.Printf("%2d) %4d %s | %s | stops:%d | %s -> %s\n", i+1, f.Price, f.Currency, f.Airline, f.Stops, f.DepartTime, f.ArriveTime)
+		fmt.Printf("%2d) %4d %s | %s | stops:%d | %s -> %s", i+1, f.Price, f.Currency, f.Airline, f.Stops, f.DepartTime, f.ArriveTime)
+		if len(res.Routes) > 0 {
+			fmt.Printf(" | %s-%s", f.From, f.To)
+		}
+		if f.TotalDurationMinutes > 0 {
+			fmt.Printf(" | %s", formatMinutes(f.TotalDurationMinutes))
+		}
+		if f.Emissions != nil {
+			fmt.Printf(" | %s", formatEmissions(*f.Emissions))
+		}
+		fmt.Println()
+		printItinerary(f)
+		printReturnOptions(f)
+		printBookingOptions(bookingTarget(f))
+	}
+	if len(res.Routes) > 0 {
+		fmt.Println("Routes:")
+		printRoutes(res.Routes)
 	}
 	fmt.Printf("Google Flights: %s\n", res.URL)
 	return nil
 }
+
+// printRoutes lists each airport pair of a multi-route search with its
+// cheapest fare and Google Flights link.
+func printRoutes(routes []model.RouteResult) {
+	for _, r := range routes {
+		switch {
+		case r.Error != "":
+			fmt.Printf("  %s-%s: failed: %s\n", r.From, r.To, r.Error)
+		case r.FlightCount == 0:
+			fmt.Printf("  %s-%s: no priced flights  %s\n", r.From, r.To, r.URL)
+		default:
+			fmt.Printf("  %s-%s: from %d %s  %s\n", r.From, r.To, r.LowestPrice, r.Currency, r.URL)
+		}
+	}
+}
+
+// formatInsights summarizes the market, e.g.
+// "Prices are low: lowest 650, typically 700-900 (30-day history)".
+func formatInsights(in model.PriceInsights) string {
+	s := "Price insights:"
+	if in.PriceLevel != "" {
+		s = fmt.Sprintf("Prices are %s:", in.PriceLevel)
+	}
+	if in.LowestPrice > 0 {
+		s += fmt.Sprintf(" lowest %d", in.LowestPrice)
+	}
+	if in.TypicalLow > 0 || in.TypicalHigh > 0 {
+		s += fmt.Sprintf(", typically %d-%d", in.TypicalLow, in.TypicalHigh)
+	}
+	if n := len(in.History); n > 1 {
+		days := int(in.History[n-1].Date.Sub(in.History[0].Date).Hours()/24) + 1
+		s += fmt.Sprintf(" (%d-day history)", days)
+	}
+	return s
+}
+
+// printItinerary lists segments with the layover that follows each one.
+func printItinerary(f model.Flight) {
+	if len(f.Segments) < 2 && len(f.Layovers) == 0 {
+		return
+	}
+	for i, s := range f.Segments {
+		line := fmt.Sprintf("      %s %s -> %s %s  %s", s.From, s.DepartTime, s.To, s.ArriveTime, strings.TrimSpace(s.Airline+" "+s.FlightNumber))
+		if s.Aircraft != "" {
+			line += "  " + s.Aircraft
+		}
+		if s.DurationMinutes > 0 {
+			line += "  " + formatMinutes(s.DurationMinutes)
+		}
+		fmt.Println(line)
+		if i < len(f.Layovers) {
+			l := f.Layovers[i]
+			note := ""
+			if l.Overnight {
+				note = " (overnight)"
+			}
+			fmt.Printf("        layover %s %s%s\n", l.Airport, formatMinutes(l.DurationMinutes), note)
+		}
+	}
+}
+
+// printReturnOptions lists the first few resolved return flights; their
+// prices cover the whole round trip.
+func printReturnOptions(f model.Flight) {
+	if f.ReturnOptions == nil {
+		return
+	}
+	if len(f.ReturnOptions) == 0 {
+		fmt.Println("      return: no options returned")
+		return
+	}
+	const shown = 3
+	for i, r := range f.ReturnOptions {
+		if i == shown {
+			fmt.Printf("      ... %d more return options (--json lists all)\n", len(f.ReturnOptions)-shown)
+			break
+		}
+		fmt.Printf("      return: %4d %s round trip | %s | stops:%d | %s -> %s", r.Price, r.Currency, r.Airline, r.Stops, r.DepartTime, r.ArriveTime)
+		if r.TotalDurationMinutes > 0 {
+			fmt.Printf(" | %s", formatMinutes(r.TotalDurationMinutes))
+		}
+		fmt.Println()
+	}
+}
+
+// bookingTarget is the itinerary that carries booking options: the first
+// return option of a resolved round trip, otherwise the flight itself.
+func bookingTarget(f model.Flight) model.Flight {
+	if len(f.ReturnOptions) > 0 {
+		return f.ReturnOptions[0]
+	}
+	return f
+}
+
+func printBookingOptions(f model.Flight) {
+	if f.BookingOptions == nil {
+		return
+	}
+	if len(f.BookingOptions) == 0 {
+		fmt.Println("      book: no booking options returned")
+		return
+	}
+	const shown = 3
+	for i, b := range f.BookingOptions {
+		if i == shown {
+			fmt.Printf("      ... %d more booking options (--json lists all)\n", len(f.BookingOptions)-shown)
+			break
+		}
+		seller := b.Seller
+		if b.Leg != "" {
+			seller += " (" + b.Leg + ")"
+		}
+		fmt.Printf("      book: %s %d %s %s\n", seller, b.Price, b.Currency, b.URL)
+	}
+}
+
+// flightRoute joins segment airports, e.g. SFO-FRA-ATH.
+func flightRoute(f model.Flight) string {
+	if len(f.Segments) == 0 {
+		return f.From + "-" + f.To
+	}
+	stops := []string{f.Segments[0].From}
+	for _, s := range f.Segments {
+		stops = append(stops, s.To)
+	}
+	return strings.Join(stops, "-")
+}
+
+func emissionGrams(f model.Flight) int {
+	if f.Emissions == nil {
+		return 0
+	}
+	return f.Emissions.Grams
+}
+
+func formatEmissions(e model.Emissions) string {
+	s := fmt.Sprintf("%dkg CO2", (e.Grams+500)/1000)
+	if e.TypicalGrams > 0 {
+		s += fmt.Sprintf(" (%+d%% vs typical)", e.DifferencePercent)
+	}
+	return s
+}
+
+// formatMinutes renders 735 as "12h15m".
+func formatMinutes(m int) string {
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", m/60, m%60)
+}
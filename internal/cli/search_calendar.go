@@ -0,0 +1,407 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	// defaultCalendarRequests bounds provider calls per calendar search.
+	defaultCalendarRequests = 30
+	maxCalendarRequests     = 500
+)
+
+// utcDate is t's calendar date in UTC. Query validation and watch runs both
+// use it, so a departure passes at the same moment whatever the local zone.
+func utcDate(t time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
+
+// dateGrid is the set of departure dates and trip lengths a calendar search
+// covers. TripMin == 0 with TripMax == 0 means one-way.
+type dateGrid struct {
+	DepartFrom time.Time
+	DepartTo   time.Time
+	TripMin    int
+	TripMax    int
+}
+
+func (g dateGrid) roundTrip() bool {
+	return g.TripMax > 0
+}
+
+func (g dateGrid) departDates() []time.Time {
+	var out []time.Time
+	for d := g.DepartFrom; !d.After(g.DepartTo); d = d.AddDate(0, 0, 1) {
+		out = append(out, d)
+	}
+	return out
+}
+
+func (g dateGrid) tripLengths() []int {
+	if !g.roundTrip() {
+		return []int{0}
+	}
+	out := make([]int, 0, g.TripMax-g.TripMin+1)
+	for n := g.TripMin; n <= g.TripMax; n++ {
+		out = append(out, n)
+	}
+	return out
+}
+
+func (g dateGrid) size() int {
+	return len(g.departDates()) * len(g.tripLengths())
+}
+
+// queries expands base over the grid, depart-major, into fixed-date queries.
+func (g dateGrid) queries(base model.SearchQuery) []model.SearchQuery {
+	out := make([]model.SearchQuery, 0, g.size())
+	base.DepartTo, base.TripMinDays, base.TripMaxDays = "", 0, 0
+	for _, d := range g.departDates() {
+		for _, n := range g.tripLengths() {
+			q := base
+			q.Depart = d.Format(dateLayout)
+			q.Return = ""
+			if n > 0 {
+				q.Return = d.AddDate(0, 0, n).Format(dateLayout)
+			}
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// parseDateRange accepts "2026-06-01..2026-06-15" or a single date.
+func parseDateRange(v string) (time.Time, time.Time, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(v), "..")
+	if !ok {
+		to = from
+	}
+	start, err := time.Parse(dateLayout, strings.TrimSpace(from))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date range %q (use YYYY-MM-DD..YYYY-MM-DD)", v)
+	}
+	end, err := time.Parse(dateLayout, strings.TrimSpace(to))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date range %q (use YYYY-MM-DD..YYYY-MM-DD)", v)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range %q ends before it starts", v)
+	}
+	return start, end, nil
+}
+
+// parseTripLength accepts "10" or "10-14" (days).
+func parseTripLength(v string) (int, int, error) {
+	lo, hi, ok := strings.Cut(strings.TrimSpace(v), "-")
+	if !ok {
+		hi = lo
+	}
+	minDays, err1 := strconv.Atoi(strings.TrimSpace(lo))
+	maxDays, err2 := strconv.Atoi(strings.TrimSpace(hi))
+	if err1 != nil || err2 != nil || minDays < 1 || maxDays < minDays {
+		return 0, 0, fmt.Errorf("invalid trip length %q (use days like 10 or 10-14)", v)
+	}
+	return minDays, maxDays, nil
+}
+
+// departRangeFlag sets Depart and DepartTo from one window; a single date
+// leaves DepartTo empty.
+type departRangeFlag struct{ q *model.SearchQuery }
+
+func (f departRangeFlag) String() string {
+	if f.q == nil || f.q.DepartTo == "" {
+		return ""
+	}
+	return f.q.Depart + ".." + f.q.DepartTo
+}
+
+func (f departRangeFlag) Set(v string) error {
+	from, to, err := parseDateRange(v)
+	if err != nil {
+		return err
+	}
+	f.q.Depart = from.Format(dateLayout)
+	f.q.DepartTo = ""
+	if to.After(from) {
+		f.q.DepartTo = to.Format(dateLayout)
+	}
+	return nil
+}
+
+// tripLengthFlag sets TripMinDays and TripMaxDays; an empty value clears
+// them, which lets watch update return to one-way.
+type tripLengthFlag struct{ q *model.SearchQuery }
+
+func (f tripLengthFlag) String() string {
+	if f.q == nil {
+		return ""
+	}
+	return tripLengthLabel(*f.q)
+}
+
+func (f tripLengthFlag) Set(v string) error {
+	if strings.TrimSpace(v) == "" {
+		f.q.TripMinDays, f.q.TripMaxDays = 0, 0
+		return nil
+	}
+	minDays, maxDays, err := parseTripLength(v)
+	if err != nil {
+		return err
+	}
+	f.q.TripMinDays, f.q.TripMaxDays = minDays, maxDays
+	return nil
+}
+
+// tripLengthLabel formats the trip-length range as "10" or "10-14".
+func tripLengthLabel(q model.SearchQuery) string {
+	switch {
+	case q.TripMaxDays == 0:
+		return ""
+	case q.TripMinDays == q.TripMaxDays:
+		return strconv.Itoa(q.TripMinDays)
+	default:
+		return fmt.Sprintf("%d-%d", q.TripMinDays, q.TripMaxDays)
+	}
+}
+
+// formatTripDates names concrete travel dates, e.g. "2026-06-03 -> 2026-06-10".
+func formatTripDates(depart, ret string) string {
+	if ret == "" {
+		return depart
+	}
+	return depart + " -> " + ret
+}
+
+// queryGrid returns the dates a flexible query covers. Fixed queries give a
+// single cell.
+func queryGrid(q model.SearchQuery) (dateGrid, error) {
+	var g dateGrid
+	window := q.Depart
+	if q.DepartTo != "" {
+		window += ".." + q.DepartTo
+	}
+	var err error
+	if g.DepartFrom, g.DepartTo, err = parseDateRange(window); err != nil {
+		return g, err
+	}
+	if q.TripMaxDays > 0 || q.TripMinDays > 0 {
+		if q.TripMinDays < 1 || q.TripMaxDays < q.TripMinDays {
+			return g, fmt.Errorf("invalid trip length %d-%d days", q.TripMinDays, q.TripMaxDays)
+		}
+		g.TripMin, g.TripMax = q.TripMinDays, q.TripMaxDays
+	}
+	return g, nil
+}
+
+// formatDateWindow describes the dates of a query, e.g. "2026-06-01..2026-06-15
+// +10-14d" for a flexible round trip.
+func formatDateWindow(q model.SearchQuery) string {
+	out := q.Depart
+	if q.DepartTo != "" {
+		out += ".." + q.DepartTo
+	}
+	switch {
+	case q.TripMaxDays > 0:
+		out += " +" + tripLengthLabel(q) + "d"
+	case q.Return != "":
+		out += " -> " + q.Return
+	}
+	return out
+}
+
+func (a App) cmdSearchCalendar(g globalFlags, q model.SearchQuery, maxRequests int) error {
+	if err := validateQuery(q); err != nil {
+		return err
+	}
+	warnUnknownAirports(q)
+	grid, err := queryGrid(q)
+	if err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if n := grid.size() * len(routeQueries(q)); n > maxRequests {
+		return newExitError(ExitInvalidUsage, "date grid needs %d provider requests, over --max-requests %d (narrow --depart-range/--trip-length or raise --max-requests)", n, maxRequests)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	if err := validateProviderRuntime(cfg); err != nil {
+		return wrapValidationError(err)
+	}
+	p, err := a.resolveProvider(cfg, g)
+	if err != nil {
+		return err
+	}
+	deadline, err := parseDeadline(g)
+	if err != nil {
+		return err
+	}
+	sigCtx, stop := signalContext()
+	defer stop()
+	ctx, cancel := withDeadline(sigCtx, deadline)
+	defer cancel()
+	out, runErr := runCalendar(ctx, multiRouteSearch(p.Search, 1), q, grid, watchConcurrency(cfg.WatchConcurrency, p), time.Now())
+	switch {
+	case g.JSON:
+		if err := writeJSON(out); err != nil {
+			return err
+		}
+	case g.Plain:
+		writeCalendarPlain(out)
+	default:
+		writeCalendarMatrix(out)
+	}
+	return runErr
+}
+
+type calendarCell struct {
+	Depart      string `json:"depart"`
+	Return      string `json:"return,omitempty"`
+	TripLength  int    `json:"trip_length,omitempty"`
+	LowestPrice int    `json:"lowest_price"`
+	Currency    string `json:"currency"`
+	FlightCount int    `json:"flight_count"`
+	Error       string `json:"error,omitempty"`
+}
+
+type calendarOutput struct {
+	Query       model.SearchQuery `json:"query"`
+	DepartFrom  string            `json:"depart_from"`
+	DepartTo    string            `json:"depart_to"`
+	TripLengths []int             `json:"trip_lengths,omitempty"`
+	Requests    int               `json:"requests"`
+	Failed      int               `json:"failed"`
+	Cheapest    *calendarCell     `json:"cheapest,omitempty"`
+	Cells       []calendarCell    `json:"cells"`
+	CheckedAt   time.Time         `json:"checked_at"`
+}
+
+// runCalendar searches every grid cell and keeps the cheapest fare of each.
+// It fails only when the run was interrupted or every request failed; other
+// errors stay on their cells.
+func runCalendar(ctx context.Context, search watchSearchFunc, base model.SearchQuery, g dateGrid, concurrency int, now time.Time) (calendarOutput, error) {
+	queries := g.queries(base)
+	out := calendarOutput{
+		Query:      base,
+		DepartFrom: g.DepartFrom.Format(dateLayout),
+		DepartTo:   g.DepartTo.Format(dateLayout),
+		Requests:   len(queries) * len(routeQueries(base)),
+		Cells:      make([]calendarCell, 0, len(queries)),
+		CheckedAt:  now.UTC(),
+	}
+	out.Query.Depart, out.Query.Return = "", ""
+	out.Query.DepartTo, out.Query.TripMinDays, out.Query.TripMaxDays = "", 0, 0
+	if g.roundTrip() {
+		out.TripLengths = g.tripLengths()
+	}
+	outcomes := searchConcurrently(ctx, queries, search, concurrency)
+	var firstErr error
+	for k, q := range queries {
+		o := outcomes[k]
+		<-o.done
+		cell := calendarCell{Depart: q.Depart, Return: q.Return, Currency: firstOr(q.Currency, "USD")}
+		if q.Return != "" {
+			cell.TripLength = g.TripMin + k%len(g.tripLengths())
+		}
+		if o.err != nil {
+			cell.Error = o.err.Error()
+			out.Failed++
+			if firstErr == nil {
+				firstErr = o.err
+			}
+		} else {
+			cell.FlightCount = len(o.result.Flights)
+			cell.LowestPrice, cell.Currency = lowestFare(o.result)
+			if cell.FlightCount == 0 {
+				cell.Currency = firstOr(q.Currency, "USD")
+			}
+		}
+		out.Cells = append(out.Cells, cell)
+	}
+	for i := range out.Cells {
+		c := &out.Cells[i]
+		if c.LowestPrice > 0 && (out.Cheapest == nil || c.LowestPrice < out.Cheapest.LowestPrice) {
+			out.Cheapest = c
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return out, wrapProviderError(err)
+	}
+	if out.Failed == len(out.Cells) && firstErr != nil {
+		return out, wrapProviderError(firstErr)
+	}
+	return out, nil
+}
+
+func writeCalendarPlain(out calendarOutput) {
+	writePlainTableHeader("depart", "return", "trip_length", "lowest_price", "currency", "flight_count", "error")
+	for _, c := range out.Cells {
+		writePlainTableRow(
+			c.Depart,
+			c.Return,
+			strconv.Itoa(c.TripLength),
+			strconv.Itoa(c.LowestPrice),
+			c.Currency,
+			strconv.Itoa(c.FlightCount),
+			c.Error,
+		)
+	}
+}
+
+// writeCalendarMatrix prints departure dates down and trip lengths across,
+// marking the cheapest cell with "*".
+func writeCalendarMatrix(out calendarOutput) {
+	route := fmt.Sprintf("%s -> %s", out.Query.From, out.Query.To)
+	fmt.Printf("Cheapest fares for %s, departing %s..%s (%d requests)\n", route, out.DepartFrom, out.DepartTo, out.Requests)
+	cols := len(out.TripLengths)
+	header := fmt.Sprintf("%-12s", "depart")
+	if cols == 0 {
+		cols = 1
+		header += fmt.Sprintf("%10s", "one-way")
+	}
+	for _, n := range out.TripLengths {
+		header += fmt.Sprintf("%10s", fmt.Sprintf("%dd", n))
+	}
+	fmt.Println(header)
+	for i := 0; i < len(out.Cells); i += cols {
+		line := fmt.Sprintf("%-12s", out.Cells[i].Depart)
+		for j := i; j < i+cols && j < len(out.Cells); j++ {
+			line += fmt.Sprintf("%10s", calendarCellLabel(&out.Cells[j], out.Cheapest))
+		}
+		fmt.Println(line)
+	}
+	if out.Cheapest != nil {
+		c := out.Cheapest
+		trip := ""
+		if c.Return != "" {
+			trip = fmt.Sprintf(" returning %s (%d days)", c.Return, c.TripLength)
+		}
+		fmt.Printf("Cheapest: %d %s departing %s%s\n", c.LowestPrice, c.Currency, c.Depart, trip)
+	} else {
+		fmt.Println("No priced flights returned for any date.")
+	}
+	if out.Failed > 0 {
+		fmt.Printf("%d of %d dates failed (shown as err; --plain or --json include the errors)\n", out.Failed, len(out.Cells))
+	}
+}
+
+func calendarCellLabel(c, cheapest *calendarCell) string {
+	switch {
+	case c.Error != "":
+		return "err"
+	case c.LowestPrice == 0:
+		return "-"
+	case c == cheapest:
+		return fmt.Sprintf("*%d", c.LowestPrice)
+	default:
+		return strconv.Itoa(c.LowestPrice)
+	}
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/provider"
+)
+
+func TestParseDateRangeAndTripLength(t *testing.T) {
+	from, to, err := parseDateRange("2030-06-01..2030-06-15")
+	if err != nil || from.Format(dateLayout) != "2030-06-01" || to.Format(dateLayout) != "2030-06-15" {
+		t.Fatalf("parseDateRange = %v %v %v", from, to, err)
+	}
+	if _, _, err := parseDateRange("2030-06-15..2030-06-01"); err == nil {
+		t.Fatal("expected reversed range to fail")
+	}
+	if lo, hi, err := parseTripLength("10-14"); err != nil || lo != 10 || hi != 14 {
+		t.Fatalf("parseTripLength = %d %d %v", lo, hi, err)
+	}
+	for _, bad := range []string{"0", "14-10", "x"} {
+		if _, _, err := parseTripLength(bad); err == nil {
+			t.Fatalf("expected %q to fail", bad)
+		}
+	}
+}
+
+func TestRunCalendarKeepsCheapestPerCell(t *testing.T) {
+	from, to, _ := parseDateRange("2030-06-01..2030-06-02")
+	grid := dateGrid{DepartFrom: from, DepartTo: to, TripMin: 10, TripMax: 11}
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	search := func(_ context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		mu.Lock()
+		seen[q.Depart+"/"+q.Return] = true
+		mu.Unlock()
+		switch q.Depart + "/" + q.Return {
+		case "2030-06-02/2030-06-12":
+			return model.SearchResult{Flights: []model.Flight{{Price: 720, Currency: "USD"}, {Price: 610, Currency: "USD"}}}, nil
+		case "2030-06-02/2030-06-13":
+			return model.SearchResult{}, errors.New("boom")
+		}
+		return model.SearchResult{Flights: []model.Flight{{Price: 800, Currency: "USD"}}}, nil
+	}
+	out, err := runCalendar(context.Background(), search, model.SearchQuery{From: "SFO", To: "ATH"}, grid, 3, time.Now())
+	if err != nil {
+		t.Fatalf("runCalendar: %v", err)
+	}
+	if len(seen) != 4 || out.Requests != 4 || out.Failed != 1 || len(out.Cells) != 4 {
+		t.Fatalf("unexpected grid: seen=%v out=%+v", seen, out)
+	}
+	if c := out.Cheapest; c == nil || c.Depart != "2030-06-02" || c.Return != "2030-06-12" || c.TripLength != 10 || c.LowestPrice != 610 {
+		t.Fatalf("unexpected cheapest cell: %+v", out.Cheapest)
+	}
+	if out.Cells[3].Error != "boom" || out.Cells[3].TripLength != 11 {
+		t.Fatalf("expected failed cell kept with its error: %+v", out.Cells[3])
+	}
+}
+
+func TestRunCalendarCountsRequestsPerRoute(t *testing.T) {
+	from, to, _ := parseDateRange("2030-06-01..2030-06-03")
+	search := func(context.Context, model.SearchQuery) (model.SearchResult, error) {
+		return model.SearchResult{Flights: []model.Flight{{Price: 800, Currency: "USD"}}}, nil
+	}
+	out, err := runCalendar(context.Background(), search, model.SearchQuery{From: "SFO,OAK", To: "ATH"}, dateGrid{DepartFrom: from, DepartTo: to}, 1, time.Now())
+	if err != nil || len(out.Cells) != 3 || out.Requests != 6 {
+		t.Fatalf("expected 3 cells over 2 routes to need 6 requests, got cells=%d requests=%d err=%v", len(out.Cells), out.Requests, err)
+	}
+}
+
+func TestRunCalendarFailsWhenEveryRequestFails(t *testing.T) {
+	from, _, _ := parseDateRange("2030-06-01")
+	search := func(context.Context, model.SearchQuery) (model.SearchResult, error) {
+		return model.SearchResult{}, provider.ErrRateLimited
+	}
+	_, err := runCalendar(context.Background(), search, model.SearchQuery{}, dateGrid{DepartFrom: from, DepartTo: from}, 1, time.Now())
+	if ExitCode(err) != ExitProviderFailure {
+		t.Fatalf("expected provider failure, got %v", err)
+	}
+}
+
+func TestSearchCalendarRejectsOversizedGrid(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := NewApp("test")
+	err := app.Run([]string{"search", "--from", "SFO", "--to", "ATH", "--depart-range", "2030-06-01..2030-06-30", "--trip-length", "7-9"})
+	if ExitCode(err) != ExitInvalidUsage || !strings.Contains(err.Error(), "90 provider requests") {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	for _, args := range [][]string{
+		{"--depart", "2030-06-01", "--depart-range", "2030-06-01..2030-06-02"},
+		{"--depart", "2030-06-01", "--return", "2030-06-10", "--trip-length", "7"},
+		{"--depart-range", "2030-06-01..2030-06-02", "--booking"},
+		{"--depart", "2030-06-01", "--max-requests", "0"},
+		{"--depart", "2030-06-01", "--max-requests", "501"},
+	} {
+		err := app.Run(append([]string{"search", "--from", "SFO", "--to", "ATH"}, args...))
+		if ExitCode(err) != ExitInvalidUsage {
+			t.Fatalf("expected invalid usage for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestSearchCalendarPlainRows(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := NewApp("test")
+	if err := app.Run([]string{"auth", "login", "--provider", "google-url"}); err != nil {
+		t.Fatalf("auth login: %v", err)
+	}
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "search", "--from", "SFO", "--to", "ATH", "--depart-range", "2030-06-01..2030-06-02", "--trip-length", "7"})
+	})
+	if err != nil {
+		t.Fatalf("calendar search: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || lines[0] != "depart\treturn\ttrip_length\tlowest_price\tcurrency\tflight_count\terror" || !strings.HasPrefix(lines[2], "2030-06-02\t2030-06-09\t7\t") {
+		t.Fatalf("unexpected plain calendar output: %q", out)
+	}
+}
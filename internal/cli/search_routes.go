@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/agisilaos/gflight/internal/airports"
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/provider"
+)
+
+// routeQueries expands the origins and destinations of q, metro codes
+// included, into one query per airport pair. Pairs that start and end at the
+// same airport are skipped.
+func routeQueries(q model.SearchQuery) []model.SearchQuery {
+	var out []model.SearchQuery
+	for _, from := range airports.Expand(q.Origins()) {
+		for _, to := range airports.Expand(q.Destinations()) {
+			if from == to {
+				continue
+			}
+			r := q
+			r.From, r.To = from, to
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// suggestAirport matches typos like SF0 that are one letter from exactly
+// one known code, and names like "athens" by airport search. Codes with
+// several near neighbours get no suggestion, since they are as likely to be
+// real airports missing from the table.
+func suggestAirport(code string) string {
+	if len(code) == 3 {
+		match := ""
+		for _, c := range airports.Codes() {
+			if levenshtein(strings.ToLower(code), strings.ToLower(c)) != 1 {
+				continue
+			}
+			if match != "" {
+				return ""
+			}
+			match = c
+		}
+		return match
+	}
+	if len(code) > 3 {
+		if hits := airports.Search(code); len(hits) > 0 {
+			return hits[0].Code
+		}
+	}
+	return ""
+}
+
+// multiRouteSearch searches every airport pair of a multi-route query on up
+// to concurrency workers and merges the flights, labelled with their route,
+// into one sorted result. Failed routes become warnings; the search fails
+// only when every route failed, auth is missing or ctx is done. Single-route
+// queries pass straight through.
+func multiRouteSearch(search watchSearchFunc, concurrency int) watchSearchFunc {
+	return func(ctx context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		routes := routeQueries(q)
+		if len(routes) <= 1 {
+			return search(ctx, q)
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		outcomes := searchConcurrently(ctx, routes, search, concurrency)
+		merged := model.SearchResult{Query: q, Flights: []model.Flight{}, Routes: make([]model.RouteResult, 0, len(routes))}
+		var (
+			best      *model.SearchResult
+			bestPrice int
+			firstErr  error
+		)
+		for k, r := range routes {
+			o := outcomes[k]
+			<-o.done
+			label := r.From + "-" + r.To
+			rr := model.RouteResult{From: r.From, To: r.To, Currency: firstOr(q.Currency, "USD")}
+			if o.err != nil {
+				if ctx.Err() != nil || errors.Is(o.err, provider.ErrAuthRequired) {
+					return model.SearchResult{}, o.err
+				}
+				if firstErr == nil {
+					firstErr = o.err
+				}
+				rr.Error = o.err.Error()
+				merged.Warnings = append(merged.Warnings, fmt.Sprintf("route %s: %v", label, o.err))
+				merged.Routes = append(merged.Routes, rr)
+				continue
+			}
+			res := o.result
+			rr.URL, rr.FlightCount = res.URL, len(res.Flights)
+			if rr.FlightCount > 0 {
+				rr.LowestPrice, rr.Currency = lowestFare(res)
+			}
+			for _, w := range res.Warnings {
+				merged.Warnings = append(merged.Warnings, fmt.Sprintf("route %s: %s", label, w))
+			}
+			for _, f := range res.Flights {
+				f.From, f.To = r.From, r.To
+				merged.Flights = append(merged.Flights, f)
+			}
+			if best == nil || (rr.LowestPrice > 0 && (bestPrice == 0 || rr.LowestPrice < bestPrice)) {
+				best, bestPrice = &o.result, rr.LowestPrice
+			}
+			merged.Routes = append(merged.Routes, rr)
+		}
+		if best == nil {
+			return model.SearchResult{}, firstErr
+		}
+		merged.URL, merged.Insights, merged.CheckedAt = best.URL, best.Insights, best.CheckedAt
+		sortFlights(merged.Flights, q.SortBy)
+		return merged, nil
+	}
+}
+
+// sortFlights orders flights merged from several routes by the query's sort
+// order, cheapest first on ties. "best" has no cross-route ranking, so it
+// sorts by price.
+func sortFlights(flights []model.Flight, order model.SortOrder) {
+	order, _ = model.ParseSortOrder(string(order))
+	byOrder := func(a, b model.Flight) int {
+		switch order {
+		case model.SortDepartureTime:
+			return strings.Compare(a.DepartTime, b.DepartTime)
+		case model.SortArrivalTime:
+			return strings.Compare(a.ArriveTime, b.ArriveTime)
+		case model.SortDuration:
+			return cmp.Compare(a.TotalDurationMinutes, b.TotalDurationMinutes)
+		case model.SortEmissions:
+			return cmp.Compare(emissionGrams(a), emissionGrams(b))
+		}
+		return 0
+	}
+	slices.SortStableFunc(flights, func(a, b model.Flight) int {
+		if c := byOrder(a, b); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Price, b.Price)
+	})
+}
+
+// cheapestRoute names the airport pair of the cheapest flight, e.g. OAK-ATH.
+func cheapestRoute(res model.SearchResult) string {
+	i := cheapestFlight(res)
+	if i < 0 {
+		return ""
+	}
+	return res.Flights[i].From + "-" + res.Flights[i].To
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/provider"
+)
+
+func TestRouteQueriesExpandsMetroCodes(t *testing.T) {
+	routes := routeQueries(model.SearchQuery{From: "NYC,JFK", To: "ATH,JFK", Depart: "2030-06-10"})
+	var got []string
+	for _, r := range routes {
+		got = append(got, r.From+"-"+r.To)
+	}
+	want := "JFK-ATH,EWR-ATH,EWR-JFK,LGA-ATH,LGA-JFK"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("routeQueries = %v, want %s", got, want)
+	}
+}
+
+func TestMultiRouteSearchMergesAndLabelsRoutes(t *testing.T) {
+	search := func(_ context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		switch q.From {
+		case "SFO":
+			return model.SearchResult{Query: q, URL: "https://sfo", Flights: []model.Flight{{From: q.From, To: q.To, Price: 820}, {From: q.From, To: q.To, Price: 700}}}, nil
+		case "OAK":
+			return model.SearchResult{Query: q, URL: "https://oak", Flights: []model.Flight{{Price: 640}}, Insights: &model.PriceInsights{PriceLevel: "low"}}, nil
+		}
+		return model.SearchResult{}, errors.New("boom")
+	}
+	q := model.SearchQuery{From: "SFO,OAK,SJC", To: "ATH", Currency: "USD", SortBy: model.SortPrice}
+	res, err := multiRouteSearch(search, 3)(context.Background(), q)
+	if err != nil {
+		t.Fatalf("multiRouteSearch: %v", err)
+	}
+	var prices []int
+	for _, f := range res.Flights {
+		prices = append(prices, f.Price)
+	}
+	if len(prices) != 3 || prices[0] != 640 || prices[2] != 820 || res.Flights[0].From != "OAK" || res.Flights[0].To != "ATH" {
+		t.Fatalf("expected merged flights sorted by price and labelled, got %+v", res.Flights)
+	}
+	if res.Query.From != "SFO,OAK,SJC" || res.URL != "https://oak" || res.Insights == nil || cheapestRoute(res) != "OAK-ATH" {
+		t.Fatalf("expected the cheapest route's link and insights, got %+v", res)
+	}
+	if len(res.Routes) != 3 || res.Routes[2].Error != "boom" || len(res.Warnings) != 1 || res.Routes[0].LowestPrice != 700 {
+		t.Fatalf("unexpected route summaries: %+v %v", res.Routes, res.Warnings)
+	}
+}
+
+func TestMultiRouteSearchFailsOnAuthOrWhenEveryRouteFails(t *testing.T) {
+	q := model.SearchQuery{From: "SFO,OAK", To: "ATH"}
+	for _, want := range []error{provider.ErrAuthRequired, provider.ErrRateLimited} {
+		search := func(context.Context, model.SearchQuery) (model.SearchResult, error) {
+			return model.SearchResult{}, want
+		}
+		if _, err := multiRouteSearch(search, 1)(context.Background(), q); !errors.Is(err, want) {
+			t.Fatalf("expected %v, got %v", want, err)
+		}
+	}
+}
+
+func TestRunWatchPassMultiRouteAlertNamesRoute(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	watches := []model.Watch{{ID: "w1", Name: "bay", Enabled: true, TargetPrice: 700, Query: model.SearchQuery{From: "SFO,OAK", To: "ATH", Depart: "2030-06-10"}}}
+	search := func(_ context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		price := 800
+		if q.From == "OAK" {
+			price = 650
+		}
+		return model.SearchResult{Query: q, Flights: []model.Flight{{Price: price, Currency: "USD"}}}, nil
+	}
+	report, _ := runWatchPass(watches, "", true, search, func(model.Watch, model.Alert) error { return nil }, now, false, nil)
+	if report.Triggered != 1 || report.Alerts[0].Route != "OAK-ATH" || report.Alerts[0].LowestPrice != 650 {
+		t.Fatalf("expected alert on the cheapest route, got %+v", report)
+	}
+}
+
+func TestSearchRejectsRoutesOverBudget(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := NewApp("test")
+	err := app.Run([]string{"search", "--from", "NYC,WAS", "--to", "LON", "--depart", "2030-06-10", "--max-requests", "10"})
+	if ExitCode(err) != ExitInvalidUsage || !strings.Contains(err.Error(), "36 routes") {
+		t.Fatalf("expected route budget error, got %v", err)
+	}
+	q := model.SearchQuery{From: "SFO,OAK", To: "ATH", Depart: "2030-06-10", Return: "2030-06-20", Booking: true}
+	sp := provider.SerpAPIProvider{ReturnLookups: 2}
+	// Each route: one search, three returns (raised to the booking cap) and three bookings.
+	if err := checkSearchRequests(sp, q, 13); ExitCode(err) != ExitInvalidUsage || !strings.Contains(err.Error(), "up to 14 provider requests") {
+		t.Fatalf("expected return and booking lookups counted, got %v", err)
+	}
+	if err := checkSearchRequests(sp, q, 14); err != nil {
+		t.Fatalf("expected 14 requests to fit, got %v", err)
+	}
+	if err := checkSearchRequests(provider.GoogleURLProvider{}, q, 2); err != nil {
+		t.Fatalf("expected one request per route without lookups, got %v", err)
+	}
+	err = app.Run([]string{"search", "--from", "ATH", "--to", "ath", "--depart", "2030-06-10"})
+	if ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected same origin and destination to be rejected, got %v", err)
+	}
+}
+
+func TestSearchPlainListsEachRoute(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := NewApp("test")
+	if err := app.Run([]string{"auth", "login", "--provider", "google-url"}); err != nil {
+		t.Fatalf("auth login: %v", err)
+	}
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "search", "--from", "sfo,oak", "--to", "ATH", "--depart", "2030-06-10"})
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if !strings.Contains(out, "route=SFO-ATH\t") || !strings.Contains(out, "route=OAK-ATH\t") || !strings.Contains(out, "f=OAK") {
+		t.Fatalf("expected a line per route, got %q", out)
+	}
+}
+
+func TestValidateQuerySuggestsAirportCodes(t *testing.T) {
+	err := validateQuery(model.SearchQuery{From: "SF0", To: "ATH", Depart: "2030-06-10"})
+	if ExitCode(err) != ExitInvalidUsage || !strings.Contains(err.Error(), `unknown airport code "SF0" (did you mean SFO?)`) {
+		t.Fatalf("expected typo suggestion, got %v", err)
+	}
+	if hints := ErrorHints(err); len(hints) == 0 || strings.Contains(strings.Join(hints, "\n"), "SFO") {
+		t.Fatalf("expected a hint that does not rewrite the code, got %q", hints)
+	}
+	err = validateQuery(model.SearchQuery{From: "SFO", To: "ATHENS", Depart: "2030-06-10"})
+	if err == nil || !strings.Contains(err.Error(), "--to") || !strings.Contains(err.Error(), "did you mean ATH?") {
+		t.Fatalf("expected city name suggestion, got %v", err)
+	}
+}
+
+func TestValidateQueryUnknownWellFormedAirports(t *testing.T) {
+	q := model.SearchQuery{From: "BOI", To: "RNO", Depart: "2030-06-10", Adults: 1}
+	err := validateQuery(q)
+	if ExitCode(err) != ExitInvalidUsage || strings.Contains(err.Error(), "did you mean") {
+		t.Fatalf("expected unknown codes rejected without a far-fetched suggestion, got %v", err)
+	}
+	if hints := ErrorHints(err); len(hints) == 0 || !strings.Contains(hints[0], "--allow-unknown-airports") {
+		t.Fatalf("expected the override flag in the hint, got %q", hints)
+	}
+	q.AllowUnknownAirports = true
+	if err := validateQuery(q); err != nil {
+		t.Fatalf("expected well-formed codes allowed, got %v", err)
+	}
+	if got := unknownAirportCodes(q); strings.Join(got, ",") != "BOI,RNO" {
+		t.Fatalf("expected both codes reported for the warning, got %v", got)
+	}
+	q.From = "B0I"
+	if err := validateQuery(q); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected a malformed code rejected even when allowed, got %v", err)
+	}
+}
+
+func TestAirportsSearchPlain(t *testing.T) {
+	app := NewApp("test")
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "airports", "search", "new", "york", "--limit", "2"})
+	})
+	if err != nil {
+		t.Fatalf("airports search: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || lines[0] != "code\tname\tcity\tcountry\ttimezone\tlatitude\tlongitude\tairports" || !strings.HasPrefix(lines[1], "NYC\t") || !strings.HasSuffix(lines[1], "\tJFK,EWR,LGA") {
+		t.Fatalf("unexpected plain output: %q", out)
+	}
+	if err := app.Run([]string{"airports", "search", "zzzz"}); ExitCode(err) != ExitNoMatches {
+		t.Fatalf("expected no-match exit, got %v", err)
+	}
+}
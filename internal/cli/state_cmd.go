@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/agisilaos/gflight/internal/watcher"
+)
+
+type stateMigrateOutput struct {
+	watcher.MigrationResult
+	StorePath string `json:"store_path"`
+	DryRun    bool   `json:"dry_run"`
+	Migrated  bool   `json:"migrated"`
+}
+
+func (a App) cmdState(g globalFlags, args []string) error {
+	if len(args) == 0 {
+		return newExitError(ExitInvalidUsage, "usage: gflight state migrate [--from-backend NAME] [--dry-run]")
+	}
+	switch args[0] {
+	case "migrate":
+		return a.cmdStateMigrate(g, args[1:])
+	default:
+		if s := suggestClosest(args[0], []string{"migrate"}); s != "" {
+			return newExitError(ExitInvalidUsage, "unknown state action %q (did you mean %q?)", args[0], s)
+		}
+		return newExitError(ExitInvalidUsage, "unknown state action %q", args[0])
+	}
+}
+
+func (a App) cmdStateMigrate(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("state migrate", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	dryRun := fs.Bool("dry-run", false, "Report pending migrations without changing the store")
+	fromBackend := fs.String("from-backend", "", "Move watches, history, snapshots and run log from this storage backend into the configured one")
+	if err := fs.Parse(args); err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if *fromBackend != "" {
+		return a.cmdStateMoveBackend(g, *fromBackend, *dryRun)
+	}
+	store, err := a.watcherStore(g.StateDir)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	var res watcher.MigrationResult
+	if *dryRun {
+		res, err = store.PendingMigrations()
+	} else {
+		res, err = store.Migrate()
+	}
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	out := stateMigrateOutput{
+		MigrationResult: res,
+		StorePath:       store.Location(),
+		DryRun:          *dryRun,
+		Migrated:        !*dryRun && len(res.Pending) > 0,
+	}
+	if g.JSON {
+		return writeJSON(out)
+	}
+	if g.Plain {
+		writePlainKV(
+			"from_version", strconv.Itoa(out.FromVersion),
+			"to_version", strconv.Itoa(out.ToVersion),
+			"pending", strconv.Itoa(len(out.Pending)),
+			"dry_run", strconv.FormatBool(out.DryRun),
+			"migrated", strconv.FormatBool(out.Migrated),
+			"backup_path", out.BackupPath,
+		)
+		for _, m := range out.Pending {
+			writePlainKV("version", strconv.Itoa(m.Version), "description", m.Description)
+		}
+		return nil
+	}
+	if len(out.Pending) == 0 {
+		fmt.Printf("Watch store is up to date (schema v%d): %s\n", out.ToVersion, out.StorePath)
+		return nil
+	}
+	verb := "Migrated"
+	if out.DryRun {
+		verb = "Would migrate"
+	}
+	fmt.Printf("%s watch store from schema v%d to v%d: %s\n", verb, out.FromVersion, out.ToVersion, out.StorePath)
+	for _, m := range out.Pending {
+		fmt.Printf("  v%d: %s\n", m.Version, m.Description)
+	}
+	if out.BackupPath != "" {
+		fmt.Printf("Backup: %s\n", out.BackupPath)
+	}
+	return nil
+}
+
+type stateMoveOutput struct {
+	watcher.MoveResult
+	DryRun bool `json:"dry_run"`
+}
+
+// cmdStateMoveBackend moves everything stored with the from backend into the
+// configured storage_backend.
+func (a App) cmdStateMoveBackend(g globalFlags, from string, dryRun bool) error {
+	dst, err := a.storage(g.StateDir)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	src, err := watcher.Open(dst.Dir, from)
+	if err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if src.Backend == dst.Backend {
+		return newExitError(ExitInvalidUsage, "--from-backend %s is already the configured storage_backend; set storage_backend to the target backend first", from)
+	}
+	res, err := watcher.MoveStorage(src, dst, dryRun)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	out := stateMoveOutput{MoveResult: res, DryRun: dryRun}
+	if g.JSON {
+		return writeJSON(out)
+	}
+	if g.Plain {
+		writePlainKV(
+			"from_backend", out.From,
+			"to_backend", out.To,
+			"watches", strconv.Itoa(out.Watches),
+			"history_entries", strconv.Itoa(out.HistoryEntries),
+			"snapshots", strconv.Itoa(out.Snapshots),
+			"run_log_entries", strconv.Itoa(out.RunLogEntries),
+			"dry_run", strconv.FormatBool(out.DryRun),
+			"moved_aside", strings.Join(out.MovedAside, ","),
+		)
+		return nil
+	}
+	verb := "Moved"
+	if out.DryRun {
+		verb = "Would move"
+	}
+	fmt.Printf("%s %d watches from %s to %s storage (%d history entries, %d snapshots, %d run log entries)\n",
+		verb, out.Watches, out.From, out.To, out.HistoryEntries, out.Snapshots, out.RunLogEntries)
+	for _, p := range out.MovedAside {
+		fmt.Printf("Kept old files at: %s\n", p)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStateMigrateDryRunThenApply(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	legacy := `{"watches": [{"id": "w_1", "name": "athens", "query": {"from": "SFO", "to": "ATH", "depart": "2026-06-10"}, "enabled": true}]}`
+	path := filepath.Join(stateDir, "watches.json")
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("write legacy store: %v", err)
+	}
+	app := NewApp("test")
+
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "state", "migrate", "--dry-run"})
+	})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !strings.HasPrefix(out, "from_version=0\tto_version=1\tpending=1\tdry_run=true\tmigrated=false") {
+		t.Fatalf("unexpected dry-run output: %q", out)
+	}
+	if b, _ := os.ReadFile(path); string(b) != legacy {
+		t.Fatalf("dry run changed the store: %q", b)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "state", "migrate"})
+	})
+	if err != nil || !strings.Contains(out, "migrated=true") || !strings.Contains(out, "backup_path="+path+".v0.bak") {
+		t.Fatalf("migrate: out=%q err=%v", out, err)
+	}
+	if b, _ := os.ReadFile(path); !strings.Contains(string(b), `"schema_version": 1`) {
+		t.Fatalf("expected versioned store, got %q", b)
+	}
+	out, _ = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--state-dir", stateDir, "state", "migrate"})
+	})
+	if !strings.HasPrefix(out, "Watch store is up to date (schema v1)") {
+		t.Fatalf("expected up to date, got %q", out)
+	}
+}
+
+func TestStateMigrateFromBackend(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Setenv("GFLIGHT_STORAGE_BACKEND", "jsonl-dir")
+
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "list"})
+	})
+	if err != nil || strings.Contains(out, "SFO") {
+		t.Fatalf("expected json watches hidden from the jsonl-dir backend, out=%q err=%v", out, err)
+	}
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "doctor"})
+	})
+	if !strings.Contains(out, "WARN\tstate.stray\t") {
+		t.Fatalf("expected a stray watch warning from doctor, out=%q err=%v", out, err)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "state", "migrate", "--from-backend", "json"})
+	})
+	if err != nil || !strings.HasPrefix(out, "from_backend=json\tto_backend=jsonl-dir\twatches=1\t") {
+		t.Fatalf("migrate from backend: out=%q err=%v", out, err)
+	}
+	out, _ = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "list"})
+	})
+	if !strings.Contains(out, "SFO") {
+		t.Fatalf("expected the moved watch listed, got %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "watches.json.moved")); err != nil {
+		t.Fatalf("expected the old store kept aside: %v", err)
+	}
+
+	err = app.Run([]string{"--state-dir", stateDir, "state", "migrate", "--from-backend", "jsonl-dir"})
+	if ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected invalid usage moving from the configured backend, got %v", err)
+	}
+}
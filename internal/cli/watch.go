@@ -1,23 +1,93 @@
 package cli
 
 import (
-	"path/filepath"
+	"fmt"
+	"os"
 
 	"github.com/agisilaos/gflight/internal/config"
 	"github.com/agisilaos/gflight/internal/watcher"
 )
 
-func (a App) watcherStore(stateOverride string) (watcher.Store, error) {
+// storage opens the configured storage_backend under the state directory.
+// Commands open it once and pass the handle to whatever needs the watches,
+// history, snapshots or run log.
+func (a App) storage(stateOverride string) (watcher.Storage, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return watcher.Storage{}, err
+	}
+	return openStorage(stateOverride, cfg)
+}
+
+// openStorage is storage for commands that have loaded the config already.
+func openStorage(stateOverride string, cfg config.Config) (watcher.Storage, error) {
 	dir, err := config.StateDir(stateOverride)
 	if err != nil {
-		return watcher.Store{}, err
+		return watcher.Storage{}, err
+	}
+	return watcher.Open(dir, cfg.StorageBackend)
+}
+
+// watchStorage is storage for commands that read watches, warning about
+// watches left in another backend.
+func (a App) watchStorage(stateOverride string) (watcher.Storage, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return watcher.Storage{}, err
+	}
+	return openWatchStorage(stateOverride, cfg)
+}
+
+// openWatchStorage is watchStorage for commands that have loaded the config
+// already.
+func openWatchStorage(stateOverride string, cfg config.Config) (watcher.Storage, error) {
+	st, err := openStorage(stateOverride, cfg)
+	if err != nil {
+		return st, err
+	}
+	warnStrayWatches(st)
+	return st, nil
+}
+
+func (a App) watcherStore(stateOverride string) (watcher.Backend, error) {
+	st, err := a.watchStorage(stateOverride)
+	return st.Watches, err
+}
+
+// warnStrayWatches points at watches left in another backend, which would
+// otherwise seem to vanish after storage_backend is switched.
+func warnStrayWatches(st watcher.Storage) {
+	other, n, err := watcher.StrayWatches(st.Dir, st.Backend)
+	if other == "" || err != nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: %d watch(es) are stored with the %s backend but storage_backend is %s; move them with: gflight state migrate --from-backend %s\n", n, other, st.Backend, other)
+}
+
+// lockedStorage returns storage with the watch store's lock held, for
+// commands that load, modify and save watches. Callers must defer the
+// returned unlock.
+func (a App) lockedStorage(stateOverride string) (watcher.Storage, func(), error) {
+	st, err := a.watchStorage(stateOverride)
+	if err != nil {
+		return st, nil, wrapExitError(ExitGenericFailure, err)
+	}
+	unlock, err := lockStorage(st)
+	return st, unlock, err
+}
+
+// lockStorage takes the watch store's lock of storage opened already.
+func lockStorage(st watcher.Storage) (func(), error) {
+	unlock, err := st.Watches.Lock()
+	if err != nil {
+		return nil, wrapExitError(ExitGenericFailure, err)
 	}
-	return watcher.Store{Path: filepath.Join(dir, "watches.json")}, nil
+	return unlock, nil
 }
 
 func (a App) cmdWatch(g globalFlags, args []string) error {
 	if len(args) == 0 {
-		return newExitError(ExitInvalidUsage, "watch requires subcommand: create|list|enable|disable|delete|run|test")
+		return newExitError(ExitInvalidUsage, "watch requires subcommand: create|update|list|enable|disable|delete|run|runs|test|show|history|export|import")
 	}
 	sub := args[0]
 	argv := args[1:]
@@ -36,8 +106,20 @@ func (a App) cmdWatch(g globalFlags, args []string) error {
 		return a.cmdWatchRun(g, argv)
 	case "test":
 		return a.cmdWatchTest(g, argv)
+	case "update":
+		return a.cmdWatchUpdate(g, argv)
+	case "export":
+		return a.cmdWatchExport(g, argv)
+	case "import":
+		return a.cmdWatchImport(g, argv)
+	case "show":
+		return a.cmdWatchShow(g, argv)
+	case "history":
+		return a.cmdWatchHistory(g, argv)
+	case "runs":
+		return a.cmdWatchRuns(g, argv)
 	default:
-		if s := suggestClosest(sub, []string{"create", "list", "enable", "disable", "delete", "run", "test"}); s != "" {
+		if s := suggestClosest(sub, []string{"create", "update", "list", "enable", "disable", "delete", "run", "runs", "test", "show", "history", "export", "import"}); s != "" {
 			return newExitError(ExitInvalidUsage, "unknown watch subcommand %q (did you mean %q?)", sub, s)
 		}
 		return newExitError(ExitInvalidUsage, "unknown watch subcommand %q", sub)
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+const (
+	alertStateArmed   = "armed"
+	alertStateFired   = "fired"
+	alertStateRearmed = "rearmed"
+
+	defaultRearmPercent = model.DefaultRearmPercent
+)
+
+type suppressedAlert struct {
+	model.Alert
+	SuppressedBy string `json:"suppressed_by"`
+}
+
+func parseCooldown(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("must be a non-negative duration (e.g. 6h)")
+	}
+	return d, nil
+}
+
+// applyAlertState moves a watch through armed -> fired -> rearmed and decides
+// whether a triggered alert should notify. Once fired, a watch only alerts
+// again when the price drops below the fired price or after it has bounced
+// back above the rearm band. The cooldown is a minimum gap between alerts.
+// It returns an empty string when the alert should be sent, otherwise the
+// reason it was suppressed.
+func applyAlertState(w *model.Watch, triggered bool, price int, now time.Time) string {
+	st := &w.AlertState
+	if st.Status == "" {
+		st.Status = alertStateArmed
+	}
+	if st.Status == alertStateFired && price > 0 && float64(price) > float64(st.FiredPrice)*(1+w.RearmPercent/100) {
+		st.Status = alertStateRearmed
+		st.RearmedAt = now.UTC()
+	}
+	if !triggered {
+		return ""
+	}
+	if w.Cooldown != "" && !st.FiredAt.IsZero() {
+		if cooldown, err := parseCooldown(w.Cooldown); err == nil && now.Sub(st.FiredAt) < cooldown {
+			return fmt.Sprintf("cooldown %s active since %s", w.Cooldown, st.FiredAt.Format(time.RFC3339))
+		}
+	}
+	if st.Status == alertStateFired && price >= st.FiredPrice {
+		return fmt.Sprintf("already fired at %d; waiting for a lower price or a rebound more than %s%% above it", st.FiredPrice, strconv.FormatFloat(w.RearmPercent, 'f', -1, 64))
+	}
+	st.Status = alertStateFired
+	st.FiredAt = now.UTC()
+	st.FiredPrice = price
+	return ""
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestApplyAlertStateDeduplicatesUntilLowerOrRearmed(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	w := model.Watch{RearmPercent: 5}
+
+	steps := []struct {
+		price      int
+		triggered  bool
+		wantNotify bool
+		wantStatus string
+	}{
+		{price: 650, triggered: true, wantNotify: true, wantStatus: alertStateFired},
+		{price: 650, triggered: true, wantNotify: false, wantStatus: alertStateFired},
+		{price: 680, triggered: true, wantNotify: false, wantStatus: alertStateFired},
+		{price: 640, triggered: true, wantNotify: true, wantStatus: alertStateFired},
+		{price: 700, triggered: false, wantNotify: true, wantStatus: alertStateRearmed},
+		{price: 660, triggered: true, wantNotify: true, wantStatus: alertStateFired},
+	}
+	for i, step := range steps {
+		now = now.Add(time.Hour)
+		suppressedBy := applyAlertState(&w, step.triggered, step.price, now)
+		if step.triggered && (suppressedBy == "") != step.wantNotify {
+			t.Fatalf("step %d: expected notify=%t, suppressed_by=%q", i, step.wantNotify, suppressedBy)
+		}
+		if w.AlertState.Status != step.wantStatus {
+			t.Fatalf("step %d: expected status %s, got %s", i, step.wantStatus, w.AlertState.Status)
+		}
+	}
+	if w.AlertState.FiredPrice != 660 {
+		t.Fatalf("expected fired price 660, got %d", w.AlertState.FiredPrice)
+	}
+}
+
+func TestApplyAlertStateCooldown(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	w := model.Watch{Cooldown: "6h"}
+	if s := applyAlertState(&w, true, 650, now); s != "" {
+		t.Fatalf("expected first alert to notify, got %q", s)
+	}
+	if s := applyAlertState(&w, true, 600, now.Add(2*time.Hour)); s == "" {
+		t.Fatalf("expected lower price within cooldown to be suppressed")
+	}
+	if s := applyAlertState(&w, true, 600, now.Add(7*time.Hour)); s != "" {
+		t.Fatalf("expected lower price after cooldown to notify, got %q", s)
+	}
+}
+
+func TestRunWatchPassReportsSuppressedAlerts(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	watches := []model.Watch{{ID: "w1", Name: "athens", Enabled: true, TargetPrice: 700}}
+	search := func(context.Context, model.SearchQuery) (model.SearchResult, error) {
+		return model.SearchResult{Flights: []model.Flight{{Price: 650, Currency: "USD"}}}, nil
+	}
+	notified := 0
+	notify := func(model.Watch, model.Alert) error {
+		notified++
+		return nil
+	}
+
+	first, _ := runWatchPass(watches, "", true, search, notify, now, false, nil)
+	second, _ := runWatchPass(watches, "", true, search, notify, now.Add(time.Hour), false, nil)
+	if first.Triggered != 1 || first.Suppressed != 0 {
+		t.Fatalf("unexpected first report: %+v", first)
+	}
+	if second.Triggered != 0 || second.Suppressed != 1 || len(second.SuppressedAlerts) != 1 {
+		t.Fatalf("unexpected second report: %+v", second)
+	}
+	if second.SuppressedAlerts[0].SuppressedBy == "" || second.SuppressedAlerts[0].WatchID != "w1" {
+		t.Fatalf("expected suppressed alert details, got %+v", second.SuppressedAlerts[0])
+	}
+	if notified != 1 {
+		t.Fatalf("expected 1 notification, got %d", notified)
+	}
+}
@@ -0,0 +1,449 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/watcher"
+)
+
+const (
+	importActionCreate    = "create"
+	importActionSkip      = "skip"
+	importActionOverwrite = "overwrite"
+	importActionRename    = "rename"
+	importActionRemove    = "remove"
+)
+
+var importConflictModes = []string{"error", importActionSkip, importActionOverwrite, importActionRename}
+
+// watchExport is the file format shared by watch export and watch import. It
+// is a superset of watches.json, so a copied state file imports as-is.
+type watchExport struct {
+	SchemaVersion int           `json:"schema_version,omitempty"`
+	ExportedAt    time.Time     `json:"exported_at"`
+	Watches       []model.Watch `json:"watches"`
+}
+
+type watchImportItem struct {
+	Action     string `json:"action"`
+	WatchID    string `json:"watch_id"`
+	Name       string `json:"name"`
+	OriginalID string `json:"original_id,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	// HistoryReset marks an imported watch that replaces a stored one with a
+	// different fare, which deletes the stored watch's price history,
+	// snapshot and run state.
+	HistoryReset bool `json:"history_reset,omitempty"`
+}
+
+type watchImportReport struct {
+	Mode        string            `json:"mode"`
+	DryRun      bool              `json:"dry_run"`
+	Created     int               `json:"created"`
+	Skipped     int               `json:"skipped"`
+	Overwritten int               `json:"overwritten"`
+	Renamed     int               `json:"renamed"`
+	Removed     int               `json:"removed"`
+	Items       []watchImportItem `json:"items"`
+}
+
+func (a App) cmdWatchExport(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("watch export", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	id := fs.String("id", "", "Watch ID")
+	all := fs.Bool("all", false, "Export all watches")
+	if err := fs.Parse(args); err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if (*id == "" && !*all) || (*id != "" && *all) {
+		return newExitError(ExitInvalidUsage, "watch export requires exactly one of --all or --id")
+	}
+	store, err := a.watcherStore(g.StateDir)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	ws, err := store.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	out := watchExport{SchemaVersion: watcher.CurrentSchemaVersion, ExportedAt: time.Now().UTC(), Watches: []model.Watch{}}
+	for _, w := range ws.Watches {
+		if *all || w.ID == *id {
+			out.Watches = append(out.Watches, w)
+		}
+	}
+	if *id != "" && len(out.Watches) == 0 {
+		return newExitError(ExitGenericFailure, "watch not found: %s", *id)
+	}
+	return writeJSON(out)
+}
+
+func (a App) cmdWatchImport(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("watch import", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	merge := fs.Bool("merge", false, "Add imported watches to existing ones (default)")
+	replace := fs.Bool("replace", false, "Replace all existing watches with the imported set")
+	onConflict := fs.String("on-conflict", "error", "With --merge, how to handle an existing watch ID: error|skip|overwrite|rename")
+	stripRuntime := fs.Bool("strip-runtime", false, "Drop last run time, last lowest price and alert state")
+	dryRun := fs.Bool("dry-run", false, "Preview the import without saving")
+	force := fs.Bool("force", false, "Allow --replace to remove existing watches")
+	file, err := parseWithPositional(fs, args)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		return newExitError(ExitInvalidUsage, "usage: gflight watch import <file.json|-> [--merge|--replace] [--dry-run]")
+	}
+	if *merge && *replace {
+		return newExitError(ExitInvalidUsage, "--merge and --replace are mutually exclusive")
+	}
+	if !slices.Contains(importConflictModes, *onConflict) {
+		return newExitError(ExitInvalidUsage, "invalid --on-conflict %q (use %s)", *onConflict, strings.Join(importConflictModes, ", "))
+	}
+	if *replace && flagWasSet(fs, "on-conflict") {
+		return newExitError(ExitInvalidUsage, "--on-conflict only applies to --merge")
+	}
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return newExitError(ExitInvalidUsage, "%v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	imported, err := parseWatchExport(r)
+	if err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	st, err := openWatchStorage(g.StateDir, cfg)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	unlock, err := lockStorage(st)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	ws, err := st.Watches.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	now := time.Now().UTC()
+	prepared, stale, err := prepareImportedWatches(imported, *stripRuntime, cfg, now)
+	if err != nil {
+		return err
+	}
+	for _, item := range stale {
+		fmt.Fprintf(os.Stderr, "warning: import watch %s skipped: %s\n", item.WatchID, item.Reason)
+	}
+	var (
+		result []model.Watch
+		report watchImportReport
+	)
+	if *replace {
+		result, report = importReplace(ws.Watches, prepared, stale)
+		if report.Removed > 0 && !*force && !*dryRun {
+			return newExitError(ExitInvalidUsage, "destructive action: --replace removes %d existing watch(es); pass --force", report.Removed)
+		}
+	} else {
+		result, report, err = importMerge(ws.Watches, prepared, *onConflict, now)
+		if err != nil {
+			return err
+		}
+	}
+	report.DryRun = *dryRun
+	report.Skipped += len(stale)
+	report.Items = append(report.Items, stale...)
+	if err := checkUniqueWatchKeys(result); err != nil {
+		return err
+	}
+	if !*dryRun {
+		ws.Watches = result
+		if err := st.Watches.Save(ws); err != nil {
+			return wrapExitError(ExitGenericFailure, err)
+		}
+		deleted := []string{}
+		for _, item := range report.Items {
+			if item.Action == importActionRemove || item.HistoryReset {
+				deleted = append(deleted, item.WatchID)
+			}
+		}
+		if err := deleteWatchArtifacts(st, deleted); err != nil {
+			return err
+		}
+	}
+
+	if g.JSON {
+		return writeJSON(report)
+	}
+	if g.Plain {
+		writePlainKV(
+			"mode", report.Mode,
+			"dry_run", strconv.FormatBool(report.DryRun),
+			"created", strconv.Itoa(report.Created),
+			"skipped", strconv.Itoa(report.Skipped),
+			"overwritten", strconv.Itoa(report.Overwritten),
+			"renamed", strconv.Itoa(report.Renamed),
+			"removed", strconv.Itoa(report.Removed),
+		)
+		for _, item := range report.Items {
+			writePlainKV("action", item.Action, "watch_id", item.WatchID, "original_id", item.OriginalID, "history_reset", strconv.FormatBool(item.HistoryReset))
+		}
+		return nil
+	}
+	verb := "Imported"
+	if report.DryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d watch(es) from %s (%s): created=%d overwritten=%d renamed=%d skipped=%d removed=%d\n",
+		verb, len(prepared), file, report.Mode, report.Created, report.Overwritten, report.Renamed, report.Skipped, report.Removed)
+	for _, item := range report.Items {
+		line := fmt.Sprintf("  %-9s %s %s", item.Action, item.WatchID, item.Name)
+		if item.OriginalID != "" {
+			line += " (was " + item.OriginalID + ")"
+		}
+		if item.HistoryReset {
+			line += " (fare changed: history reset)"
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func parseWatchExport(r io.Reader) ([]model.Watch, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var in watchExport
+	if err := dec.Decode(&in); err != nil {
+		return nil, fmt.Errorf("parse import file: %w", err)
+	}
+	if in.SchemaVersion > watcher.CurrentSchemaVersion {
+		return nil, fmt.Errorf("parse import file: %w (file is v%d, supported up to v%d)", watcher.ErrUnsupportedSchema, in.SchemaVersion, watcher.CurrentSchemaVersion)
+	}
+	if in.Watches == nil {
+		return nil, fmt.Errorf("parse import file: missing \"watches\" list")
+	}
+	// Older exports omitted rearm_percent when it was zero, so only a missing
+	// key means the default; an explicit 0 re-arms on any rise.
+	var keys struct {
+		Watches []map[string]json.RawMessage `json:"watches"`
+	}
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, fmt.Errorf("parse import file: %w", err)
+	}
+	for i := range in.Watches {
+		if _, ok := keys.Watches[i]["rearm_percent"]; !ok {
+			in.Watches[i].RearmPercent = defaultRearmPercent
+		}
+	}
+	return in.Watches, nil
+}
+
+// prepareImportedWatches validates each watch like watch create and fills
+// fields older exports may lack. A watch whose only problem is a departure
+// that has passed is left out and returned as a skip item; any other problem
+// fails the import, listing every problem of every watch.
+func prepareImportedWatches(watches []model.Watch, stripRuntime bool, cfg config.Config, now time.Time) ([]model.Watch, []watchImportItem, error) {
+	seen := map[string]bool{}
+	out := make([]model.Watch, 0, len(watches))
+	var (
+		stale    []watchImportItem
+		problems []FieldError
+	)
+	for i, w := range watches {
+		label := w.ID
+		if label == "" {
+			label = "#" + strconv.Itoa(i+1)
+		}
+		if w.ID == "" {
+			w.ID = fmt.Sprintf("w_%d", now.UnixNano()+int64(i))
+		}
+		if !validWatchID(w.ID) {
+			return nil, nil, newExitError(ExitInvalidUsage, "import watch %s: invalid id", label)
+		}
+		if seen[w.ID] {
+			return nil, nil, newExitError(ExitInvalidUsage, "import watch %s: duplicate id in file", label)
+		}
+		seen[w.ID] = true
+		if err := prepareWatch(&w, cfg); err != nil {
+			var verr ValidationError
+			if errors.As(err, &verr) && verr.onlyDepartPassed() {
+				stale = append(stale, watchImportItem{Action: importActionSkip, WatchID: w.ID, Name: w.Name, Reason: verr.summary()})
+				continue
+			}
+			if errors.As(err, &verr) {
+				problems = append(problems, verr.labelled("import watch "+label)...)
+				continue
+			}
+			return nil, nil, newExitError(ExitInvalidUsage, "import watch %s: %w", label, err)
+		}
+		if stripRuntime {
+			resetWatchRunState(&w)
+		}
+		if w.AlertState.Status == "" {
+			w.AlertState.Status = alertStateArmed
+		}
+		if w.CreatedAt.IsZero() {
+			w.CreatedAt = now
+		}
+		if w.UpdatedAt.IsZero() {
+			w.UpdatedAt = now
+		}
+		out = append(out, w)
+	}
+	if len(problems) > 0 {
+		return nil, nil, ExitError{Code: ExitInvalidUsage, Err: ValidationError{Problems: problems}}
+	}
+	return out, stale, nil
+}
+
+// importReplace swaps the stored watches for the imported ones. A stored
+// watch whose import was skipped as stale is kept rather than removed.
+func importReplace(existing, imported []model.Watch, skipped []watchImportItem) ([]model.Watch, watchImportReport) {
+	report := watchImportReport{Mode: "replace", Items: []watchImportItem{}}
+	stored := map[string]model.Watch{}
+	for _, w := range existing {
+		stored[w.ID] = w
+	}
+	out := make([]model.Watch, 0, len(imported))
+	kept := map[string]bool{}
+	for _, w := range imported {
+		kept[w.ID] = true
+		item := watchImportItem{Action: importActionCreate, WatchID: w.ID, Name: w.Name}
+		if old, ok := stored[w.ID]; ok {
+			item.HistoryReset = replaceFare(&w, old)
+		}
+		report.Created++
+		report.Items = append(report.Items, item)
+		out = append(out, w)
+	}
+	stale := map[string]bool{}
+	for _, item := range skipped {
+		stale[item.WatchID] = true
+	}
+	for _, w := range existing {
+		switch {
+		case kept[w.ID]:
+		case stale[w.ID]:
+			out = append(out, w)
+		default:
+			report.Removed++
+			report.Items = append(report.Items, watchImportItem{Action: importActionRemove, WatchID: w.ID, Name: w.Name})
+		}
+	}
+	return out, report
+}
+
+func importMerge(existing, imported []model.Watch, onConflict string, now time.Time) ([]model.Watch, watchImportReport, error) {
+	report := watchImportReport{Mode: "merge", Items: []watchImportItem{}}
+	out := append([]model.Watch(nil), existing...)
+	index := map[string]int{}
+	for i, w := range out {
+		index[w.ID] = i
+	}
+	collisions := []string{}
+	for _, w := range imported {
+		if _, ok := index[w.ID]; ok {
+			collisions = append(collisions, w.ID)
+		}
+	}
+	if len(collisions) > 0 && onConflict == "error" {
+		return nil, report, newExitError(ExitInvalidUsage, "import conflicts with existing watch id(s): %s (use --on-conflict skip|overwrite|rename or --replace)", strings.Join(collisions, ", "))
+	}
+	for i, w := range imported {
+		pos, exists := index[w.ID]
+		switch {
+		case !exists:
+			report.Created++
+			report.Items = append(report.Items, watchImportItem{Action: importActionCreate, WatchID: w.ID, Name: w.Name})
+			index[w.ID] = len(out)
+			out = append(out, w)
+		case onConflict == importActionSkip:
+			report.Skipped++
+			report.Items = append(report.Items, watchImportItem{Action: importActionSkip, WatchID: w.ID, Name: w.Name})
+		case onConflict == importActionOverwrite:
+			reset := replaceFare(&w, out[pos])
+			report.Overwritten++
+			report.Items = append(report.Items, watchImportItem{Action: importActionOverwrite, WatchID: w.ID, Name: w.Name, HistoryReset: reset})
+			out[pos] = w
+		case onConflict == importActionRename:
+			original := w.ID
+			w.ID = fmt.Sprintf("w_%d", now.UnixNano()+int64(i))
+			w.Key = ""
+			report.Renamed++
+			report.Items = append(report.Items, watchImportItem{Action: importActionRename, WatchID: w.ID, Name: w.Name, OriginalID: original})
+			index[w.ID] = len(out)
+			out = append(out, w)
+		}
+	}
+	return out, report, nil
+}
+
+// replaceFare resets the run state of w, which takes the place of the stored
+// watch old, when the two search different fares, since old's price history
+// no longer applies. It reports whether it did.
+func replaceFare(w *model.Watch, old model.Watch) bool {
+	if w.Query.SameFare(old.Query) {
+		return false
+	}
+	resetWatchRunState(w)
+	return true
+}
+
+func checkUniqueWatchKeys(watches []model.Watch) error {
+	owner := map[string]string{}
+	for _, w := range watches {
+		if w.Key == "" {
+			continue
+		}
+		if other, ok := owner[w.Key]; ok {
+			return newExitError(ExitInvalidUsage, "watches %s and %s would share manifest key %q", other, w.ID, w.Key)
+		}
+		owner[w.Key] = w.ID
+	}
+	return nil
+}
+
+func validWatchID(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`)
+}
+
+// parseWithPositional parses flags that may appear before or after a single
+// positional argument, which flag.FlagSet alone does not allow.
+func parseWithPositional(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if fs.NArg() == 0 {
+		return "", nil
+	}
+	positional := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return "", newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if fs.NArg() > 0 {
+		return "", newExitError(ExitInvalidUsage, "unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return positional, nil
+}
@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/watcher"
+)
+
+func TestWatchExportImportRoundTrip(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	src := t.TempDir()
+	dst := t.TempDir()
+	app := NewApp("test")
+	if err := app.Run([]string{"--state-dir", src, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--target-price", "700"}); err != nil {
+		t.Fatalf("create watch: %v", err)
+	}
+	store := watcher.Store{Path: filepath.Join(src, "watches.json")}
+	ws, _ := store.Load()
+	ws.Watches[0].LastLowestPrice = 720
+	ws.Watches[0].LastRunAt = time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	if err := store.Save(ws); err != nil {
+		t.Fatalf("save store: %v", err)
+	}
+	id := ws.Watches[0].ID
+
+	if err := app.Run([]string{"--state-dir", src, "watch", "export"}); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected selector to be required, got %v", err)
+	}
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--state-dir", src, "watch", "export", "--all"})
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "watches.json")
+	if err := os.WriteFile(file, []byte(out), 0o600); err != nil {
+		t.Fatalf("write export: %v", err)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", dst, "watch", "import", file, "--strip-runtime", "--dry-run"})
+	})
+	if err != nil {
+		t.Fatalf("dry-run import: %v", err)
+	}
+	if !strings.HasPrefix(out, "mode=merge\tdry_run=true\tcreated=1\t") {
+		t.Fatalf("unexpected plain output: %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "watches.json")); !os.IsNotExist(err) {
+		t.Fatalf("dry-run must not write the store")
+	}
+
+	if err := app.Run([]string{"--state-dir", dst, "watch", "import", file, "--strip-runtime"}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	got := onlyWatch(t, dst)
+	if got.ID != id || got.TargetPrice != 700 || got.LastLowestPrice != 0 || !got.LastRunAt.IsZero() {
+		t.Fatalf("expected imported watch without runtime fields, got %+v", got)
+	}
+
+	if err := app.Run([]string{"--state-dir", dst, "watch", "import", file}); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected id collision error, got %v", err)
+	}
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--json", "--state-dir", dst, "watch", "import", file, "--on-conflict", "rename"})
+	})
+	if err != nil {
+		t.Fatalf("import with rename: %v", err)
+	}
+	var report watchImportReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	if report.Renamed != 1 || report.Items[0].OriginalID != id || report.Items[0].WatchID == id {
+		t.Fatalf("unexpected rename report: %+v", report)
+	}
+
+	if err := app.Run([]string{"--state-dir", dst, "watch", "import", file, "--replace"}); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected --replace to require --force when removing watches, got %v", err)
+	}
+	if err := app.Run([]string{"--state-dir", dst, "watch", "import", file, "--replace", "--force"}); err != nil {
+		t.Fatalf("replace import: %v", err)
+	}
+	if got := onlyWatch(t, dst); got.ID != id || got.LastLowestPrice != 720 {
+		t.Fatalf("expected replaced store to match export, got %+v", got)
+	}
+}
+
+func TestWatchImportReplaceKeepsStaleWatches(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	past := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2020-06-10", Cabin: "economy", Adults: 1, Currency: "USD", SortBy: "price"}
+	future := past
+	future.Depart = "2030-06-10"
+	store := watcher.Store{Path: filepath.Join(dir, "watches.json")}
+	if err := store.Save(model.WatchStore{Watches: []model.Watch{
+		{ID: "w_past", Name: "past", Query: past, Enabled: true, LastLowestPrice: 720},
+		{ID: "w_future", Name: "future", Query: future, Enabled: true},
+	}}); err != nil {
+		t.Fatalf("save store: %v", err)
+	}
+	app := NewApp("test")
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--state-dir", dir, "watch", "export", "--all"})
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "watches.json")
+	if err := os.WriteFile(file, []byte(out), 0o600); err != nil {
+		t.Fatalf("write export: %v", err)
+	}
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--json", "--state-dir", dir, "watch", "import", file, "--replace", "--force"})
+	})
+	if err != nil {
+		t.Fatalf("replace import: %v", err)
+	}
+	var report watchImportReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	if report.Created != 1 || report.Skipped != 1 || report.Removed != 0 {
+		t.Fatalf("unexpected replace report: %+v", report)
+	}
+	ws, err := store.Load()
+	if err != nil {
+		t.Fatalf("load store: %v", err)
+	}
+	if len(ws.Watches) != 2 {
+		t.Fatalf("expected the stale watch kept, got %+v", ws.Watches)
+	}
+	for _, w := range ws.Watches {
+		if w.ID == "w_past" && w.LastLowestPrice != 720 {
+			t.Fatalf("expected the stale watch kept as it was, got %+v", w)
+		}
+	}
+}
+
+func TestWatchImportOverwriteResetsChangedFare(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	athens := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2030-06-10", Cabin: "economy", Adults: 1, Currency: "USD", SortBy: "price"}
+	store := watcher.Store{Path: filepath.Join(dir, "watches.json")}
+	if err := store.Save(model.WatchStore{Watches: []model.Watch{{ID: "w_1", Name: "athens", Query: athens, Enabled: true, LastLowestPrice: 720}}}); err != nil {
+		t.Fatalf("save store: %v", err)
+	}
+	h := watcher.HistoryStore{Dir: filepath.Join(dir, "history")}
+	if err := h.Append("w_1", model.PriceHistoryEntry{CheckedAt: time.Now().UTC(), LowestPrice: 720, Currency: "USD"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	app := NewApp("test")
+	importWatch := func(q model.SearchQuery, args ...string) watchImportReport {
+		t.Helper()
+		b, err := json.Marshal(watchExport{Watches: []model.Watch{{ID: "w_1", Name: "athens", Query: q, Enabled: true, LastLowestPrice: 650}}})
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		file := filepath.Join(t.TempDir(), "watches.json")
+		if err := os.WriteFile(file, b, 0o600); err != nil {
+			t.Fatalf("write import: %v", err)
+		}
+		out, err := captureStdoutForRun(t, func() error {
+			return app.Run(append([]string{"--json", "--state-dir", dir, "watch", "import", file}, args...))
+		})
+		if err != nil {
+			t.Fatalf("import %v: %v", args, err)
+		}
+		var report watchImportReport
+		if err := json.Unmarshal([]byte(out), &report); err != nil {
+			t.Fatalf("decode json: %v", err)
+		}
+		return report
+	}
+	historyLen := func() int {
+		t.Helper()
+		entries, err := h.Load("w_1")
+		if err != nil {
+			t.Fatalf("load history: %v", err)
+		}
+		return len(entries)
+	}
+
+	sorted := athens
+	sorted.SortBy = "duration"
+	if report := importWatch(sorted, "--on-conflict", "overwrite"); report.Items[0].HistoryReset || historyLen() != 1 || onlyWatch(t, dir).LastLowestPrice != 650 {
+		t.Fatalf("expected an overwrite with the same fare to keep history, got %+v", report)
+	}
+	rome := athens
+	rome.To = "FCO"
+	if report := importWatch(rome, "--on-conflict", "overwrite", "--dry-run"); !report.Items[0].HistoryReset || historyLen() != 1 {
+		t.Fatalf("expected a dry run to flag the reset without deleting, got %+v", report)
+	}
+	if report := importWatch(rome, "--on-conflict", "overwrite"); !report.Items[0].HistoryReset || historyLen() != 0 || onlyWatch(t, dir).LastLowestPrice != 0 {
+		t.Fatalf("expected an overwrite with a new fare to reset history, got %+v", report)
+	}
+
+	if err := h.Append("w_1", model.PriceHistoryEntry{CheckedAt: time.Now().UTC(), LowestPrice: 600, Currency: "USD"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if report := importWatch(athens, "--replace"); !report.Items[0].HistoryReset || historyLen() != 0 || onlyWatch(t, dir).LastLowestPrice != 0 {
+		t.Fatalf("expected a replace with a new fare to reset history, got %+v", report)
+	}
+}
+
+func TestPrepareImportedWatchesValidates(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	valid := model.Watch{ID: "w_1", Query: model.SearchQuery{From: "SFO", To: "ATH", Depart: "2030-06-10", Adults: 1}}
+	cases := map[string][]model.Watch{
+		"path id":      {{ID: "../w", Query: valid.Query}},
+		"duplicate id": {valid, valid},
+		"bad schedule": {{ID: "w_2", Query: valid.Query, Schedule: "every day"}},
+		"bad rule":     {{ID: "w_3", Query: valid.Query, Rules: []model.AlertRule{{Type: "percent_drop"}}}},
+	}
+	for name, watches := range cases {
+		if _, _, err := prepareImportedWatches(watches, false, config.Config{}, now); ExitCode(err) != ExitInvalidUsage {
+			t.Fatalf("%s: expected invalid usage, got %v", name, err)
+		}
+	}
+	// A departure that has passed skips the watch; any other query problem
+	// fails the import and lists every problem.
+	past := valid.Query
+	past.Depart = "2020-06-10"
+	out, stale, err := prepareImportedWatches([]model.Watch{valid, {ID: "w_5", Query: past}}, false, config.Config{}, now)
+	if err != nil || len(out) != 1 || out[0].ID != "w_1" || len(stale) != 1 {
+		t.Fatalf("expected stale watch skipped, got out=%+v stale=%+v err=%v", out, stale, err)
+	}
+	if stale[0].Action != importActionSkip || stale[0].WatchID != "w_5" || !strings.Contains(stale[0].Reason, "--depart: 2020-06-10 is in the past") {
+		t.Fatalf("expected skip item with reason, got %+v", stale[0])
+	}
+	malformed := valid.Query
+	malformed.Depart, malformed.Cabin = "2027-13-01", "coach"
+	_, _, err = prepareImportedWatches([]model.Watch{
+		{ID: "w_4", Query: model.SearchQuery{From: "SFO", To: "ATH", Depart: "2030-06-10"}},
+		{ID: "w_5", Query: past},
+		{ID: "w_6", Query: malformed},
+	}, false, config.Config{}, now)
+	var verr ValidationError
+	if ExitCode(err) != ExitInvalidUsage || !errors.As(err, &verr) || len(verr.Problems) != 3 {
+		t.Fatalf("expected every problem of the malformed watches, got %v", err)
+	}
+	lower := valid.Query
+	lower.From, lower.To, lower.Currency = "sfo, oak", "ath", "eur"
+	out, _, err = prepareImportedWatches([]model.Watch{{Query: lower}}, false, config.Config{}, now)
+	if err != nil || out[0].ID == "" || out[0].AlertState.Status != alertStateArmed || !out[0].CreatedAt.Equal(now) {
+		t.Fatalf("expected defaults filled, got %+v err=%v", out, err)
+	}
+	q := out[0].Query
+	if q.From != "SFO,OAK" || q.To != "ATH" || q.Currency != "EUR" || q.Cabin != model.CabinEconomy || q.SortBy != model.SortPrice {
+		t.Fatalf("expected normalized query and defaults, got %+v", q)
+	}
+}
+
+func TestParseWatchExportDefaultsOnlyMissingRearmPercent(t *testing.T) {
+	watches, err := parseWatchExport(strings.NewReader(`{"watches":[
+		{"id":"w_1","query":{"from":"SFO","to":"ATH","depart":"2030-06-10"}},
+		{"id":"w_2","query":{"from":"SFO","to":"ATH","depart":"2030-06-10"},"rearm_percent":0},
+		{"id":"w_3","query":{"from":"SFO","to":"ATH","depart":"2030-06-10"},"rearm_percent":12.5}
+	]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for i, want := range []float64{defaultRearmPercent, 0, 12.5} {
+		if got := watches[i].RearmPercent; got != want {
+			t.Fatalf("watch %s: expected rearm_percent %v, got %v", watches[i].ID, want, got)
+		}
+	}
+	b, err := json.Marshal(watches[1])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(b), `"rearm_percent":0`) {
+		t.Fatalf("expected an explicit zero rearm_percent to be written, got %s", b)
+	}
+}
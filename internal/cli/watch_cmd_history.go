@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/watcher"
+)
+
+type watchHistoryOutput struct {
+	WatchID   string                    `json:"watch_id"`
+	WatchName string                    `json:"watch_name"`
+	Entries   []model.PriceHistoryEntry `json:"entries"`
+}
+
+func (a App) cmdWatchHistory(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("watch history", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	id := fs.String("id", "", "Watch ID")
+	since := fs.String("since", "", "Only entries at or after this time (YYYY-MM-DD, RFC3339, or age like 7d/12h)")
+	limit := fs.Int("limit", 0, "Only the most recent N entries (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if *id == "" {
+		return newExitError(ExitInvalidUsage, "--id is required")
+	}
+	if *limit < 0 {
+		return newExitError(ExitInvalidUsage, "--limit must be >= 0")
+	}
+	var cutoff time.Time
+	if *since != "" {
+		parsed, err := parseSince(*since, time.Now().UTC())
+		if err != nil {
+			return newExitError(ExitInvalidUsage, "%v", err)
+		}
+		cutoff = parsed
+	}
+	st, err := a.watchStorage(g.StateDir)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	ws, err := st.Watches.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	var watch *model.Watch
+	for i := range ws.Watches {
+		if ws.Watches[i].ID == *id {
+			watch = &ws.Watches[i]
+			break
+		}
+	}
+	if watch == nil {
+		return newExitError(ExitGenericFailure, "watch not found: %s", *id)
+	}
+	entries, err := st.History.Load(watch.ID)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	entries = filterRecent(entries, func(e model.PriceHistoryEntry) time.Time { return e.CheckedAt }, cutoff, *limit)
+
+	if g.JSON {
+		return writeJSON(watchHistoryOutput{WatchID: watch.ID, WatchName: watch.Name, Entries: entries})
+	}
+	if g.Plain {
+		writePlainTableHeader("checked_at", "lowest_price", "currency", "flight_count", "airline", "flight_number", "depart_time", "arrive_time", "stops", "depart", "return", "route")
+		for _, e := range entries {
+			top := model.Flight{}
+			if e.TopItinerary != nil {
+				top = *e.TopItinerary
+			}
+			writePlainTableRow(
+				e.CheckedAt.Format(time.RFC3339),
+				strconv.Itoa(e.LowestPrice),
+				e.Currency,
+				strconv.Itoa(e.FlightCount),
+				top.Airline,
+				top.FlightNumber,
+				top.DepartTime,
+				top.ArriveTime,
+				strconv.Itoa(top.Stops),
+				e.Depart,
+				e.Return,
+				e.Route,
+			)
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No price history for %s (%s)\n", watch.Name, watch.ID)
+		return nil
+	}
+	fmt.Printf("Price history for %s (%s): %d entries\n", watch.Name, watch.ID, len(entries))
+	for _, e := range entries {
+		line := fmt.Sprintf("%s  %6d %s  flights=%d", e.CheckedAt.UTC().Format("2006-01-02 15:04"), e.LowestPrice, e.Currency, e.FlightCount)
+		if e.Route != "" {
+			line += "  route " + e.Route
+		}
+		if e.Depart != "" {
+			line += "  dates " + formatTripDates(e.Depart, e.Return)
+		}
+		if e.TopItinerary != nil {
+			line += fmt.Sprintf("  %s | stops:%d | %s -> %s", e.TopItinerary.Airline, e.TopItinerary.Stops, e.TopItinerary.DepartTime, e.TopItinerary.ArriveTime)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// retainedHistory appends run observations, prunes entries older than the
+// configured retention window, and keeps each watch's latest snapshot.
+type retainedHistory struct {
+	store         watcher.HistoryBackend
+	snapshots     watcher.SnapshotBackend
+	retentionDays int
+}
+
+func (h retainedHistory) Load(watchID string) ([]model.PriceHistoryEntry, error) {
+	return h.store.Load(watchID)
+}
+
+func (h retainedHistory) Record(w model.Watch, entry model.PriceHistoryEntry) error {
+	if err := h.store.Append(w.ID, entry); err != nil {
+		return err
+	}
+	if h.retentionDays <= 0 {
+		return nil
+	}
+	cutoff := entry.CheckedAt.AddDate(0, 0, -h.retentionDays)
+	oldest, err := h.store.Oldest(w.ID)
+	if err != nil || !oldest.Before(cutoff) {
+		return err
+	}
+	_, err = h.store.Prune(w.ID, cutoff)
+	return err
+}
+
+func (h retainedHistory) Snapshot(snap model.WatchSnapshot) error {
+	return h.snapshots.Save(snap)
+}
+
+// filterRecent keeps the items recorded at or after since, then the last
+// limit of them; a zero since or limit disables that filter. Items must be in
+// the order they were recorded.
+func filterRecent[T any](items []T, recordedAt func(T) time.Time, since time.Time, limit int) []T {
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		if !since.IsZero() && recordedAt(item).Before(since) {
+			continue
+		}
+		out = append(out, item)
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+func parseSince(v string, now time.Time) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q (use YYYY-MM-DD, RFC3339, or an age like 7d or 12h)", v)
+}
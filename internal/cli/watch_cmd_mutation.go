@@ -4,70 +4,200 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/agisilaos/gflight/internal/config"
 	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/watcher"
 )
 
+type watchFlags struct {
+	name           *string
+	tags           stringsFlag
+	target         *int
+	rules          ruleFlags
+	cooldown       *string
+	rearmPercent   *float64
+	notifyTerminal *bool
+	notifyEmail    *bool
+	notifyWebhook  *bool
+	emailTo        *string
+	webhookURL     *string
+	checkInterval  *string
+	schedule       *string
+	maxRequests    *int
+	dryRun         *bool
+}
+
+func newWatchFlagSet(name string) (*flag.FlagSet, *model.SearchQuery, *watchFlags) {
+	fs, q := newSearchFlagSet(name)
+	wf := &watchFlags{}
+	wf.name = fs.String("name", "", "Watch name")
+	fs.Var(&wf.tags, "tag", "Tag for selecting the watch, e.g. team:growth (repeatable)")
+	wf.target = fs.Int("target-price", 0, "Alert when price <= target")
+	fs.Var(&wf.rules, "rule", "Alert rule (repeatable): percent_drop=10%/7d, all_time_low, below_average=5%/10runs, absolute_drop=50, good_deal")
+	wf.cooldown = fs.String("cooldown", "", "Minimum time between alerts for this watch (e.g. 6h)")
+	wf.rearmPercent = fs.Float64("rearm-percent", defaultRearmPercent, "Re-arm after the price rebounds this many percent above the fired price")
+	wf.notifyTerminal = fs.Bool("notify-terminal", true, "Send terminal notifications")
+	wf.notifyEmail = fs.Bool("notify-email", false, "Send email notifications")
+	wf.notifyWebhook = fs.Bool("notify-webhook", false, "Send webhook notifications")
+	wf.emailTo = fs.String("email-to", "", "Email recipient")
+	wf.webhookURL = fs.String("webhook-url", "", "Webhook URL override")
+	wf.checkInterval = fs.String("check-interval", "", "Daemon check interval (e.g. 30m); defaults to config check_interval")
+	wf.schedule = fs.String("schedule", "", "Cron schedule (e.g. \"0 */4 * * *\" or @hourly); overrides --check-interval")
+	wf.maxRequests = fs.Int("max-requests", defaultWatchRequests, "Most provider requests one run of the watch may use across its routes and dates")
+	wf.dryRun = fs.Bool("dry-run", false, "Preview watch without saving")
+	return fs, q, wf
+}
+
+// validateWatch checks the user-editable parts of a watch, whichever command
+// produced it. cfg selects the provider whose lookups count against the
+// request budget.
+func validateWatch(w model.Watch, cfg config.Config) error {
+	if err := validateQuery(w.Query); err != nil {
+		return err
+	}
+	return validateWatchSettings(w, cfg)
+}
+
+// validateWatchSettings checks everything validateWatch does except the
+// query's own fields, for callers that validate those separately.
+func validateWatchSettings(w model.Watch, cfg config.Config) error {
+	if w.Query.Booking && w.Query.Flexible() {
+		return newExitError(ExitInvalidUsage, "--booking cannot be combined with --depart-range or --trip-length")
+	}
+	limit := defaultWatchRequests
+	if w.MaxRequests != 0 {
+		if w.MaxRequests < 1 || w.MaxRequests > maxCalendarRequests {
+			return newExitError(ExitInvalidUsage, "--max-requests must be from 1 to %d", maxCalendarRequests)
+		}
+		limit = w.MaxRequests
+	}
+	searches := len(routeQueries(w.Query))
+	if w.Query.Flexible() {
+		g, _ := queryGrid(w.Query)
+		searches *= g.size()
+	}
+	if n := searches * requestsPerSearch(configuredProvider(cfg, 0), w.Query); n > limit {
+		return newExitError(ExitInvalidUsage, "watch covers %d route and date combinations needing up to %d provider requests with return and booking lookups, over --max-requests %d (list fewer airports, narrow --depart-range/--trip-length, drop --booking or raise --max-requests)", searches, n, limit)
+	}
+	for _, tag := range w.Tags {
+		if err := validateTag(tag); err != nil {
+			return newExitError(ExitInvalidUsage, "--tag %v", err)
+		}
+	}
+	if w.CheckInterval != "" {
+		if _, err := parseCheckInterval(w.CheckInterval); err != nil {
+			return newExitError(ExitInvalidUsage, "--check-interval %v", err)
+		}
+	}
+	if w.Cooldown != "" {
+		if _, err := parseCooldown(w.Cooldown); err != nil {
+			return newExitError(ExitInvalidUsage, "--cooldown %v", err)
+		}
+	}
+	if w.RearmPercent < 0 {
+		return newExitError(ExitInvalidUsage, "--rearm-percent must be >= 0")
+	}
+	if w.TargetPrice < 0 {
+		return newExitError(ExitInvalidUsage, "--target-price must be >= 0")
+	}
+	if w.Schedule != "" {
+		if w.CheckInterval != "" {
+			return newExitError(ExitInvalidUsage, "--schedule and --check-interval are mutually exclusive")
+		}
+		if err := validateSchedule(w.Schedule); err != nil {
+			return newExitError(ExitInvalidUsage, "%v", err)
+		}
+	}
+	for _, r := range w.Rules {
+		if _, err := parseAlertRule(formatAlertRule(r)); err != nil {
+			return newExitError(ExitInvalidUsage, "%v", err)
+		}
+	}
+	return nil
+}
+
+// watchMaxRequests stores --max-requests only when it was passed, so watches
+// on the default budget follow it if it changes.
+func watchMaxRequests(fs *flag.FlagSet, n int) (int, error) {
+	if !flagWasSet(fs, "max-requests") {
+		return 0, nil
+	}
+	if n < 1 || n > maxCalendarRequests {
+		return 0, newExitError(ExitInvalidUsage, "--max-requests must be from 1 to %d", maxCalendarRequests)
+	}
+	return n, nil
+}
+
 func (a App) cmdWatchCreate(g globalFlags, args []string) error {
-	fs, q := newSearchFlagSet("watch create")
-	name := fs.String("name", "", "Watch name")
-	target := fs.Int("target-price", 0, "Alert when price <= target")
-	notifyTerminal := fs.Bool("notify-terminal", true, "Send terminal notifications")
-	notifyEmail := fs.Bool("notify-email", false, "Send email notifications")
-	notifyWebhook := fs.Bool("notify-webhook", false, "Send webhook notifications")
-	emailTo := fs.String("email-to", "", "Email recipient")
-	webhookURL := fs.String("webhook-url", "", "Webhook URL override")
-	dryRun := fs.Bool("dry-run", false, "Preview watch without saving")
+	fs, q, wf := newWatchFlagSet("watch create")
 	if err := fs.Parse(args); err != nil {
 		return newExitError(ExitInvalidUsage, "%v", err)
 	}
-	if err := validateQuery(*q); err != nil {
+	if err := checkDateFlags(fs); err != nil {
 		return err
 	}
-	if *name == "" {
-		*name = fmt.Sprintf("%s-%s-%s", q.From, q.To, q.Depart)
+	name := *wf.name
+	if name == "" {
+		name = fmt.Sprintf("%s-%s-%s", q.From, q.To, q.Depart)
 	}
 	cfg, err := config.Load()
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
-	if *emailTo == "" {
-		*emailTo = cfg.DefaultNotifyEmail
-	}
-	if *webhookURL == "" {
-		*webhookURL = cfg.WebhookURL
-	}
+	emailTo := firstOr(*wf.emailTo, cfg.DefaultNotifyEmail)
+	webhookURL := firstOr(*wf.webhookURL, cfg.WebhookURL)
 	w := model.Watch{
 		ID:             fmt.Sprintf("w_%d", time.Now().UnixNano()),
-		Name:           *name,
+		Name:           name,
+		Tags:           normalizeTags(wf.tags),
 		Query:          *q,
 		Enabled:        true,
-		TargetPrice:    *target,
-		NotifyTerminal: *notifyTerminal,
-		NotifyEmail:    *notifyEmail,
-		NotifyWebhook:  *notifyWebhook,
-		EmailTo:        *emailTo,
-		WebhookURL:     *webhookURL,
+		TargetPrice:    *wf.target,
+		Rules:          wf.rules,
+		Cooldown:       *wf.cooldown,
+		RearmPercent:   *wf.rearmPercent,
+		AlertState:     model.AlertState{Status: alertStateArmed},
+		NotifyTerminal: *wf.notifyTerminal,
+		NotifyEmail:    *wf.notifyEmail,
+		NotifyWebhook:  *wf.notifyWebhook,
+		EmailTo:        emailTo,
+		WebhookURL:     webhookURL,
+		CheckInterval:  *wf.checkInterval,
+		Schedule:       *wf.schedule,
 		CreatedAt:      time.Now().UTC(),
 		UpdatedAt:      time.Now().UTC(),
 	}
-	if *dryRun {
+	if w.MaxRequests, err = watchMaxRequests(fs, *wf.maxRequests); err != nil {
+		return err
+	}
+	if err := validateWatch(w, cfg); err != nil {
+		return err
+	}
+	warnUnknownAirports(w.Query)
+	if *wf.dryRun {
 		return writeMaybeJSON(g, w)
 	}
-	store, err := a.watcherStore(g.StateDir)
+	st, err := openWatchStorage(g.StateDir, cfg)
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
-	ws, err := store.Load()
+	unlock, err := lockStorage(st)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	ws, err := st.Watches.Load()
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
 	ws.Watches = append(ws.Watches, w)
-	if err := store.Save(ws); err != nil {
+	if err := st.Watches.Save(ws); err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
 	if g.Plain && !g.JSON {
@@ -77,37 +207,81 @@ func (a App) cmdWatchCreate(g globalFlags, args []string) error {
 	return writeMaybeJSON(g, w)
 }
 
+type watchListItem struct {
+	model.Watch
+	NextDueAt *time.Time `json:"next_due_at,omitempty"`
+}
+
 func (a App) cmdWatchList(g globalFlags, args []string) error {
 	fs := flag.NewFlagSet("watch list", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
+	var sel watchSelector
+	addWatchSelectorFlags(fs, &sel)
 	if err := fs.Parse(args); err != nil {
 		return newExitError(ExitInvalidUsage, "%v", err)
 	}
-	store, err := a.watcherStore(g.StateDir)
+	if err := sel.validate(); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
-	ws, err := store.Load()
+	st, err := openWatchStorage(g.StateDir, cfg)
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
+	ws, err := st.Watches.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	defaultInterval, err := parseCheckInterval(cfg.CheckInterval)
+	if err != nil {
+		return newExitError(ExitInvalidUsage, "config check_interval %v", err)
+	}
 	sort.Slice(ws.Watches, func(i, j int) bool {
 		if ws.Watches[i].CreatedAt.Equal(ws.Watches[j].CreatedAt) {
 			return ws.Watches[i].ID < ws.Watches[j].ID
 		}
 		return ws.Watches[i].CreatedAt.After(ws.Watches[j].CreatedAt)
 	})
+	now := time.Now().UTC()
+	items := make([]watchListItem, 0, len(ws.Watches))
+	for _, w := range ws.Watches {
+		if !sel.empty() && !sel.matches(w) {
+			continue
+		}
+		item := watchListItem{Watch: w}
+		if w.Enabled {
+			if next, ok := watchNextDue(w, w.LastRunAt, defaultInterval); ok {
+				if next.IsZero() {
+					next = now
+				}
+				item.NextDueAt = &next
+			}
+		}
+		items = append(items, item)
+	}
 	if g.JSON {
-		return writeJSON(ws.Watches)
+		return writeJSON(items)
 	}
-	if len(ws.Watches) == 0 {
-		fmt.Println("No watches configured")
+	if len(items) == 0 {
+		if sel.empty() {
+			fmt.Println("No watches configured")
+		} else {
+			fmt.Printf("No watches match %s\n", sel.describe())
+		}
 		return nil
 	}
 	if g.Plain {
-		writePlainTableHeader("id", "name", "enabled", "target_price", "from", "to", "depart")
+		writePlainTableHeader("id", "name", "enabled", "target_price", "from", "to", "depart", "schedule", "next_due_at", "tags")
 	}
-	for _, w := range ws.Watches {
+	for _, item := range items {
+		w := item.Watch
+		nextDue := ""
+		if item.NextDueAt != nil {
+			nextDue = item.NextDueAt.Format(time.RFC3339)
+		}
 		if g.Plain {
 			writePlainTableRow(
 				w.ID,
@@ -117,95 +291,162 @@ func (a App) cmdWatchList(g globalFlags, args []string) error {
 				w.Query.From,
 				w.Query.To,
 				w.Query.Depart,
+				watchScheduleLabel(w, cfg.CheckInterval),
+				nextDue,
+				strings.Join(w.Tags, ","),
 			)
 			continue
 		}
-		fmt.Printf("%s\t%s\t%s->%s\t%s\ttarget=%d\tenabled=%t\n", w.ID, w.Name, w.Query.From, w.Query.To, w.Query.Depart, w.TargetPrice, w.Enabled)
+		line := fmt.Sprintf("%s\t%s\t%s->%s\t%s\ttarget=%d\tenabled=%t\tschedule=%s\tnext=%s", w.ID, w.Name, w.Query.From, w.Query.To, formatDateWindow(w.Query), w.TargetPrice, w.Enabled, watchScheduleLabel(w, cfg.CheckInterval), firstOr(nextDue, "-"))
+		if len(w.Tags) > 0 {
+			line += "\ttags=" + strings.Join(w.Tags, ",")
+		}
+		fmt.Println(line)
 	}
 	return nil
 }
 
+// selectWatches returns the indexes of watches matching sel. A plain --id
+// that matches nothing is reported as not found, like before selectors existed.
+func selectWatches(watches []model.Watch, sel watchSelector) ([]int, error) {
+	idx := []int{}
+	for i, w := range watches {
+		if sel.matches(w) {
+			idx = append(idx, i)
+		}
+	}
+	if len(idx) > 0 {
+		return idx, nil
+	}
+	if sel.ID != "" && !sel.filtered() {
+		return nil, newExitError(ExitGenericFailure, "watch not found: %s", sel.ID)
+	}
+	return nil, newExitError(ExitNoMatches, "no watches match %s", sel.describe())
+}
+
 func (a App) cmdWatchSetEnabled(g globalFlags, args []string, enabled bool) error {
 	fs := flag.NewFlagSet("watch set-enabled", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
-	id := fs.String("id", "", "Watch ID")
+	var sel watchSelector
+	addWatchSelectorFlags(fs, &sel)
 	if err := fs.Parse(args); err != nil {
 		return newExitError(ExitInvalidUsage, "%v", err)
 	}
-	if *id == "" {
-		return newExitError(ExitInvalidUsage, "--id is required")
+	if sel.empty() {
+		return newExitError(ExitInvalidUsage, "--id or a selector (--tag, --name-glob, --route) is required")
 	}
-	store, err := a.watcherStore(g.StateDir)
+	if err := sel.validate(); err != nil {
+		return err
+	}
+	st, unlock, err := a.lockedStorage(g.StateDir)
 	if err != nil {
-		return wrapExitError(ExitGenericFailure, err)
+		return err
 	}
-	ws, err := store.Load()
+	defer unlock()
+	ws, err := st.Watches.Load()
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
-	for i := range ws.Watches {
-		if ws.Watches[i].ID != *id {
-			continue
-		}
+	idx, err := selectWatches(ws.Watches, sel)
+	if err != nil {
+		return err
+	}
+	changed := make([]model.Watch, 0, len(idx))
+	for _, i := range idx {
 		ws.Watches[i].Enabled = enabled
 		ws.Watches[i].UpdatedAt = time.Now().UTC()
-		if err := store.Save(ws); err != nil {
-			return wrapExitError(ExitGenericFailure, err)
-		}
-		if g.Plain && !g.JSON {
-			writePlainKV("watch_id", ws.Watches[i].ID, "enabled", strconv.FormatBool(ws.Watches[i].Enabled))
-			return nil
+		changed = append(changed, ws.Watches[i])
+	}
+	if err := st.Watches.Save(ws); err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	if g.Plain && !g.JSON {
+		for _, w := range changed {
+			writePlainKV("watch_id", w.ID, "enabled", strconv.FormatBool(w.Enabled))
 		}
-		return writeMaybeJSON(g, ws.Watches[i])
+		return nil
+	}
+	if !sel.filtered() {
+		return writeMaybeJSON(g, changed[0])
 	}
-	return newExitError(ExitGenericFailure, "watch not found: %s", *id)
+	return writeMaybeJSON(g, changed)
 }
 
 func (a App) cmdWatchDelete(g globalFlags, args []string) error {
 	fs := flag.NewFlagSet("watch delete", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
-	id := fs.String("id", "", "Watch ID")
+	var sel watchSelector
+	addWatchSelectorFlags(fs, &sel)
 	force := fs.Bool("force", false, "Delete without confirmation")
 	confirm := fs.String("confirm", "", "Confirmation token (watch ID)")
 	if err := fs.Parse(args); err != nil {
 		return newExitError(ExitInvalidUsage, "%v", err)
 	}
-	if *id == "" {
-		return newExitError(ExitInvalidUsage, "--id is required")
+	if sel.empty() {
+		return newExitError(ExitInvalidUsage, "--id or a selector (--tag, --name-glob, --route) is required")
+	}
+	if err := sel.validate(); err != nil {
+		return err
+	}
+	if sel.filtered() && !*force {
+		return newExitError(ExitInvalidUsage, "destructive action: deleting by selector requires --force")
 	}
-	if !*force && *confirm != *id {
+	if !*force && *confirm != sel.ID {
 		return newExitError(ExitInvalidUsage, "destructive action: pass --force or --confirm with the watch ID")
 	}
 	if g.NoInput && !*force {
 		return newExitError(ExitInvalidUsage, "--no-input requires --force for watch delete")
 	}
-	store, err := a.watcherStore(g.StateDir)
+	st, unlock, err := a.lockedStorage(g.StateDir)
 	if err != nil {
-		return wrapExitError(ExitGenericFailure, err)
+		return err
 	}
-	ws, err := store.Load()
+	defer unlock()
+	ws, err := st.Watches.Load()
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
+	idx, err := selectWatches(ws.Watches, sel)
+	if err != nil {
+		return err
+	}
+	deleted := make([]string, 0, len(idx))
 	filtered := make([]model.Watch, 0, len(ws.Watches))
-	found := false
-	for _, w := range ws.Watches {
-		if w.ID == *id {
-			found = true
+	for i, w := range ws.Watches {
+		if slices.Contains(idx, i) {
+			deleted = append(deleted, w.ID)
 			continue
 		}
 		filtered = append(filtered, w)
 	}
-	if !found {
-		return newExitError(ExitGenericFailure, "watch not found: %s", *id)
-	}
 	ws.Watches = filtered
-	if err := store.Save(ws); err != nil {
+	if err := st.Watches.Save(ws); err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
+	if err := deleteWatchArtifacts(st, deleted); err != nil {
+		return err
+	}
 	if g.Plain && !g.JSON {
-		writePlainKV("deleted_id", *id)
+		for _, id := range deleted {
+			writePlainKV("deleted_id", id)
+		}
 		return nil
 	}
-	return writeMaybeJSON(g, map[string]any{"deleted": *id})
+	if !sel.filtered() {
+		return writeMaybeJSON(g, map[string]any{"deleted": deleted[0]})
+	}
+	return writeMaybeJSON(g, map[string]any{"deleted": deleted})
+}
+
+// deleteWatchArtifacts removes the history and snapshot files of deleted watches.
+func deleteWatchArtifacts(st watcher.Storage, ids []string) error {
+	for _, id := range ids {
+		if err := st.History.Delete(id); err != nil {
+			return wrapExitError(ExitGenericFailure, err)
+		}
+		if err := st.Snapshots.Delete(id); err != nil {
+			return wrapExitError(ExitGenericFailure, err)
+		}
+	}
+	return nil
 }
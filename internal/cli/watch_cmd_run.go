@@ -3,6 +3,7 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strconv"
@@ -12,31 +13,52 @@ import (
 	"github.com/agisilaos/gflight/internal/config"
 	"github.com/agisilaos/gflight/internal/model"
 	"github.com/agisilaos/gflight/internal/notify"
+	"github.com/agisilaos/gflight/internal/watcher"
 )
 
 func (a App) cmdWatchRun(g globalFlags, args []string) error {
 	fs := flag.NewFlagSet("watch run", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
-	watchID := fs.String("id", "", "Watch ID")
-	runAll := fs.Bool("all", false, "Run all watches")
+	var sel watchSelector
+	addWatchSelectorFlags(fs, &sel)
+	fs.BoolVar(&sel.All, "all", false, "Run all watches")
 	failOnProviderErrors := fs.Bool("fail-on-provider-errors", false, "Exit non-zero when any provider failure occurs")
 	once := fs.Bool("once", true, "Single pass")
+	daemon := fs.Bool("daemon", false, "Keep running and evaluate each watch on its check interval")
+	dueOnly := fs.Bool("due", false, "Only run watches whose schedule or check interval has come due")
+	concurrency := fs.Int("concurrency", 0, "Parallel provider searches (default config watch_concurrency)")
 	if err := fs.Parse(args); err != nil {
 		return newExitError(ExitInvalidUsage, "%v", err)
 	}
-	_ = once
-	if (*watchID == "" && !*runAll) || (*watchID != "" && *runAll) {
-		return newExitError(ExitInvalidUsage, "watch run requires exactly one of --all or --id")
+	if flagWasSet(fs, "concurrency") && (*concurrency < 1 || *concurrency > config.MaxWatchConcurrency) {
+		return newExitError(ExitInvalidUsage, "--concurrency must be from 1 to %d", config.MaxWatchConcurrency)
 	}
-	store, err := a.watcherStore(g.StateDir)
+	if *dueOnly && sel.empty() {
+		sel.All = true
+	}
+	if sel.empty() {
+		return newExitError(ExitInvalidUsage, "watch run requires exactly one of --all or --id, or a selector (--tag, --name-glob, --route)")
+	}
+	if err := sel.validate(); err != nil {
+		return err
+	}
+	if *daemon && *once && flagWasSet(fs, "once") {
+		return newExitError(ExitInvalidUsage, "--once and --daemon are mutually exclusive")
+	}
+	daemonMode := *daemon || !*once
+	deadline, err := parseDeadline(g)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
-	ws, err := store.Load()
+	st, err := openWatchStorage(g.StateDir, cfg)
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
-	cfg, err := config.Load()
+	ws, err := st.Watches.Load()
 	if err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
@@ -47,61 +69,80 @@ func (a App) cmdWatchRun(g globalFlags, args []string) error {
 	if err != nil {
 		return err
 	}
+	if !flagWasSet(fs, "concurrency") {
+		*concurrency = cfg.WatchConcurrency
+	}
+	workers := watchConcurrency(*concurrency, p)
+	if g.Verbose {
+		fmt.Fprintf(os.Stderr, "watch run: %d concurrent search(es)\n", workers)
+	}
+	recorder := retainedHistory{store: st.History, snapshots: st.Snapshots, retentionDays: cfg.HistoryRetention}
 	n := newDefaultNotifyDispatcher(notify.Notifier{Config: cfg})
-	report, notifyErrs := runWatchPass(
+	notifyFn := func(w model.Watch, alert model.Alert) error { return a.sendWatchNotifications(n, w, alert) }
+	defaultInterval, err := parseCheckInterval(cfg.CheckInterval)
+	if err != nil {
+		return newExitError(ExitInvalidUsage, "config check_interval %v", err)
+	}
+	if daemonMode {
+		return a.runWatchDaemon(g, &watchDaemon{
+			store:           st.Watches,
+			selector:        sel,
+			defaultInterval: defaultInterval,
+			search:          p.Search,
+			notify:          notifyFn,
+			history:         recorder,
+			runLog:          st.RunLog,
+			retentionDays:   cfg.HistoryRetention,
+			concurrency:     workers,
+			deadline:        deadline,
+			now:             time.Now,
+			sleep:           sleepContext,
+			verbose:         g.Verbose,
+			errw:            os.Stderr,
+		})
+	}
+	now := time.Now().UTC()
+	ran := map[string]bool{}
+	selected := func(w model.Watch) bool {
+		if !shouldRunSelected(w, sel) || (*dueOnly && !isWatchDue(w, now, defaultInterval)) {
+			return false
+		}
+		ran[w.ID] = true
+		return true
+	}
+	sigCtx, stop := signalContext()
+	defer stop()
+	ctx, cancel := withDeadline(sigCtx, deadline)
+	defer cancel()
+	report, notifyErrs := runWatchPassSelected(
+		ctx,
 		ws.Watches,
-		*watchID,
-		*runAll,
+		selected,
 		p.Search,
-		func(w model.Watch, alert model.Alert) error { return a.sendWatchNotifications(n, w, alert) },
-		time.Now().UTC(),
+		notifyFn,
+		recorder,
+		workers,
+		now,
 		g.Verbose,
 		os.Stderr,
 	)
-	if err := store.Save(ws); err != nil {
+	if err := saveRunState(st.Watches, ws.Watches, evaluatedIDs(ran, report)); err != nil {
 		return wrapExitError(ExitGenericFailure, err)
 	}
+	logRun(st.RunLog, "run", now, time.Now().UTC(), report, cfg.HistoryRetention, os.Stderr)
 	if g.JSON {
 		if err := writeJSON(report); err != nil {
 			return wrapExitError(ExitGenericFailure, err)
 		}
 	}
 	if g.Plain && !g.JSON {
-		writePlainKV(
-			"evaluated", strconv.Itoa(report.Evaluated),
-			"triggered", strconv.Itoa(report.Triggered),
-			"provider_failures", strconv.Itoa(report.ProviderFailures),
-			"notify_failures", strconv.Itoa(report.NotifyFailures),
-		)
-		alerts := append([]model.Alert(nil), report.Alerts...)
-		sort.SliceStable(alerts, func(i, j int) bool {
-			if alerts[i].WatchID != alerts[j].WatchID {
-				return alerts[i].WatchID < alerts[j].WatchID
-			}
-			if !alerts[i].TriggeredAt.Equal(alerts[j].TriggeredAt) {
-				return alerts[i].TriggeredAt.Before(alerts[j].TriggeredAt)
-			}
-			return alerts[i].LowestPrice < alerts[j].LowestPrice
-		})
-		for _, alert := range alerts {
-			writePlainKV(
-				"alert_watch_id", alert.WatchID,
-				"watch_name", alert.WatchName,
-				"price", strconv.Itoa(alert.LowestPrice),
-				"currency", alert.Currency,
-				"reason", alert.Reason,
-				"url", alert.URL,
-			)
-		}
+		writeWatchRunPlain(report)
 	}
 	if !g.JSON && !g.Plain {
-		fmt.Printf(
-			"Watch run summary: evaluated=%d triggered=%d provider_failures=%d notify_failures=%d\n",
-			report.Evaluated,
-			report.Triggered,
-			report.ProviderFailures,
-			report.NotifyFailures,
-		)
+		fmt.Println(watchRunSummaryLine(report))
+	}
+	if report.Interrupted {
+		return interruptedRunError(ctx, report, notifyErrs)
 	}
 	if len(notifyErrs) > 0 {
 		return newExitError(ExitNotifyFailure, "%s", strings.Join(notifyErrs, "; "))
@@ -124,6 +165,121 @@ func (a App) cmdWatchRun(g globalFlags, args []string) error {
 	return nil
 }
 
+func (a App) runWatchDaemon(g globalFlags, d *watchDaemon) error {
+	ctx, stop := signalContext()
+	defer stop()
+	d.onPass = func(report watchRunReport, notifyErrs []string) {
+		switch {
+		case g.JSON:
+			_ = writeJSONLine(report)
+		case g.Plain:
+			writeWatchRunPlain(report)
+		default:
+			fmt.Printf("[%s] %s\n", time.Now().UTC().Format(time.RFC3339), watchRunSummaryLine(report))
+		}
+		for _, msg := range notifyErrs {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+	}
+	if g.Verbose {
+		fmt.Fprintf(os.Stderr, "watch daemon started (default interval %s)\n", d.defaultInterval)
+	}
+	if err := d.run(ctx); err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	if g.Verbose {
+		fmt.Fprintln(os.Stderr, "watch daemon stopped")
+	}
+	return nil
+}
+
+// logRun records a pass in the run log and drops passes older than the
+// history retention window. Failures only warn: the pass itself is done.
+func logRun(runLog watcher.RunLogBackend, mode string, started, finished time.Time, report watchRunReport, retentionDays int, errw io.Writer) {
+	if runLog == nil {
+		return
+	}
+	entry := model.RunLogEntry{
+		StartedAt:        started,
+		FinishedAt:       finished,
+		Mode:             mode,
+		Evaluated:        report.Evaluated,
+		Triggered:        report.Triggered,
+		Suppressed:       report.Suppressed,
+		ProviderFailures: report.ProviderFailures,
+		NotifyFailures:   report.NotifyFailures,
+		Skipped:          report.Skipped,
+		Interrupted:      report.Interrupted,
+	}
+	for _, alert := range report.Alerts {
+		entry.AlertWatchIDs = append(entry.AlertWatchIDs, alert.WatchID)
+	}
+	err := runLog.Append(entry)
+	if err == nil && retentionDays > 0 {
+		err = runLog.Prune(finished.AddDate(0, 0, -retentionDays))
+	}
+	if err != nil {
+		fmt.Fprintf(errw, "warning: run log: %v\n", err)
+	}
+}
+
+func watchRunSummaryLine(report watchRunReport) string {
+	line := fmt.Sprintf(
+		"Watch run summary: evaluated=%d triggered=%d suppressed=%d provider_failures=%d notify_failures=%d",
+		report.Evaluated,
+		report.Triggered,
+		report.Suppressed,
+		report.ProviderFailures,
+		report.NotifyFailures,
+	)
+	if report.Interrupted {
+		line += fmt.Sprintf(" skipped=%d (interrupted)", report.Skipped)
+	}
+	return line
+}
+
+func writeWatchRunPlain(report watchRunReport) {
+	writePlainKV(
+		"evaluated", strconv.Itoa(report.Evaluated),
+		"triggered", strconv.Itoa(report.Triggered),
+		"suppressed", strconv.Itoa(report.Suppressed),
+		"provider_failures", strconv.Itoa(report.ProviderFailures),
+		"notify_failures", strconv.Itoa(report.NotifyFailures),
+		"skipped", strconv.Itoa(report.Skipped),
+		"interrupted", strconv.FormatBool(report.Interrupted),
+	)
+	alerts := append([]model.Alert(nil), report.Alerts...)
+	sort.SliceStable(alerts, func(i, j int) bool {
+		if alerts[i].WatchID != alerts[j].WatchID {
+			return alerts[i].WatchID < alerts[j].WatchID
+		}
+		if !alerts[i].TriggeredAt.Equal(alerts[j].TriggeredAt) {
+			return alerts[i].TriggeredAt.Before(alerts[j].TriggeredAt)
+		}
+		return alerts[i].LowestPrice < alerts[j].LowestPrice
+	})
+	for _, alert := range alerts {
+		writePlainKV(
+			"alert_watch_id", alert.WatchID,
+			"watch_name", alert.WatchName,
+			"price", strconv.Itoa(alert.LowestPrice),
+			"currency", alert.Currency,
+			"reason", alert.Reason,
+			"url", alert.URL,
+		)
+	}
+	for _, sa := range report.SuppressedAlerts {
+		writePlainKV(
+			"suppressed_watch_id", sa.WatchID,
+			"watch_name", sa.WatchName,
+			"price", strconv.Itoa(sa.LowestPrice),
+			"currency", sa.Currency,
+			"reason", sa.Reason,
+			"suppressed_by", sa.SuppressedBy,
+		)
+	}
+}
+
 func (a App) sendWatchNotifications(n notifyDispatcher, w model.Watch, alert model.Alert) error {
 	notifyErrs := make([]string, 0)
 	if w.NotifyTerminal {
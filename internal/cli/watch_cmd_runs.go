@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+type watchRunsOutput struct {
+	Runs []model.RunLogEntry `json:"runs"`
+}
+
+func (a App) cmdWatchRuns(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("watch runs", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	since := fs.String("since", "", "Only passes started at or after this time (YYYY-MM-DD, RFC3339, or age like 7d/12h)")
+	limit := fs.Int("limit", 20, "Only the most recent N passes (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if *limit < 0 {
+		return newExitError(ExitInvalidUsage, "--limit must be >= 0")
+	}
+	var cutoff time.Time
+	if *since != "" {
+		parsed, err := parseSince(*since, time.Now().UTC())
+		if err != nil {
+			return newExitError(ExitInvalidUsage, "%v", err)
+		}
+		cutoff = parsed
+	}
+	st, err := a.storage(g.StateDir)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	runs, err := st.RunLog.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	runs = filterRecent(runs, func(r model.RunLogEntry) time.Time { return r.StartedAt }, cutoff, *limit)
+
+	if g.JSON {
+		return writeJSON(watchRunsOutput{Runs: runs})
+	}
+	if g.Plain {
+		writePlainTableHeader("started_at", "finished_at", "mode", "evaluated", "triggered", "suppressed", "provider_failures", "notify_failures", "skipped", "interrupted")
+		for _, r := range runs {
+			writePlainTableRow(
+				r.StartedAt.Format(time.RFC3339),
+				r.FinishedAt.Format(time.RFC3339),
+				r.Mode,
+				strconv.Itoa(r.Evaluated),
+				strconv.Itoa(r.Triggered),
+				strconv.Itoa(r.Suppressed),
+				strconv.Itoa(r.ProviderFailures),
+				strconv.Itoa(r.NotifyFailures),
+				strconv.Itoa(r.Skipped),
+				strconv.FormatBool(r.Interrupted),
+			)
+		}
+		return nil
+	}
+	if len(runs) == 0 {
+		fmt.Println("No watch runs recorded")
+		return nil
+	}
+	fmt.Printf("Watch runs: %d\n", len(runs))
+	for _, r := range runs {
+		line := fmt.Sprintf("%s  %-6s  %5s  evaluated=%d triggered=%d suppressed=%d",
+			r.StartedAt.UTC().Format("2006-01-02 15:04"), r.Mode, r.FinishedAt.Sub(r.StartedAt).Round(time.Second),
+			r.Evaluated, r.Triggered, r.Suppressed)
+		if r.ProviderFailures > 0 || r.NotifyFailures > 0 {
+			line += fmt.Sprintf(" failures=provider:%d,notify:%d", r.ProviderFailures, r.NotifyFailures)
+		}
+		if r.Skipped > 0 {
+			line += fmt.Sprintf(" skipped=%d", r.Skipped)
+		}
+		if r.Interrupted {
+			line += " interrupted"
+		}
+		if len(r.AlertWatchIDs) > 0 {
+			line += "  alerts " + strings.Join(r.AlertWatchIDs, ",")
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
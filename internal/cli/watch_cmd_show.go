@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+type watchShowOutput struct {
+	Watch        model.Watch          `json:"watch"`
+	NextDueAt    *time.Time           `json:"next_due_at,omitempty"`
+	LastSnapshot *model.WatchSnapshot `json:"last_snapshot"`
+}
+
+func (a App) cmdWatchShow(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("watch show", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	id := fs.String("id", "", "Watch ID")
+	top := fs.Int("top", 5, "Number of flights to show from the last evaluation (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if *id == "" {
+		return newExitError(ExitInvalidUsage, "--id is required")
+	}
+	if *top < 0 {
+		return newExitError(ExitInvalidUsage, "--top must be >= 0")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	defaultInterval, err := parseCheckInterval(cfg.CheckInterval)
+	if err != nil {
+		return newExitError(ExitInvalidUsage, "config check_interval %v", err)
+	}
+	st, err := openWatchStorage(g.StateDir, cfg)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	ws, err := st.Watches.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	var w *model.Watch
+	for i := range ws.Watches {
+		if ws.Watches[i].ID == *id {
+			w = &ws.Watches[i]
+			break
+		}
+	}
+	if w == nil {
+		return newExitError(ExitGenericFailure, "watch not found: %s", *id)
+	}
+	snap, err := st.Snapshots.Load(w.ID)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	if snap != nil && snap.Result != nil && *top > 0 && len(snap.Result.Flights) > *top {
+		snap.Result.Flights = snap.Result.Flights[:*top]
+	}
+	out := watchShowOutput{Watch: *w, LastSnapshot: snap}
+	if w.Enabled {
+		if next, ok := watchNextDue(*w, w.LastRunAt, defaultInterval); ok {
+			if next.IsZero() {
+				next = time.Now().UTC()
+			}
+			out.NextDueAt = &next
+		}
+	}
+
+	if g.JSON {
+		return writeJSON(out)
+	}
+	if g.Plain {
+		writeWatchShowPlain(out, cfg.CheckInterval)
+		return nil
+	}
+	writeWatchShowHuman(out, cfg.CheckInterval)
+	return nil
+}
+
+func writeWatchShowPlain(out watchShowOutput, defaultInterval string) {
+	w := out.Watch
+	writePlainKV(
+		"watch_id", w.ID,
+		"name", w.Name,
+		"enabled", strconv.FormatBool(w.Enabled),
+		"from", w.Query.From,
+		"to", w.Query.To,
+		"depart", w.Query.Depart,
+		"return", w.Query.Return,
+		"depart_to", w.Query.DepartTo,
+		"trip_length", tripLengthLabel(w.Query),
+		"cabin", string(w.Query.Cabin),
+		"adults", strconv.Itoa(w.Query.Adults),
+		"children", strconv.Itoa(w.Query.Children),
+		"nonstop", strconv.FormatBool(w.Query.Nonstop),
+		"stops", string(w.Query.MaxStops()),
+		"sort", string(w.Query.SortBy),
+		"booking", strconv.FormatBool(w.Query.Booking),
+		"max_price", strconv.Itoa(w.Query.MaxPrice),
+		"currency", w.Query.Currency,
+		"target_price", strconv.Itoa(w.TargetPrice),
+		"rules", watchRulesLabel(w),
+		"schedule", watchScheduleLabel(w, defaultInterval),
+		"next_due_at", formatOptionalTime(out.NextDueAt),
+		"notify_terminal", strconv.FormatBool(w.NotifyTerminal),
+		"notify_email", strconv.FormatBool(w.NotifyEmail),
+		"notify_webhook", strconv.FormatBool(w.NotifyWebhook),
+		"email_to", w.EmailTo,
+		"webhook_url", w.WebhookURL,
+		"last_run_at", formatOptionalTime(&w.LastRunAt),
+		"last_lowest_price", strconv.Itoa(w.LastLowestPrice),
+		"alert_status", w.AlertState.Status,
+	)
+	snap := out.LastSnapshot
+	if snap == nil {
+		return
+	}
+	alertReason := ""
+	if snap.Alert != nil {
+		alertReason = snap.Alert.Reason
+	}
+	flights := snapshotFlights(snap)
+	writePlainKV(
+		"snapshot_checked_at", snap.CheckedAt.Format(time.RFC3339),
+		"error", snap.Error,
+		"alert_reason", alertReason,
+		"suppressed_by", snap.SuppressedBy,
+		"flights_shown", strconv.Itoa(len(flights)),
+	)
+	for i, f := range flights {
+		writePlainKV(
+			"rank", strconv.Itoa(i+1),
+			"price", strconv.Itoa(f.Price),
+			"currency", f.Currency,
+			"airline", f.Airline,
+			"flight_number", f.FlightNumber,
+			"depart_time", f.DepartTime,
+			"arrive_time", f.ArriveTime,
+			"stops", strconv.Itoa(f.Stops),
+		)
+	}
+}
+
+func writeWatchShowHuman(out watchShowOutput, defaultInterval string) {
+	w := out.Watch
+	q := w.Query
+	fmt.Printf("Watch %s (%s)\n", w.Name, w.ID)
+	fmt.Printf("  enabled:       %t\n", w.Enabled)
+	route := fmt.Sprintf("%s -> %s  depart %s", q.From, q.To, q.Depart)
+	if q.DepartTo != "" {
+		route += ".." + q.DepartTo
+	}
+	if q.Return != "" {
+		route += "  return " + q.Return
+	}
+	if q.TripMaxDays > 0 {
+		route += "  trip " + tripLengthLabel(q) + " days"
+	}
+	if q.Flexible() {
+		route += " (cheapest date)"
+	}
+	fmt.Printf("  route:         %s\n", route)
+	fmt.Printf("  travellers:    adults=%d children=%d cabin=%s stops=%s sort=%s booking=%t\n", q.Adults, q.Children, q.Cabin, q.MaxStops(), firstOr(string(q.SortBy), "-"), q.Booking)
+	fmt.Printf("  pricing:       currency=%s max_price=%d target_price=%d\n", q.Currency, q.MaxPrice, w.TargetPrice)
+	fmt.Printf("  rules:         %s\n", firstOr(watchRulesLabel(w), "- (any drop since last run)"))
+	fmt.Printf("  schedule:      %s (next %s)\n", watchScheduleLabel(w, defaultInterval), firstOr(formatOptionalTime(out.NextDueAt), "-"))
+	channels := []string{}
+	if w.NotifyTerminal {
+		channels = append(channels, "terminal")
+	}
+	if w.NotifyEmail {
+		channels = append(channels, "email "+firstOr(w.EmailTo, "(no recipient)"))
+	}
+	if w.NotifyWebhook {
+		channels = append(channels, "webhook "+firstOr(w.WebhookURL, "(config webhook_url)"))
+	}
+	fmt.Printf("  notify:        %s\n", firstOr(strings.Join(channels, ", "), "-"))
+	fmt.Printf("  last run:      %s\n", firstOr(formatOptionalTime(&w.LastRunAt), "never"))
+	fmt.Printf("  last lowest:   %d\n", w.LastLowestPrice)
+	fmt.Printf("  alert state:   %s\n", firstOr(w.AlertState.Status, alertStateArmed))
+
+	snap := out.LastSnapshot
+	if snap == nil {
+		fmt.Println("No evaluation recorded yet")
+		return
+	}
+	fmt.Printf("Last evaluation at %s\n", snap.CheckedAt.Format(time.RFC3339))
+	if snap.Error != "" {
+		fmt.Printf("  provider error: %s\n", snap.Error)
+		return
+	}
+	if snap.Alert != nil {
+		if snap.SuppressedBy != "" {
+			fmt.Printf("  alert suppressed (%s): %s\n", snap.SuppressedBy, snap.Alert.Reason)
+		} else {
+			fmt.Printf("  alert: %s\n", snap.Alert.Reason)
+		}
+	}
+	flights := snapshotFlights(snap)
+	if len(flights) == 0 {
+		fmt.Println("  no flights returned")
+		return
+	}
+	for i, f := range flights {
+		fmt.Printf("  %d. %d %s | %s %s | stops:%d | %s -> %s\n", i+1, f.Price, f.Currency, f.Airline, f.FlightNumber, f.Stops, f.DepartTime, f.ArriveTime)
+	}
+	if snap.Result.URL != "" {
+		fmt.Printf("  %s\n", snap.Result.URL)
+	}
+}
+
+func snapshotFlights(snap *model.WatchSnapshot) []model.Flight {
+	if snap == nil || snap.Result == nil {
+		return nil
+	}
+	return snap.Result.Flights
+}
+
+func watchRulesLabel(w model.Watch) string {
+	rules := make([]string, 0, len(w.Rules))
+	for _, r := range w.Rules {
+		rules = append(rules, formatAlertRule(r))
+	}
+	return strings.Join(rules, ",")
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/watcher"
+)
+
+func TestRunWatchPassSavesSnapshots(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	watches := []model.Watch{
+		{ID: "ok", Enabled: true, TargetPrice: 700, Query: model.SearchQuery{From: "SFO"}},
+		{ID: "down", Enabled: true, Query: model.SearchQuery{From: "OAK"}},
+	}
+	search := func(_ context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		if q.From == "OAK" {
+			return model.SearchResult{}, errors.New("provider unavailable")
+		}
+		return model.SearchResult{Flights: []model.Flight{{Price: 650, Airline: "Aegean"}}}, nil
+	}
+	history := &fakeHistory{}
+	runWatchPassSelected(context.Background(), watches, func(model.Watch) bool { return true }, search, func(model.Watch, model.Alert) error { return nil }, history, 1, now, false, nil)
+
+	ok := history.snapshots["ok"]
+	if ok.Result == nil || ok.Result.Flights[0].Price != 650 || ok.Alert == nil || !ok.CheckedAt.Equal(now) {
+		t.Fatalf("unexpected snapshot for triggered watch: %+v", ok)
+	}
+	down := history.snapshots["down"]
+	if down.Error != "provider unavailable" || down.Result != nil {
+		t.Fatalf("unexpected snapshot for failed watch: %+v", down)
+	}
+}
+
+func TestWatchShowOutputs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--rule", "all_time_low"}); err != nil {
+		t.Fatalf("create watch: %v", err)
+	}
+	id := onlyWatchID(t, stateDir)
+
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--state-dir", stateDir, "watch", "show", "--id", id})
+	})
+	if err != nil {
+		t.Fatalf("show without snapshot: %v", err)
+	}
+	if !strings.Contains(out, "rules:         all_time_low") || !strings.Contains(out, "No evaluation recorded yet") {
+		t.Fatalf("unexpected human output: %q", out)
+	}
+
+	snapshots := watcher.SnapshotStore{Dir: filepath.Join(stateDir, "snapshots")}
+	flights := []model.Flight{{Price: 640, Currency: "USD", Airline: "Aegean"}, {Price: 700, Currency: "USD", Airline: "United"}, {Price: 710, Currency: "USD", Airline: "Delta"}}
+	if err := snapshots.Save(model.WatchSnapshot{
+		WatchID:      id,
+		CheckedAt:    time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC),
+		Result:       &model.SearchResult{Flights: flights},
+		Alert:        &model.Alert{WatchID: id, Reason: "all_time_low: new all-time low 640 (previous low 700)"},
+		SuppressedBy: "cooldown",
+	}); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "show", "--id", id, "--top", "2"})
+	})
+	if err != nil {
+		t.Fatalf("show plain: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "watch_id="+id+"\tname=athens") {
+		t.Fatalf("unexpected plain output: %q", out)
+	}
+	if !strings.Contains(lines[1], "suppressed_by=cooldown\tflights_shown=2") || !strings.HasPrefix(lines[2], "rank=1\tprice=640") {
+		t.Fatalf("unexpected plain snapshot lines: %q", out)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--json", "--state-dir", stateDir, "watch", "show", "--id", id, "--top", "0"})
+	})
+	if err != nil {
+		t.Fatalf("show json: %v", err)
+	}
+	var payload watchShowOutput
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	if payload.Watch.ID != id || payload.LastSnapshot == nil || len(payload.LastSnapshot.Result.Flights) != 3 || payload.NextDueAt == nil {
+		t.Fatalf("unexpected json payload: %+v", payload)
+	}
+
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "show"}); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected invalid usage without --id, got %v", err)
+	}
+}
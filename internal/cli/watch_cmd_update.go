@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+type watchFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type watchUpdateOutput struct {
+	WatchID string             `json:"watch_id"`
+	DryRun  bool               `json:"dry_run"`
+	Changes []watchFieldChange `json:"changes"`
+	// HistoryReset reports that the fare changed, so the watch's price
+	// history, snapshot and run state are deleted.
+	HistoryReset bool        `json:"history_reset"`
+	Watch        model.Watch `json:"watch"`
+}
+
+func (a App) cmdWatchUpdate(g globalFlags, args []string) error {
+	fs, q, wf := newWatchFlagSet("watch update")
+	id := fs.String("id", "", "Watch ID")
+	clearRules := fs.Bool("clear-rules", false, "Remove all --rule alert rules")
+	clearTags := fs.Bool("clear-tags", false, "Remove all tags")
+	if err := fs.Parse(args); err != nil {
+		return newExitError(ExitInvalidUsage, "%v", err)
+	}
+	if *id == "" {
+		return newExitError(ExitInvalidUsage, "--id is required")
+	}
+	if err := checkDateFlags(fs); err != nil {
+		return err
+	}
+	if *clearRules && flagWasSet(fs, "rule") {
+		return newExitError(ExitInvalidUsage, "--clear-rules and --rule are mutually exclusive")
+	}
+	if *clearTags && flagWasSet(fs, "tag") {
+		return newExitError(ExitInvalidUsage, "--clear-tags and --tag are mutually exclusive")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	st, err := openWatchStorage(g.StateDir, cfg)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	unlock, err := lockStorage(st)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	ws, err := st.Watches.Load()
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	idx := -1
+	for i := range ws.Watches {
+		if ws.Watches[i].ID == *id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return newExitError(ExitGenericFailure, "watch not found: %s", *id)
+	}
+	old := ws.Watches[idx]
+	updated := old
+	updated.Rules = append([]model.AlertRule(nil), old.Rules...)
+	updated.Tags = append([]string(nil), old.Tags...)
+	if flagWasSet(fs, "max-requests") {
+		if updated.MaxRequests, err = watchMaxRequests(fs, *wf.maxRequests); err != nil {
+			return err
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "from":
+			updated.Query.From = q.From
+		case "to":
+			updated.Query.To = q.To
+		case "depart":
+			updated.Query.Depart = q.Depart
+			updated.Query.DepartTo = ""
+		case "depart-range":
+			updated.Query.Depart = q.Depart
+			updated.Query.DepartTo = q.DepartTo
+		case "return":
+			updated.Query.Return = q.Return
+			if q.Return != "" {
+				updated.Query.TripMinDays, updated.Query.TripMaxDays = 0, 0
+			}
+		case "trip-length":
+			updated.Query.TripMinDays = q.TripMinDays
+			updated.Query.TripMaxDays = q.TripMaxDays
+			updated.Query.Return = ""
+		case "cabin":
+			updated.Query.Cabin = q.Cabin
+		case "adults":
+			updated.Query.Adults = q.Adults
+		case "children":
+			updated.Query.Children = q.Children
+		case "nonstop":
+			updated.Query.Nonstop = q.Nonstop
+			if !flagWasSet(fs, "stops") {
+				updated.Query.Stops = ""
+			}
+		case "stops":
+			updated.Query.Stops = q.Stops
+			if !flagWasSet(fs, "nonstop") {
+				updated.Query.Nonstop = false
+			}
+		case "max-price":
+			updated.Query.MaxPrice = q.MaxPrice
+		case "currency":
+			updated.Query.Currency = q.Currency
+		case "sort":
+			updated.Query.SortBy = q.SortBy
+		case "booking":
+			updated.Query.Booking = q.Booking
+		case "allow-unknown-airports":
+			updated.Query.AllowUnknownAirports = q.AllowUnknownAirports
+		case "name":
+			updated.Name = *wf.name
+		case "tag":
+			updated.Tags = normalizeTags(wf.tags)
+		case "clear-tags":
+			if *clearTags {
+				updated.Tags = nil
+			}
+		case "target-price":
+			updated.TargetPrice = *wf.target
+		case "rule":
+			updated.Rules = wf.rules
+		case "clear-rules":
+			if *clearRules {
+				updated.Rules = nil
+			}
+		case "cooldown":
+			updated.Cooldown = *wf.cooldown
+		case "rearm-percent":
+			updated.RearmPercent = *wf.rearmPercent
+		case "notify-terminal":
+			updated.NotifyTerminal = *wf.notifyTerminal
+		case "notify-email":
+			updated.NotifyEmail = *wf.notifyEmail
+		case "notify-webhook":
+			updated.NotifyWebhook = *wf.notifyWebhook
+		case "email-to":
+			updated.EmailTo = *wf.emailTo
+		case "webhook-url":
+			updated.WebhookURL = *wf.webhookURL
+		case "check-interval":
+			updated.CheckInterval = *wf.checkInterval
+			if *wf.checkInterval != "" && !flagWasSet(fs, "schedule") {
+				updated.Schedule = ""
+			}
+		case "schedule":
+			updated.Schedule = *wf.schedule
+			if *wf.schedule != "" && !flagWasSet(fs, "check-interval") {
+				updated.CheckInterval = ""
+			}
+		}
+	})
+	// A stored query may have aged since it was created, e.g. its departure
+	// passed, so only the fields this update sets must validate.
+	var problems fieldErrors
+	for _, p := range queryProblems(updated.Query, time.Now()) {
+		if queryProblemChanged(fs, p) {
+			problems = append(problems, p)
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: watch %s: %s\n", updated.ID, p)
+		}
+	}
+	if len(problems) > 0 {
+		return ExitError{Code: ExitInvalidUsage, Err: ValidationError{Problems: problems}}
+	}
+	if err := validateWatchSettings(updated, cfg); err != nil {
+		return err
+	}
+	warnUnknownAirports(updated.Query)
+	newFare := !updated.Query.SameFare(old.Query)
+	if newFare {
+		resetWatchRunState(&updated)
+	}
+	changes, err := diffWatches(old, updated)
+	if err != nil {
+		return wrapExitError(ExitGenericFailure, err)
+	}
+	if len(changes) > 0 {
+		updated.UpdatedAt = time.Now().UTC()
+	}
+	historyReset := newFare && len(changes) > 0
+	if !*wf.dryRun && len(changes) > 0 {
+		ws.Watches[idx] = updated
+		if err := st.Watches.Save(ws); err != nil {
+			return wrapExitError(ExitGenericFailure, err)
+		}
+		if historyReset {
+			if err := deleteWatchArtifacts(st, []string{updated.ID}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if g.JSON {
+		return writeJSON(watchUpdateOutput{WatchID: updated.ID, DryRun: *wf.dryRun, Changes: changes, HistoryReset: historyReset, Watch: updated})
+	}
+	if g.Plain {
+		writePlainKV("watch_id", updated.ID, "changed", strconv.Itoa(len(changes)), "dry_run", strconv.FormatBool(*wf.dryRun), "history_reset", strconv.FormatBool(historyReset))
+		for _, c := range changes {
+			writePlainKV("field", c.Field, "old", c.Old, "new", c.New)
+		}
+		return nil
+	}
+	if len(changes) == 0 {
+		fmt.Printf("No changes for watch %s (%s)\n", updated.Name, updated.ID)
+		return nil
+	}
+	verb := "Updated"
+	if *wf.dryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("%s watch %s (%s):\n", verb, updated.Name, updated.ID)
+	for _, c := range changes {
+		fmt.Printf("  %s: %s -> %s\n", c.Field, firstOr(c.Old, `""`), firstOr(c.New, `""`))
+	}
+	if historyReset {
+		if *wf.dryRun {
+			fmt.Println("  The fare changes: price history, snapshot and alert state would be deleted.")
+		} else {
+			fmt.Println("  The fare changed: price history, snapshot and alert state were deleted.")
+		}
+	}
+	return nil
+}
+
+// queryProblemFlags lists, per problem field, the other flags whose change
+// can cause it: dates are checked together and codes depend on
+// --allow-unknown-airports.
+var queryProblemFlags = map[string][]string{
+	"from":         {"allow-unknown-airports", "to"},
+	"to":           {"allow-unknown-airports", "from"},
+	"depart":       {"depart-range"},
+	"depart-range": {"depart"},
+	"trip-length":  {"depart-range"},
+	"return":       {"depart", "depart-range", "trip-length"},
+	"adults":       {"children"},
+	"children":     {"adults"},
+	"stops":        {"nonstop"},
+}
+
+// queryProblemChanged reports whether an update flag touched the field a
+// query problem is about.
+func queryProblemChanged(fs *flag.FlagSet, p FieldError) bool {
+	if flagWasSet(fs, p.Field) {
+		return true
+	}
+	for _, name := range queryProblemFlags[p.Field] {
+		if flagWasSet(fs, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// resetWatchRunState clears run state that describes a previous query, so the
+// watch is due immediately and alerts from scratch. Callers also delete the
+// watch's price history and snapshot once the change is saved, so rules and
+// watch history never compare the new fare with the old one.
+func resetWatchRunState(w *model.Watch) {
+	w.LastLowestPrice = 0
+	w.LastRunAt = time.Time{}
+	w.AlertState = model.AlertState{Status: alertStateArmed}
+}
+
+// diffWatches compares two watches field by field using their JSON names,
+// with nested objects flattened to dotted keys (query.depart).
+func diffWatches(old, updated model.Watch) ([]watchFieldChange, error) {
+	before, err := flattenWatch(old)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flattenWatch(updated)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	changes := []watchFieldChange{}
+	for k := range keys {
+		if k == "updated_at" || before[k] == after[k] {
+			continue
+		}
+		changes = append(changes, watchFieldChange{Field: k, Old: before[k], New: after[k]})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+func flattenWatch(w model.Watch) (map[string]string, error) {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	flattenValue("", raw, out)
+	if len(w.Rules) > 0 {
+		out["rules"] = watchRulesLabel(w)
+	} else {
+		delete(out, "rules")
+	}
+	if len(w.Tags) > 0 {
+		out["tags"] = strings.Join(w.Tags, ",")
+	} else {
+		delete(out, "tags")
+	}
+	return out, nil
+}
+
+func flattenValue(prefix string, v any, out map[string]string) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flattenValue(key, child, out)
+		}
+	case nil:
+		out[prefix] = ""
+	case string:
+		out[prefix] = val
+	default:
+		b, _ := json.Marshal(val)
+		out[prefix] = string(b)
+	}
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/config"
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/watcher"
+)
+
+func TestWatchUpdateChangesOnlyExplicitFlags(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--target-price", "700", "--cabin", "business", "--schedule", "@hourly"}); err != nil {
+		t.Fatalf("create watch: %v", err)
+	}
+	store := watcher.Store{Path: filepath.Join(stateDir, "watches.json")}
+	ws, err := store.Load()
+	if err != nil {
+		t.Fatalf("load store: %v", err)
+	}
+	ws.Watches[0].LastLowestPrice = 720
+	if err := store.Save(ws); err != nil {
+		t.Fatalf("save store: %v", err)
+	}
+	before := onlyWatch(t, stateDir)
+
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "update", "--id", before.ID, "--target-price", "650", "--notify-email=true", "--check-interval", "30m", "--dry-run"})
+	})
+	if err != nil {
+		t.Fatalf("dry-run update: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if lines[0] != "watch_id="+before.ID+"\tchanged=4\tdry_run=true\thistory_reset=false" {
+		t.Fatalf("unexpected plain summary: %q", lines[0])
+	}
+	if !strings.Contains(out, "field=target_price\told=700\tnew=650") || !strings.Contains(out, "field=schedule\told=@hourly\tnew=") {
+		t.Fatalf("expected field diffs, got %q", out)
+	}
+	if onlyWatch(t, stateDir).TargetPrice != 700 {
+		t.Fatalf("dry-run must not save")
+	}
+
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "update", "--id", before.ID, "--target-price", "650"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	after := onlyWatch(t, stateDir)
+	if after.TargetPrice != 650 || after.Query.Cabin != "business" || after.Schedule != "@hourly" || after.LastLowestPrice != 720 {
+		t.Fatalf("expected only target price to change, got %+v", after)
+	}
+	if !after.CreatedAt.Equal(before.CreatedAt) || !after.UpdatedAt.After(before.UpdatedAt) {
+		t.Fatalf("expected created_at kept and updated_at bumped: %+v", after)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--json", "--state-dir", stateDir, "watch", "update", "--id", before.ID, "--depart", "2030-06-12"})
+	})
+	if err != nil {
+		t.Fatalf("update depart: %v", err)
+	}
+	var payload watchUpdateOutput
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	fields := []string{}
+	for _, c := range payload.Changes {
+		fields = append(fields, c.Field)
+	}
+	if strings.Join(fields, ",") != "last_lowest_price,query.depart" || payload.Watch.LastLowestPrice != 0 || !payload.HistoryReset {
+		t.Fatalf("expected query change to reset last price, got %v %+v", fields, payload.Watch)
+	}
+}
+
+func TestWatchUpdateClearsHistoryWhenFareChanges(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}); err != nil {
+		t.Fatalf("create watch: %v", err)
+	}
+	id := onlyWatchID(t, stateDir)
+	h := watcher.HistoryStore{Dir: filepath.Join(stateDir, "history")}
+	if err := h.Append(id, model.PriceHistoryEntry{CheckedAt: time.Now().UTC(), LowestPrice: 700, Currency: "USD"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	for _, tc := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"--notify-email", "--booking"}, 1},
+		{[]string{"--sort", "duration", "--max-price", "900"}, 1},
+		{[]string{"--to", "FCO", "--dry-run"}, 1},
+		{[]string{"--to", "FCO"}, 0},
+	} {
+		if err := app.Run(append([]string{"--state-dir", stateDir, "watch", "update", "--id", id}, tc.args...)); err != nil {
+			t.Fatalf("update %v: %v", tc.args, err)
+		}
+		entries, err := h.Load(id)
+		if err != nil {
+			t.Fatalf("load history: %v", err)
+		}
+		if len(entries) != tc.want {
+			t.Fatalf("after update %v expected %d history entries, got %d", tc.args, tc.want, len(entries))
+		}
+	}
+}
+
+func TestWatchUpdateValidation(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}); err != nil {
+		t.Fatalf("create watch: %v", err)
+	}
+	id := onlyWatchID(t, stateDir)
+	cases := [][]string{
+		{"--target-price", "1"},
+		{"--id", id, "--to", ""},
+		{"--id", id, "--schedule", "@hourly", "--check-interval", "30m"},
+		{"--id", id, "--rule", "all_time_low", "--clear-rules"},
+		{"--id", id, "--cabin", "steerage"},
+		{"--id", id, "--stops", "3"},
+		{"--id", id, "--nonstop", "--stops", "1"},
+	}
+	for _, args := range cases {
+		err := app.Run(append([]string{"--state-dir", stateDir, "watch", "update"}, args...))
+		if ExitCode(err) != ExitInvalidUsage {
+			t.Fatalf("expected invalid usage for %v, got %v", args, err)
+		}
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "update", "--id", "w_missing", "--name", "x"}); ExitCode(err) != ExitGenericFailure {
+		t.Fatalf("expected not found failure, got %v", err)
+	}
+
+	// Once the trip date passes, edits that leave the dates alone still work
+	// and only a new past date is rejected.
+	store := watcher.Store{Path: filepath.Join(stateDir, "watches.json")}
+	ws, err := store.Load()
+	if err != nil {
+		t.Fatalf("load store: %v", err)
+	}
+	ws.Watches[0].Query.Depart = "2020-06-10"
+	if err := store.Save(ws); err != nil {
+		t.Fatalf("save store: %v", err)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "update", "--id", id, "--notify-email", "--cabin", "business"}); err != nil {
+		t.Fatalf("expected update of a past-dated watch to succeed, got %v", err)
+	}
+	if w := onlyWatch(t, stateDir); !w.NotifyEmail || w.Query.Cabin != "business" {
+		t.Fatalf("expected update saved, got %+v", w)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "update", "--id", id, "--depart", "2020-07-01"}); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected a new past departure to be rejected, got %v", err)
+	}
+}
+
+func TestWatchDateWindowCreateAndUpdate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart-range", "2030-06-01..2030-06-30", "--trip-length", "6-8"})
+	if ExitCode(err) != ExitInvalidUsage || !strings.Contains(err.Error(), "90 route and date combinations") {
+		t.Fatalf("expected oversized window to be rejected, got %v", err)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart-range", "2030-06-01..2030-06-30", "--trip-length", "6-8", "--max-requests", "90", "--dry-run"}); err != nil {
+		t.Fatalf("expected --max-requests to raise the budget, got %v", err)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-01", "--max-requests", "0"}); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected --max-requests 0 to be rejected, got %v", err)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart-range", "2030-06-01..2030-06-15", "--trip-length", "7"}); err != nil {
+		t.Fatalf("create watch: %v", err)
+	}
+	w := onlyWatch(t, stateDir)
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "update", "--id", w.ID, "--booking"}); ExitCode(err) != ExitInvalidUsage || !strings.Contains(err.Error(), "--booking cannot be combined") {
+		t.Fatalf("expected --booking on a window to be rejected, got %v", err)
+	}
+	if q := w.Query; q.Depart != "2030-06-01" || q.DepartTo != "2030-06-15" || q.TripMinDays != 7 || q.TripMaxDays != 7 || q.Return != "" {
+		t.Fatalf("unexpected window query: %+v", q)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "update", "--id", w.ID, "--return", "2030-06-08"}); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected --return on a window to be rejected, got %v", err)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "update", "--id", w.ID, "--depart", "2030-06-05"}); err != nil {
+		t.Fatalf("update depart: %v", err)
+	}
+	if q := onlyWatch(t, stateDir).Query; q.Depart != "2030-06-05" || q.DepartTo != "" || q.TripMaxDays != 7 {
+		t.Fatalf("expected --depart to collapse the window only: %+v", q)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "update", "--id", w.ID, "--return", "2030-06-12"}); err != nil {
+		t.Fatalf("update return: %v", err)
+	}
+	if q := onlyWatch(t, stateDir).Query; q.Return != "2030-06-12" || q.Flexible() {
+		t.Fatalf("expected a fixed round trip: %+v", q)
+	}
+}
+
+func TestValidateWatchCountsProviderLookups(t *testing.T) {
+	q := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2030-06-10", Return: "2030-06-20", Adults: 1, Cabin: "economy", Currency: "USD", SortBy: "price", Booking: true}
+	w := model.Watch{Query: q, MaxRequests: 6}
+	err := validateWatch(w, config.Config{})
+	if ExitCode(err) != ExitInvalidUsage || !strings.Contains(err.Error(), "up to 7 provider requests") {
+		t.Fatalf("expected return and booking lookups counted, got %v", err)
+	}
+	w.MaxRequests = 7
+	if err := validateWatch(w, config.Config{}); err != nil {
+		t.Fatalf("expected the budget to cover the lookups, got %v", err)
+	}
+	w.MaxRequests = 1
+	if err := validateWatch(w, config.Config{Provider: "google-url"}); err != nil {
+		t.Fatalf("expected one request per search without SerpAPI lookups, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/watcher"
+)
+
+const daemonPollInterval = time.Minute
+
+type watchDaemon struct {
+	store           watcher.Backend
+	selector        watchSelector
+	defaultInterval time.Duration
+	search          watchSearchFunc
+	notify          watchNotifyFunc
+	history         watchHistory
+	runLog          watcher.RunLogBackend
+	retentionDays   int
+	concurrency     int
+	deadline        time.Duration
+	now             func() time.Time
+	sleep           func(context.Context, time.Duration) error
+	onPass          func(watchRunReport, []string)
+	verbose         bool
+	errw            io.Writer
+
+	lastAttempt map[string]time.Time
+}
+
+func (d *watchDaemon) run(ctx context.Context) error {
+	if d.lastAttempt == nil {
+		d.lastAttempt = map[string]time.Time{}
+	}
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		wait, err := d.pass(ctx)
+		if err != nil {
+			// Store errors, such as the lock timing out while another command
+			// holds it, are usually transient; only ctx stops the daemon.
+			fmt.Fprintf(d.errw, "warning: watch daemon pass: %v (retrying in %s)\n", err, wait)
+		}
+		if err := d.sleep(ctx, wait); err != nil {
+			return nil
+		}
+	}
+}
+
+// pass reloads the store so watches created or edited while the daemon is
+// running are picked up, evaluates the ones that are due, and saves state.
+// It returns how long to wait before the next pass. A pass cut short by ctx
+// or the per-pass deadline still saves the watches it finished. When the
+// store cannot be loaded or saved, it returns the poll interval with the error.
+func (d *watchDaemon) pass(ctx context.Context) (time.Duration, error) {
+	ws, err := d.store.Load()
+	if err != nil {
+		return daemonPollInterval, err
+	}
+	now := d.now().UTC()
+	due := map[string]bool{}
+	for _, w := range ws.Watches {
+		if next, ok := d.nextDue(w); ok && shouldRunSelected(w, d.selector) && !next.After(now) {
+			due[w.ID] = true
+		}
+	}
+	if len(due) > 0 {
+		passCtx, cancel := withDeadline(ctx, d.deadline)
+		report, notifyErrs := runWatchPassSelected(
+			passCtx,
+			ws.Watches,
+			func(w model.Watch) bool { return due[w.ID] },
+			d.search,
+			d.notify,
+			d.history,
+			d.concurrency,
+			now,
+			d.verbose,
+			d.errw,
+		)
+		cancel()
+		ran := evaluatedIDs(due, report)
+		for id := range ran {
+			d.lastAttempt[id] = now
+		}
+		if err := saveRunState(d.store, ws.Watches, ran); err != nil {
+			return daemonPollInterval, err
+		}
+		logRun(d.runLog, "daemon", now, d.now().UTC(), report, d.retentionDays, d.errw)
+		if d.onPass != nil {
+			d.onPass(report, notifyErrs)
+		}
+	}
+	return d.untilNext(ws.Watches, now), nil
+}
+
+// nextDue uses the later of the persisted run time and the last attempt made
+// by this process, so provider failures back off until the next slot instead
+// of being retried on every poll.
+func (d *watchDaemon) nextDue(w model.Watch) (time.Time, bool) {
+	last := w.LastRunAt
+	if attempt, ok := d.lastAttempt[w.ID]; ok && attempt.After(last) {
+		last = attempt
+	}
+	return watchNextDue(w, last, d.defaultInterval)
+}
+
+func (d *watchDaemon) untilNext(watches []model.Watch, now time.Time) time.Duration {
+	wait := daemonPollInterval
+	for _, w := range watches {
+		if !shouldRunSelected(w, d.selector) {
+			continue
+		}
+		next, ok := d.nextDue(w)
+		if !ok {
+			continue
+		}
+		if until := next.Sub(now); until < wait {
+			wait = until
+		}
+	}
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return wait
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
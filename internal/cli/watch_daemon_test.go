@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/watcher"
+)
+
+func TestParseCheckInterval(t *testing.T) {
+	if _, err := parseCheckInterval("30s"); err == nil {
+		t.Fatalf("expected error for interval below minimum")
+	}
+	if _, err := parseCheckInterval("soon"); err == nil {
+		t.Fatalf("expected error for malformed interval")
+	}
+	d, err := parseCheckInterval("2h")
+	if err != nil || d != 2*time.Hour {
+		t.Fatalf("expected 2h, got %s err=%v", d, err)
+	}
+}
+
+func TestWatchDaemonRunsEachWatchOnItsOwnInterval(t *testing.T) {
+	store := watcher.Store{Path: filepath.Join(t.TempDir(), "watches.json")}
+	if err := store.Save(model.WatchStore{Watches: []model.Watch{
+		{ID: "fast", Enabled: true, CheckInterval: "10m"},
+		{ID: "slow", Enabled: true},
+		{ID: "off", Enabled: false},
+	}}); err != nil {
+		t.Fatalf("save store: %v", err)
+	}
+
+	clock := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	calls := map[string]int{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var waits []time.Duration
+	d := &watchDaemon{
+		store:           store,
+		selector:        watchSelector{All: true},
+		defaultInterval: 30 * time.Minute,
+		search: func(_ context.Context, q model.SearchQuery) (model.SearchResult, error) {
+			return model.SearchResult{Flights: []model.Flight{{Price: 500, Currency: "USD"}}}, nil
+		},
+		notify: func(model.Watch, model.Alert) error { return nil },
+		now:    func() time.Time { return clock },
+		sleep: func(_ context.Context, wait time.Duration) error {
+			waits = append(waits, wait)
+			clock = clock.Add(wait)
+			if clock.Sub(time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)) >= time.Hour {
+				cancel()
+				return context.Canceled
+			}
+			return nil
+		},
+		onPass: func(report watchRunReport, _ []string) {
+			for _, w := range mustLoadWatches(t, store) {
+				if w.LastRunAt.Equal(clock) {
+					calls[w.ID]++
+				}
+			}
+		},
+	}
+	if err := d.run(ctx); err != nil {
+		t.Fatalf("daemon run: %v", err)
+	}
+	if calls["fast"] != 6 {
+		t.Fatalf("expected fast watch to run 6 times in an hour, got %d", calls["fast"])
+	}
+	if calls["slow"] != 2 {
+		t.Fatalf("expected slow watch to run 2 times in an hour, got %d", calls["slow"])
+	}
+	if calls["off"] != 0 {
+		t.Fatalf("expected disabled watch not to run, got %d", calls["off"])
+	}
+	for _, w := range waits {
+		if w > daemonPollInterval {
+			t.Fatalf("expected waits capped at poll interval, got %s", w)
+		}
+	}
+}
+
+func TestWatchDaemonBacksOffAfterProviderFailure(t *testing.T) {
+	store := watcher.Store{Path: filepath.Join(t.TempDir(), "watches.json")}
+	if err := store.Save(model.WatchStore{Watches: []model.Watch{{ID: "w1", Enabled: true}}}); err != nil {
+		t.Fatalf("save store: %v", err)
+	}
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	searches := 0
+	d := &watchDaemon{
+		store:           store,
+		selector:        watchSelector{All: true},
+		defaultInterval: 15 * time.Minute,
+		search: func(context.Context, model.SearchQuery) (model.SearchResult, error) {
+			searches++
+			return model.SearchResult{}, context.DeadlineExceeded
+		},
+		notify:      func(model.Watch, model.Alert) error { return nil },
+		now:         func() time.Time { return now },
+		lastAttempt: map[string]time.Time{},
+	}
+	if _, err := d.pass(context.Background()); err != nil {
+		t.Fatalf("first pass: %v", err)
+	}
+	now = now.Add(5 * time.Minute)
+	if _, err := d.pass(context.Background()); err != nil {
+		t.Fatalf("second pass: %v", err)
+	}
+	if searches != 1 {
+		t.Fatalf("expected failed watch to wait for its interval, got %d searches", searches)
+	}
+}
+
+func TestWatchDaemonRetriesAfterStoreError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watches.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("write store: %v", err)
+	}
+	store := watcher.Store{Path: path}
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var errw bytes.Buffer
+	searches, sleeps := 0, []time.Duration{}
+	d := &watchDaemon{
+		store:           store,
+		selector:        watchSelector{All: true},
+		defaultInterval: 15 * time.Minute,
+		search: func(context.Context, model.SearchQuery) (model.SearchResult, error) {
+			searches++
+			return model.SearchResult{}, nil
+		},
+		notify: func(model.Watch, model.Alert) error { return nil },
+		now:    func() time.Time { return now },
+		sleep: func(_ context.Context, wait time.Duration) error {
+			sleeps = append(sleeps, wait)
+			if len(sleeps) == 1 {
+				// The store becomes readable again before the next pass.
+				if err := store.Save(model.WatchStore{Watches: []model.Watch{{ID: "w1", Enabled: true}}}); err != nil {
+					t.Fatalf("save store: %v", err)
+				}
+				return nil
+			}
+			cancel()
+			return ctx.Err()
+		},
+		errw: &errw,
+	}
+	if err := d.run(ctx); err != nil {
+		t.Fatalf("expected daemon to exit cleanly on cancel, got %v", err)
+	}
+	if searches != 1 || len(sleeps) != 2 || sleeps[0] != daemonPollInterval {
+		t.Fatalf("expected a retry after the store error, got searches=%d sleeps=%v", searches, sleeps)
+	}
+	if !strings.Contains(errw.String(), "warning: watch daemon pass:") {
+		t.Fatalf("expected store error logged, got %q", errw.String())
+	}
+}
+
+func TestWatchRunRejectsOnceWithDaemon(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := NewApp("test")
+	err := app.Run([]string{"--state-dir", t.TempDir(), "watch", "run", "--all", "--once", "--daemon"})
+	if ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected invalid usage, got err=%v code=%d", err, ExitCode(err))
+	}
+}
+
+func mustLoadWatches(t *testing.T, store watcher.Store) []model.Watch {
+	t.Helper()
+	ws, err := store.Load()
+	if err != nil {
+		t.Fatalf("load store: %v", err)
+	}
+	return ws.Watches
+}
+
+func TestSaveRunStateMergesIntoFreshStore(t *testing.T) {
+	store := watcher.Store{Path: filepath.Join(t.TempDir(), "watches.json")}
+	q := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"}
+	if err := store.Save(model.WatchStore{Watches: []model.Watch{{ID: "w1", Query: q}, {ID: "w2", Query: q}}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	ran := mustLoadWatches(t, store)
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	for i := range ran {
+		ran[i].LastRunAt = now
+		ran[i].LastLowestPrice = 640
+	}
+
+	// Concurrent edits while providers were queried: w2's query changed and a
+	// new watch was created.
+	current := mustLoadWatches(t, store)
+	current[1].Query.Depart = "2026-06-12"
+	current = append(current, model.Watch{ID: "w3", Query: q})
+	if err := store.Save(model.WatchStore{Watches: current}); err != nil {
+		t.Fatalf("save concurrent edit: %v", err)
+	}
+
+	if err := saveRunState(store, ran, map[string]bool{"w1": true, "w2": true}); err != nil {
+		t.Fatalf("saveRunState: %v", err)
+	}
+	got := mustLoadWatches(t, store)
+	if len(got) != 3 {
+		t.Fatalf("expected concurrently created watch kept, got %d watches", len(got))
+	}
+	if got[0].LastLowestPrice != 640 || !got[0].LastRunAt.Equal(now) {
+		t.Fatalf("expected run state saved for w1: %+v", got[0])
+	}
+	if got[1].LastLowestPrice != 0 || got[1].Query.Depart != "2026-06-12" {
+		t.Fatalf("expected edited w2 left alone: %+v", got[1])
+	}
+}
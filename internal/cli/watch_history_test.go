@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/watcher"
+)
+
+func TestRunWatchPassRecordsHistory(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	watches := []model.Watch{{ID: "w1", Enabled: true}, {ID: "w2", Enabled: true}}
+	search := func(_ context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		return model.SearchResult{Flights: []model.Flight{{Price: 650, Currency: "EUR", Airline: "Aegean"}, {Price: 700}}}, nil
+	}
+	history := &fakeHistory{}
+	runWatchPassSelected(context.Background(), watches, func(w model.Watch) bool { return w.ID == "w1" }, search, func(model.Watch, model.Alert) error { return nil }, history, 1, now, false, nil)
+	recorded := history.entries
+	if len(recorded) != 1 {
+		t.Fatalf("expected only selected watch recorded, got %v", recorded)
+	}
+	e := recorded["w1"][0]
+	if e.LowestPrice != 650 || e.Currency != "EUR" || e.FlightCount != 2 || e.TopItinerary == nil || e.TopItinerary.Airline != "Aegean" || !e.CheckedAt.Equal(now) {
+		t.Fatalf("unexpected history entry: %+v", e)
+	}
+}
+
+func TestWatchHistoryOutputs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}); err != nil {
+		t.Fatalf("create watch: %v", err)
+	}
+	id := onlyWatchID(t, stateDir)
+	h := watcher.HistoryStore{Dir: filepath.Join(stateDir, "history")}
+	now := time.Now().UTC()
+	for i, price := range []int{900, 850, 800} {
+		if err := h.Append(id, model.PriceHistoryEntry{CheckedAt: now.AddDate(0, 0, i-10).Add(time.Hour), LowestPrice: price, Currency: "USD", FlightCount: 4}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "history", "--id", id, "--limit", "2"})
+	})
+	if err != nil {
+		t.Fatalf("watch history plain: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if lines[0] != "checked_at\tlowest_price\tcurrency\tflight_count\tairline\tflight_number\tdepart_time\tarrive_time\tstops\tdepart\treturn\troute" {
+		t.Fatalf("unexpected header: %q", lines[0])
+	}
+	if len(lines) != 3 || !strings.Contains(lines[2], "\t800\t") {
+		t.Fatalf("expected two most recent rows, got %q", out)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--json", "--state-dir", stateDir, "watch", "history", "--id", id, "--since", "9d"})
+	})
+	if err != nil {
+		t.Fatalf("watch history json: %v", err)
+	}
+	var got watchHistoryOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("parse json: %v\n%s", err, out)
+	}
+	if got.WatchID != id || len(got.Entries) != 2 {
+		t.Fatalf("expected 2 entries since 9d, got %+v", got)
+	}
+
+	err = app.Run([]string{"--state-dir", stateDir, "watch", "history", "--id", id, "--since", "yesterday"})
+	if ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected invalid usage for bad --since, got %v", err)
+	}
+
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "delete", "--id", id, "--force"}); err != nil {
+		t.Fatalf("delete watch: %v", err)
+	}
+	if entries, _ := h.Load(id); len(entries) != 0 {
+		t.Fatalf("expected history removed with watch, got %d entries", len(entries))
+	}
+}
+
+func TestWatchRunsOutputs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	runLog := watcher.RunLogFile{Path: filepath.Join(stateDir, "runs.jsonl")}
+	now := time.Now().UTC()
+	for i := range 3 {
+		started := now.AddDate(0, 0, i-10).Add(time.Hour)
+		logRun(runLog, "run", started, started.Add(2*time.Second), watchRunReport{Evaluated: i + 1, Triggered: 1, Alerts: []model.Alert{{WatchID: "w_1"}}}, 0, io.Discard)
+	}
+
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "runs", "--limit", "2"})
+	})
+	if err != nil {
+		t.Fatalf("watch runs plain: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if lines[0] != "started_at\tfinished_at\tmode\tevaluated\ttriggered\tsuppressed\tprovider_failures\tnotify_failures\tskipped\tinterrupted" {
+		t.Fatalf("unexpected header: %q", lines[0])
+	}
+	if len(lines) != 3 || !strings.Contains(lines[2], "\trun\t3\t1\t") {
+		t.Fatalf("expected two most recent rows, got %q", out)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--json", "--state-dir", stateDir, "watch", "runs", "--since", "9d"})
+	})
+	if err != nil {
+		t.Fatalf("watch runs json: %v", err)
+	}
+	var got watchRunsOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("parse json: %v\n%s", err, out)
+	}
+	if len(got.Runs) != 2 || got.Runs[0].AlertWatchIDs[0] != "w_1" {
+		t.Fatalf("expected 2 runs since 9d, got %+v", got)
+	}
+
+	logRun(runLog, "daemon", now, now, watchRunReport{}, 5, io.Discard)
+	if runs, _ := runLog.Load(); len(runs) != 1 || runs[0].Mode != "daemon" {
+		t.Fatalf("expected runs past retention pruned, got %+v", runs)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"2026-02-01":           time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		"2026-02-01T10:00:00Z": time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC),
+		"7d":                   now.AddDate(0, 0, -7),
+		"12h":                  now.Add(-12 * time.Hour),
+	}
+	for in, want := range cases {
+		got, err := parseSince(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Fatalf("parseSince(%q) = %s, %v; want %s", in, got, err, want)
+		}
+	}
+}
+
+type fakeHistory struct {
+	entries   map[string][]model.PriceHistoryEntry
+	snapshots map[string]model.WatchSnapshot
+}
+
+func (f *fakeHistory) Load(watchID string) ([]model.PriceHistoryEntry, error) {
+	return append([]model.PriceHistoryEntry(nil), f.entries[watchID]...), nil
+}
+
+func (f *fakeHistory) Record(w model.Watch, entry model.PriceHistoryEntry) error {
+	if f.entries == nil {
+		f.entries = map[string][]model.PriceHistoryEntry{}
+	}
+	f.entries[w.ID] = append(f.entries[w.ID], entry)
+	return nil
+}
+
+func (f *fakeHistory) Snapshot(snap model.WatchSnapshot) error {
+	if f.snapshots == nil {
+		f.snapshots = map[string]model.WatchSnapshot{}
+	}
+	f.snapshots[snap.WatchID] = snap
+	return nil
+}
@@ -71,7 +71,7 @@ func TestWatchTestJSONKeepsStdoutClean(t *testing.T) {
 	stateDir := t.TempDir()
 	app := NewApp("test")
 
-	if err := app.Run([]string{"--state-dir", stateDir, "--json", "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2026-06-10", "--notify-terminal"}); err != nil {
+	if err := app.Run([]string{"--state-dir", stateDir, "--json", "watch", "create", "--name", "athens", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--notify-terminal"}); err != nil {
 		t.Fatalf("create watch: %v", err)
 	}
 
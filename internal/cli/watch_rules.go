@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+const (
+	ruleTargetPrice  = "target_price"
+	rulePriceDrop    = "price_drop"
+	rulePercentDrop  = "percent_drop"
+	ruleAllTimeLow   = "all_time_low"
+	ruleBelowAverage = "below_average"
+	ruleAbsoluteDrop = "absolute_drop"
+	ruleGoodDeal     = "good_deal"
+)
+
+var alertRuleTypes = []string{rulePercentDrop, ruleAllTimeLow, ruleBelowAverage, ruleAbsoluteDrop, ruleGoodDeal}
+
+// ruleFlags collects repeated --rule values.
+type ruleFlags []model.AlertRule
+
+func (r *ruleFlags) String() string {
+	parts := make([]string, 0, len(*r))
+	for _, rule := range *r {
+		parts = append(parts, formatAlertRule(rule))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (r *ruleFlags) Set(v string) error {
+	rule, err := parseAlertRule(v)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, rule)
+	return nil
+}
+
+// parseAlertRule accepts:
+//
+//	percent_drop=10%/7d     price fell at least 10% from the 7-day high
+//	all_time_low            lowest price ever recorded for the watch
+//	below_average=5%/10runs at least 5% below the average of the last 10 runs
+//	absolute_drop=50        at least 50 below the previous run
+//	good_deal               provider rates the price low, or it is under the typical range
+func parseAlertRule(v string) (model.AlertRule, error) {
+	name, params, _ := strings.Cut(strings.TrimSpace(v), "=")
+	name = strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	parts := []string{}
+	if params != "" {
+		parts = strings.Split(params, "/")
+	}
+	rule := model.AlertRule{Type: name}
+	switch name {
+	case rulePercentDrop:
+		if len(parts) != 2 {
+			return rule, fmt.Errorf("rule %q: use percent_drop=<pct>%%/<days>d (e.g. percent_drop=10%%/7d)", v)
+		}
+		pct, err := parseRulePercent(parts[0])
+		if err != nil {
+			return rule, fmt.Errorf("rule %q: %v", v, err)
+		}
+		days, err := parseRuleCount(parts[1], "d")
+		if err != nil {
+			return rule, fmt.Errorf("rule %q: days %v", v, err)
+		}
+		rule.Percent, rule.Days = pct, days
+	case ruleAllTimeLow, ruleGoodDeal:
+		if len(parts) != 0 {
+			return rule, fmt.Errorf("rule %q: %s takes no parameters", v, name)
+		}
+	case ruleBelowAverage:
+		if len(parts) != 2 {
+			return rule, fmt.Errorf("rule %q: use below_average=<pct>%%/<runs>runs (e.g. below_average=5%%/10runs)", v)
+		}
+		pct, err := parseRulePercent(parts[0])
+		if err != nil {
+			return rule, fmt.Errorf("rule %q: %v", v, err)
+		}
+		runs, err := parseRuleCount(parts[1], "runs")
+		if err != nil || runs < 2 {
+			return rule, fmt.Errorf("rule %q: runs must be an integer >= 2", v)
+		}
+		rule.Percent, rule.Runs = pct, runs
+	case ruleAbsoluteDrop:
+		if len(parts) != 1 {
+			return rule, fmt.Errorf("rule %q: use absolute_drop=<amount> (e.g. absolute_drop=50)", v)
+		}
+		amount, err := parseRuleCount(parts[0], "")
+		if err != nil {
+			return rule, fmt.Errorf("rule %q: amount %v", v, err)
+		}
+		rule.Amount = amount
+	default:
+		msg := fmt.Sprintf("unknown rule %q (use %s)", name, strings.Join(alertRuleTypes, ", "))
+		if s := suggestClosest(name, alertRuleTypes); s != "" {
+			msg = fmt.Sprintf("unknown rule %q (did you mean %q?)", name, s)
+		}
+		return rule, fmt.Errorf("%s", msg)
+	}
+	return rule, nil
+}
+
+func parseRulePercent(v string) (float64, error) {
+	n, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+	if err != nil || n <= 0 || n >= 100 {
+		return 0, fmt.Errorf("percent must be between 0 and 100 (exclusive)")
+	}
+	return n, nil
+}
+
+func parseRuleCount(v, suffix string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSuffix(v, suffix))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("must be a positive integer")
+	}
+	return n, nil
+}
+
+func formatAlertRule(r model.AlertRule) string {
+	switch r.Type {
+	case rulePercentDrop:
+		return fmt.Sprintf("%s=%s%%/%dd", r.Type, strconv.FormatFloat(r.Percent, 'f', -1, 64), r.Days)
+	case ruleBelowAverage:
+		return fmt.Sprintf("%s=%s%%/%druns", r.Type, strconv.FormatFloat(r.Percent, 'f', -1, 64), r.Runs)
+	case ruleAbsoluteDrop:
+		return fmt.Sprintf("%s=%d", r.Type, r.Amount)
+	default:
+		return r.Type
+	}
+}
+
+type ruleHit struct {
+	Rule   string
+	Reason string
+}
+
+// evaluateAlertRules checks the watch's rules against the current lowest
+// price. history holds earlier observations only, oldest first; insights may
+// be nil. Without configured rules the legacy "any drop since last run" rule
+// applies.
+func evaluateAlertRules(w model.Watch, lowest int, history []model.PriceHistoryEntry, insights *model.PriceInsights, now time.Time) []ruleHit {
+	hits := []ruleHit{}
+	if lowest <= 0 {
+		return hits
+	}
+	if w.TargetPrice > 0 && lowest <= w.TargetPrice {
+		hits = append(hits, ruleHit{ruleTargetPrice, fmt.Sprintf("price reached target <= %d", w.TargetPrice)})
+	}
+	if len(w.Rules) == 0 {
+		if w.LastLowestPrice > 0 && lowest < w.LastLowestPrice {
+			hits = append(hits, ruleHit{rulePriceDrop, fmt.Sprintf("price dropped from %d to %d", w.LastLowestPrice, lowest)})
+		}
+		return hits
+	}
+	prices := pricedHistory(history)
+	for _, rule := range w.Rules {
+		switch rule.Type {
+		case rulePercentDrop:
+			since := now.AddDate(0, 0, -rule.Days)
+			high := 0
+			for _, e := range prices {
+				if !e.CheckedAt.Before(since) && e.LowestPrice > high {
+					high = e.LowestPrice
+				}
+			}
+			if high == 0 {
+				continue
+			}
+			drop := float64(high-lowest) / float64(high) * 100
+			if drop >= rule.Percent {
+				hits = append(hits, ruleHit{rule.Type, fmt.Sprintf("price fell %.1f%% from %d-day high %d to %d", drop, rule.Days, high, lowest)})
+			}
+		case ruleAllTimeLow:
+			if len(prices) == 0 {
+				continue
+			}
+			low := prices[0].LowestPrice
+			for _, e := range prices[1:] {
+				if e.LowestPrice < low {
+					low = e.LowestPrice
+				}
+			}
+			if lowest < low {
+				hits = append(hits, ruleHit{rule.Type, fmt.Sprintf("new all-time low %d (previous low %d)", lowest, low)})
+			}
+		case ruleBelowAverage:
+			if len(prices) < rule.Runs {
+				continue
+			}
+			sum := 0
+			for _, e := range prices[len(prices)-rule.Runs:] {
+				sum += e.LowestPrice
+			}
+			avg := float64(sum) / float64(rule.Runs)
+			below := (avg - float64(lowest)) / avg * 100
+			if below >= rule.Percent {
+				hits = append(hits, ruleHit{rule.Type, fmt.Sprintf("price %d is %.1f%% below the %d-run average %.0f", lowest, below, rule.Runs, avg)})
+			}
+		case ruleAbsoluteDrop:
+			if w.LastLowestPrice > 0 && w.LastLowestPrice-lowest >= rule.Amount {
+				hits = append(hits, ruleHit{rule.Type, fmt.Sprintf("price dropped by %d from %d to %d", w.LastLowestPrice-lowest, w.LastLowestPrice, lowest)})
+			}
+		case ruleGoodDeal:
+			if insights == nil {
+				continue
+			}
+			switch {
+			case insights.TypicalLow > 0 && lowest < insights.TypicalLow:
+				hits = append(hits, ruleHit{rule.Type, fmt.Sprintf("price %d is under the typical range %d-%d", lowest, insights.TypicalLow, insights.TypicalHigh)})
+			case insights.PriceLevel == model.PriceLevelLow:
+				hits = append(hits, ruleHit{rule.Type, fmt.Sprintf("price %d is rated low for this route", lowest)})
+			}
+		}
+	}
+	return hits
+}
+
+func pricedHistory(history []model.PriceHistoryEntry) []model.PriceHistoryEntry {
+	out := make([]model.PriceHistoryEntry, 0, len(history))
+	for _, e := range history {
+		if e.LowestPrice > 0 {
+			out = append(out, e)
+		}
+	}
+	return out
+}
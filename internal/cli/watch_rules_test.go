@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestParseAlertRule(t *testing.T) {
+	cases := []struct {
+		in   string
+		want model.AlertRule
+	}{
+		{in: "percent_drop=10%/7d", want: model.AlertRule{Type: rulePercentDrop, Percent: 10, Days: 7}},
+		{in: "percent-drop=12.5/3d", want: model.AlertRule{Type: rulePercentDrop, Percent: 12.5, Days: 3}},
+		{in: "all_time_low", want: model.AlertRule{Type: ruleAllTimeLow}},
+		{in: "below_average=5%/10runs", want: model.AlertRule{Type: ruleBelowAverage, Percent: 5, Runs: 10}},
+		{in: "absolute_drop=50", want: model.AlertRule{Type: ruleAbsoluteDrop, Amount: 50}},
+		{in: "good_deal", want: model.AlertRule{Type: ruleGoodDeal}},
+	}
+	for _, tc := range cases {
+		got, err := parseAlertRule(tc.in)
+		if err != nil {
+			t.Fatalf("parseAlertRule(%q): %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Fatalf("parseAlertRule(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+		if round, err := parseAlertRule(formatAlertRule(got)); err != nil || round != got {
+			t.Fatalf("format/parse round trip failed for %+v: %+v %v", got, round, err)
+		}
+	}
+
+	for _, bad := range []string{"percent_drop=10%", "percent_drop=150%/7d", "all_time_low=1", "below_average=5%/1runs", "absolute_drop=-3", "alltime_low", "good_deal=5"} {
+		if _, err := parseAlertRule(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+	if _, err := parseAlertRule("all_time_lo"); err == nil || !strings.Contains(err.Error(), `did you mean "all_time_low"`) {
+		t.Fatalf("expected suggestion, got %v", err)
+	}
+}
+
+func TestEvaluateAlertRules(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	history := []model.PriceHistoryEntry{
+		{CheckedAt: now.AddDate(0, 0, -10), LowestPrice: 1200},
+		{CheckedAt: now.AddDate(0, 0, -6), LowestPrice: 1000},
+		{CheckedAt: now.AddDate(0, 0, -4), LowestPrice: 0},
+		{CheckedAt: now.AddDate(0, 0, -2), LowestPrice: 950},
+		{CheckedAt: now.AddDate(0, 0, -1), LowestPrice: 900},
+	}
+	cases := []struct {
+		name     string
+		rule     model.AlertRule
+		price    int
+		last     int
+		insights *model.PriceInsights
+		fire     bool
+	}{
+		{name: "percent drop within window fires", rule: model.AlertRule{Type: rulePercentDrop, Percent: 10, Days: 7}, price: 890, fire: true},
+		{name: "percent drop ignores older high", rule: model.AlertRule{Type: rulePercentDrop, Percent: 20, Days: 7}, price: 890, fire: false},
+		{name: "all time low fires", rule: model.AlertRule{Type: ruleAllTimeLow}, price: 899, fire: true},
+		{name: "all time low needs strictly lower", rule: model.AlertRule{Type: ruleAllTimeLow}, price: 900, fire: false},
+		{name: "below average fires", rule: model.AlertRule{Type: ruleBelowAverage, Percent: 5, Runs: 3}, price: 900, fire: true},
+		{name: "below average needs enough runs", rule: model.AlertRule{Type: ruleBelowAverage, Percent: 5, Runs: 5}, price: 500, fire: false},
+		{name: "absolute drop fires", rule: model.AlertRule{Type: ruleAbsoluteDrop, Amount: 50}, price: 850, last: 900, fire: true},
+		{name: "absolute drop ignores one-unit moves", rule: model.AlertRule{Type: ruleAbsoluteDrop, Amount: 50}, price: 899, last: 900, fire: false},
+		{name: "good deal fires under typical range", rule: model.AlertRule{Type: ruleGoodDeal}, price: 690, insights: &model.PriceInsights{PriceLevel: "typical", TypicalLow: 700, TypicalHigh: 900}, fire: true},
+		{name: "good deal fires on low price level", rule: model.AlertRule{Type: ruleGoodDeal}, price: 750, insights: &model.PriceInsights{PriceLevel: "low", TypicalLow: 700, TypicalHigh: 900}, fire: true},
+		{name: "good deal ignores typical prices", rule: model.AlertRule{Type: ruleGoodDeal}, price: 750, insights: &model.PriceInsights{PriceLevel: "typical", TypicalLow: 700, TypicalHigh: 900}, fire: false},
+		{name: "good deal needs insights", rule: model.AlertRule{Type: ruleGoodDeal}, price: 100, fire: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := model.Watch{Rules: []model.AlertRule{tc.rule}, LastLowestPrice: tc.last}
+			hits := evaluateAlertRules(w, tc.price, history, tc.insights, now)
+			if (len(hits) > 0) != tc.fire {
+				t.Fatalf("expected fire=%t, got %+v", tc.fire, hits)
+			}
+			if tc.fire && hits[0].Rule != tc.rule.Type {
+				t.Fatalf("expected hit for %s, got %+v", tc.rule.Type, hits)
+			}
+		})
+	}
+}
+
+func TestEvaluateWatchResultWithRulesSuppressesLegacyDrop(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	w := model.Watch{ID: "w1", LastLowestPrice: 900, Rules: []model.AlertRule{{Type: ruleAbsoluteDrop, Amount: 50}}}
+	res := model.SearchResult{Flights: []model.Flight{{Price: 899, Currency: "USD"}}}
+	if _, ok := evaluateWatchResult(&w, res, nil, now); ok {
+		t.Fatalf("expected one-unit drop not to alert when rules are configured")
+	}
+
+	w = model.Watch{ID: "w1", LastLowestPrice: 900, TargetPrice: 860, Rules: []model.AlertRule{{Type: ruleAbsoluteDrop, Amount: 50}}}
+	res.Flights[0].Price = 850
+	alert, ok := evaluateWatchResult(&w, res, nil, now)
+	if !ok {
+		t.Fatalf("expected alert")
+	}
+	if len(alert.Rules) != 2 || alert.Rules[0] != ruleTargetPrice || alert.Rules[1] != ruleAbsoluteDrop {
+		t.Fatalf("unexpected fired rules: %v", alert.Rules)
+	}
+	if !strings.Contains(alert.Reason, "target_price:") || !strings.Contains(alert.Reason, "absolute_drop:") {
+		t.Fatalf("expected reason to name fired rules, got %q", alert.Reason)
+	}
+}
+
+func TestWatchCreateParsesRules(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	base := []string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}
+	if err := app.Run(append(base, "--rule", "bogus")); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected invalid usage for unknown rule, got %v", err)
+	}
+	if err := app.Run(append(base, "--rule", "all_time_low", "--rule", "absolute_drop=40")); err != nil {
+		t.Fatalf("create with rules: %v", err)
+	}
+	w := onlyWatch(t, stateDir)
+	if len(w.Rules) != 2 || w.Rules[1].Amount != 40 {
+		t.Fatalf("unexpected stored rules: %+v", w.Rules)
+	}
+}
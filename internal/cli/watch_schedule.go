@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/schedule"
+)
+
+const minCheckInterval = time.Minute
+
+func parseCheckInterval(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < minCheckInterval {
+		return 0, fmt.Errorf("must be a duration of at least %s (e.g. 30m)", minCheckInterval)
+	}
+	return d, nil
+}
+
+func watchCheckInterval(w model.Watch, fallback time.Duration) time.Duration {
+	if w.CheckInterval == "" {
+		return fallback
+	}
+	d, err := parseCheckInterval(w.CheckInterval)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// watchNextDue returns when a watch last run at last should run again. A cron
+// schedule takes precedence over the check interval and is evaluated in the
+// local time zone. Watches that never ran are due immediately (zero time).
+// ok is false when the schedule never fires again, so the watch is never due.
+func watchNextDue(w model.Watch, last time.Time, fallback time.Duration) (next time.Time, ok bool) {
+	if last.IsZero() {
+		return time.Time{}, true
+	}
+	if w.Schedule != "" {
+		if s, err := schedule.Parse(w.Schedule); err == nil {
+			next = s.Next(last.In(time.Local))
+			return next.UTC(), !next.IsZero()
+		}
+	}
+	return last.Add(watchCheckInterval(w, fallback)).UTC(), true
+}
+
+func isWatchDue(w model.Watch, now time.Time, fallback time.Duration) bool {
+	next, ok := watchNextDue(w, w.LastRunAt, fallback)
+	return ok && !next.After(now)
+}
+
+// validateSchedule rejects cron expressions that do not parse or never fire,
+// such as "0 0 30 2 *".
+func validateSchedule(expr string) error {
+	s, err := schedule.Parse(expr)
+	if err != nil {
+		return err
+	}
+	if s.Next(time.Now()).IsZero() {
+		return fmt.Errorf("cron schedule %q never fires", expr)
+	}
+	return nil
+}
+
+func watchScheduleLabel(w model.Watch, defaultInterval string) string {
+	switch {
+	case w.Schedule != "":
+		return w.Schedule
+	case w.CheckInterval != "":
+		return "every " + w.CheckInterval
+	default:
+		return "every " + defaultInterval
+	}
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestWatchNextDuePrefersSchedule(t *testing.T) {
+	last := time.Date(2026, 2, 19, 22, 7, 0, 0, time.UTC)
+	w := model.Watch{Schedule: "0 */4 * * *", CheckInterval: "10m"}
+	got, _ := watchNextDue(w, last, time.Hour)
+	local := got.In(time.Local)
+	if !got.After(last) || got.Sub(last) > 4*time.Hour || local.Minute() != 0 || local.Hour()%4 != 0 {
+		t.Fatalf("unexpected schedule next due: %s", got)
+	}
+	w.Schedule = ""
+	if got, _ := watchNextDue(w, last, time.Hour); !got.Equal(last.Add(10 * time.Minute)) {
+		t.Fatalf("expected interval next due, got %s", got)
+	}
+	w.CheckInterval = ""
+	if got, _ := watchNextDue(w, last, time.Hour); !got.Equal(last.Add(time.Hour)) {
+		t.Fatalf("expected default interval next due, got %s", got)
+	}
+	if got, ok := watchNextDue(w, time.Time{}, time.Hour); !ok || !got.IsZero() {
+		t.Fatalf("expected never-run watch to be due immediately, got %s", got)
+	}
+}
+
+func TestIsWatchDue(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		w    model.Watch
+		want bool
+	}{
+		{name: "never run", w: model.Watch{}, want: true},
+		{name: "interval elapsed", w: model.Watch{LastRunAt: now.Add(-20 * time.Minute), CheckInterval: "15m"}, want: true},
+		{name: "interval pending", w: model.Watch{LastRunAt: now.Add(-5 * time.Minute), CheckInterval: "15m"}, want: false},
+		{name: "hourly fired since last run", w: model.Watch{LastRunAt: now.Add(-61 * time.Minute), Schedule: "@hourly"}, want: true},
+		{name: "yearly not yet fired", w: model.Watch{LastRunAt: now.Add(-time.Minute), Schedule: "@yearly"}, want: false},
+		{name: "schedule never fires", w: model.Watch{LastRunAt: now.Add(-time.Hour), Schedule: "0 0 30 2 *"}, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isWatchDue(tc.w, now, time.Hour); got != tc.want {
+				t.Fatalf("isWatchDue() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWatchCreateValidatesSchedule(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	base := []string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10"}
+
+	err := app.Run(append(base, "--schedule", "0 */4 * *"))
+	if ExitCode(err) != ExitInvalidUsage || !strings.Contains(err.Error(), "invalid cron schedule") {
+		t.Fatalf("expected invalid cron schedule usage error, got err=%v", err)
+	}
+	for _, never := range []string{"0 0 30 2 *", "0 0 31 2 *"} {
+		err = app.Run(append(base, "--schedule", never))
+		if ExitCode(err) != ExitInvalidUsage || !strings.Contains(err.Error(), "never fires") {
+			t.Fatalf("expected %q rejected as never firing, got err=%v", never, err)
+		}
+	}
+	err = app.Run(append(base, "--schedule", "@hourly", "--check-interval", "30m"))
+	if ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected mutually exclusive usage error, got err=%v", err)
+	}
+	if err := app.Run(append(base, "--schedule", "@hourly")); err != nil {
+		t.Fatalf("create with schedule: %v", err)
+	}
+	if w := onlyWatch(t, stateDir); w.Schedule != "@hourly" {
+		t.Fatalf("expected schedule to be stored, got %q", w.Schedule)
+	}
+}
+
+func TestWatchRunDueSkipsWatchesNotYetDue(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	if err := app.Run([]string{"auth", "login", "--provider", "google-url"}); err != nil {
+		t.Fatalf("auth login: %v", err)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "create", "--from", "SFO", "--to", "ATH", "--depart", "2030-06-10", "--schedule", "@yearly"}); err != nil {
+		t.Fatalf("create watch: %v", err)
+	}
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "run", "--due"})
+	})
+	if err != nil || !strings.Contains(out, "evaluated=1") {
+		t.Fatalf("expected never-run watch to be evaluated, got out=%q err=%v", out, err)
+	}
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "run", "--due"})
+	})
+	if err != nil || !strings.Contains(out, "evaluated=0") {
+		t.Fatalf("expected watch to be skipped until next fire, got out=%q err=%v", out, err)
+	}
+}
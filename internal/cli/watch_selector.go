@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/agisilaos/gflight/internal/airports"
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+// stringsFlag collects repeated string flag values.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// watchSelector picks watches by ID, tags, name glob and route. Every set
+// criterion must match; All matches every watch.
+type watchSelector struct {
+	ID       string
+	All      bool
+	Tags     stringsFlag
+	NameGlob string
+	Route    string
+}
+
+func addWatchSelectorFlags(fs *flag.FlagSet, sel *watchSelector) {
+	fs.StringVar(&sel.ID, "id", "", "Watch ID")
+	fs.Var(&sel.Tags, "tag", "Select watches with this tag (repeatable; all must match)")
+	fs.StringVar(&sel.NameGlob, "name-glob", "", "Select watches whose name matches this glob (e.g. \"summer-*\")")
+	fs.StringVar(&sel.Route, "route", "", "Select watches by route FROM-TO (e.g. SFO-ATH, SFO-*)")
+}
+
+func (s watchSelector) filtered() bool {
+	return len(s.Tags) > 0 || s.NameGlob != "" || s.Route != ""
+}
+
+func (s watchSelector) empty() bool {
+	return s.ID == "" && !s.All && !s.filtered()
+}
+
+func (s watchSelector) validate() error {
+	if s.All && (s.ID != "" || s.filtered()) {
+		return newExitError(ExitInvalidUsage, "--all cannot be combined with --id, --tag, --name-glob, or --route")
+	}
+	if s.NameGlob != "" {
+		if _, err := path.Match(s.NameGlob, ""); err != nil {
+			return newExitError(ExitInvalidUsage, "invalid --name-glob %q: %v", s.NameGlob, err)
+		}
+	}
+	if s.Route != "" {
+		from, to, ok := strings.Cut(s.Route, "-")
+		if !ok || from == "" || to == "" {
+			return newExitError(ExitInvalidUsage, "invalid --route %q (use FROM-TO, e.g. SFO-ATH or SFO-*)", s.Route)
+		}
+	}
+	for _, tag := range s.Tags {
+		if err := validateTag(tag); err != nil {
+			return newExitError(ExitInvalidUsage, "--tag %v", err)
+		}
+	}
+	return nil
+}
+
+func (s watchSelector) matches(w model.Watch) bool {
+	if s.All {
+		return true
+	}
+	if s.empty() {
+		return false
+	}
+	if s.ID != "" && w.ID != s.ID {
+		return false
+	}
+	for _, tag := range s.Tags {
+		if !slices.Contains(w.Tags, tag) {
+			return false
+		}
+	}
+	if s.NameGlob != "" {
+		if ok, _ := path.Match(s.NameGlob, w.Name); !ok {
+			return false
+		}
+	}
+	if s.Route != "" && !routeMatches(s.Route, w.Query) {
+		return false
+	}
+	return true
+}
+
+func (s watchSelector) describe() string {
+	parts := []string{}
+	if s.ID != "" {
+		parts = append(parts, "id="+s.ID)
+	}
+	for _, tag := range s.Tags {
+		parts = append(parts, "tag="+tag)
+	}
+	if s.NameGlob != "" {
+		parts = append(parts, "name-glob="+s.NameGlob)
+	}
+	if s.Route != "" {
+		parts = append(parts, "route="+s.Route)
+	}
+	return strings.Join(parts, " ")
+}
+
+// routeMatches reports whether any airport pair the query searches fits the
+// FROM-TO pattern. Metro codes on either side stand for their airports, so
+// SFO-ATH selects a SFO,OAK watch and JFK-ATH or NYC-ATH a NYC watch.
+func routeMatches(pattern string, q model.SearchQuery) bool {
+	from, to, _ := strings.Cut(pattern, "-")
+	for _, r := range routeQueries(q) {
+		if routeCodeMatches(from, r.From) && routeCodeMatches(to, r.To) {
+			return true
+		}
+	}
+	return false
+}
+
+func routeCodeMatches(pattern, code string) bool {
+	if pattern == "*" {
+		return true
+	}
+	return slices.Contains(airports.Expand(model.SplitCodes(pattern)), code)
+}
+
+func validateTag(tag string) error {
+	if tag == "" || strings.ContainsAny(tag, " \t\n,") {
+		return fmt.Errorf("invalid tag %q (tags must be non-empty and contain no spaces or commas)", tag)
+	}
+	return nil
+}
+
+// normalizeTags trims and de-duplicates tags, keeping their order.
+func normalizeTags(tags []string) []string {
+	out := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
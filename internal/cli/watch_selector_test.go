@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestWatchSelectorMatches(t *testing.T) {
+	w := model.Watch{ID: "w1", Name: "summer-athens", Tags: []string{"team:growth", "trip:summer"}, Query: model.SearchQuery{From: "SFO", To: "ATH"}}
+	cases := []struct {
+		sel  watchSelector
+		want bool
+	}{
+		{watchSelector{All: true}, true},
+		{watchSelector{}, false},
+		{watchSelector{ID: "w1"}, true},
+		{watchSelector{Tags: stringsFlag{"team:growth"}}, true},
+		{watchSelector{Tags: stringsFlag{"team:growth", "trip:winter"}}, false},
+		{watchSelector{NameGlob: "summer-*"}, true},
+		{watchSelector{NameGlob: "winter-*"}, false},
+		{watchSelector{Route: "sfo-ath"}, true},
+		{watchSelector{Route: "SFO-*"}, true},
+		{watchSelector{Route: "OAK-ATH"}, false},
+		{watchSelector{ID: "w2", Tags: stringsFlag{"team:growth"}}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.sel.matches(w); got != tc.want {
+			t.Fatalf("%+v.matches = %t, want %t", tc.sel, got, tc.want)
+		}
+	}
+	multi := model.Watch{Query: model.SearchQuery{From: "SFO,OAK", To: "ATH"}}
+	metro := model.Watch{Query: model.SearchQuery{From: "NYC", To: "ATH"}}
+	for _, tc := range []struct {
+		w     model.Watch
+		route string
+		want  bool
+	}{
+		{multi, "SFO-ATH", true},
+		{multi, "oak-ath", true},
+		{multi, "*-ATH", true},
+		{multi, "SJC-ATH", false},
+		{metro, "JFK-ATH", true},
+		{metro, "NYC-ATH", true},
+		{metro, "NYC-*", true},
+		{w, "NYC-ATH", false},
+	} {
+		if got := (watchSelector{Route: tc.route}).matches(tc.w); got != tc.want {
+			t.Fatalf("route %s matches %s-%s = %t, want %t", tc.route, tc.w.Query.From, tc.w.Query.To, got, tc.want)
+		}
+	}
+	for _, bad := range []watchSelector{{All: true, ID: "w1"}, {Route: "SFO"}, {NameGlob: "["}, {Tags: stringsFlag{"two words"}}} {
+		if err := bad.validate(); ExitCode(err) != ExitInvalidUsage {
+			t.Fatalf("expected %+v to be rejected, got %v", bad, err)
+		}
+	}
+}
+
+func TestWatchCommandsAcceptSelectors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateDir := t.TempDir()
+	app := NewApp("test")
+	create := func(name, to string, tags ...string) {
+		t.Helper()
+		args := []string{"--state-dir", stateDir, "watch", "create", "--name", name, "--from", "SFO", "--to", to, "--depart", "2030-06-10"}
+		for _, tag := range tags {
+			args = append(args, "--tag", tag)
+		}
+		if err := app.Run(args); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+	}
+	create("summer-athens", "ATH", "team:growth", "trip:summer")
+	create("summer-rome", "FCO", "trip:summer", "trip:summer")
+	create("winter-tokyo", "HND", "team:growth")
+
+	out, err := captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "disable", "--tag", "trip:summer"})
+	})
+	if err != nil {
+		t.Fatalf("disable by tag: %v", err)
+	}
+	if strings.Count(out, "enabled=false") != 2 {
+		t.Fatalf("expected two disabled watches, got %q", out)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--json", "--state-dir", stateDir, "watch", "list", "--name-glob", "summer-*"})
+	})
+	if err != nil {
+		t.Fatalf("list by name glob: %v", err)
+	}
+	var items []watchListItem
+	if err := json.Unmarshal([]byte(out), &items); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(items) != 2 || items[0].Enabled || items[1].Enabled {
+		t.Fatalf("unexpected filtered list: %+v", items)
+	}
+	for _, item := range items {
+		if item.Name == "summer-rome" && strings.Join(item.Tags, ",") != "trip:summer" {
+			t.Fatalf("expected duplicate tags collapsed, got %v", item.Tags)
+		}
+	}
+
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "enable", "--route", "SFO-XXX"}); ExitCode(err) != ExitNoMatches {
+		t.Fatalf("expected no-matches exit code, got %v", err)
+	}
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "delete", "--route", "SFO-HND"}); ExitCode(err) != ExitInvalidUsage {
+		t.Fatalf("expected selector delete to require --force, got %v", err)
+	}
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "delete", "--route", "SFO-HND", "--force"})
+	})
+	if err != nil || !strings.HasPrefix(out, "deleted_id=w_") {
+		t.Fatalf("delete by route: out=%q err=%v", out, err)
+	}
+
+	out, err = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--plain", "--state-dir", stateDir, "watch", "list", "--tag", "team:growth"})
+	})
+	if err != nil {
+		t.Fatalf("list by tag: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "summer-athens") {
+		t.Fatalf("unexpected list by tag: %q", out)
+	}
+	id := strings.Split(lines[1], "\t")[0]
+	if err := app.Run([]string{"--state-dir", stateDir, "watch", "update", "--id", id, "--clear-tags"}); err != nil {
+		t.Fatalf("clear tags: %v", err)
+	}
+	out, _ = captureStdoutForRun(t, func() error {
+		return app.Run([]string{"--state-dir", stateDir, "watch", "list", "--tag", "team:growth"})
+	})
+	if !strings.HasPrefix(out, "No watches match tag=team:growth") {
+		t.Fatalf("expected no matches after clearing tags, got %q", out)
+	}
+}
@@ -1,22 +1,44 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/provider"
+	"github.com/agisilaos/gflight/internal/watcher"
 )
 
-type watchSearchFunc func(model.SearchQuery) (model.SearchResult, error)
+// defaultWatchRequests bounds the provider requests, lookups included, one
+// watch spends per run unless it sets --max-requests. It is 31 rather than
+// defaultCalendarRequests so a one-way window can cover a whole month.
+const defaultWatchRequests = 31
+
+type watchSearchFunc func(context.Context, model.SearchQuery) (model.SearchResult, error)
 type watchNotifyFunc func(model.Watch, model.Alert) error
 
+type watchHistory interface {
+	Load(watchID string) ([]model.PriceHistoryEntry, error)
+	Record(w model.Watch, entry model.PriceHistoryEntry) error
+	Snapshot(snap model.WatchSnapshot) error
+}
+
 type watchRunReport struct {
-	Evaluated        int           `json:"evaluated"`
-	Triggered        int           `json:"triggered"`
-	ProviderFailures int           `json:"provider_failures"`
-	NotifyFailures   int           `json:"notify_failures"`
-	Alerts           []model.Alert `json:"alerts"`
+	Evaluated        int               `json:"evaluated"`
+	Triggered        int               `json:"triggered"`
+	Suppressed       int               `json:"suppressed"`
+	ProviderFailures int               `json:"provider_failures"`
+	NotifyFailures   int               `json:"notify_failures"`
+	Skipped          int               `json:"skipped"`
+	SkippedWatchIDs  []string          `json:"skipped_watch_ids,omitempty"`
+	Interrupted      bool              `json:"interrupted"`
+	Alerts           []model.Alert     `json:"alerts"`
+	SuppressedAlerts []suppressedAlert `json:"suppressed_alerts"`
 }
 
 func runWatchPass(
@@ -28,64 +50,287 @@ func runWatchPass(
 	now time.Time,
 	verbose bool,
 	errw io.Writer,
+) (watchRunReport, []string) {
+	selected := func(w model.Watch) bool { return shouldRunWatch(w, watchID, runAll) }
+	return runWatchPassSelected(context.Background(), watches, selected, search, notify, nil, 1, now, verbose, errw)
+}
+
+// runWatchPassSelected evaluates the selected watches. Provider searches run
+// on up to concurrency workers, but results are applied strictly in watch
+// order on the calling goroutine, so the report, alerts, notifications and
+// state updates do not depend on which search finishes first. Once ctx is
+// done no new searches start; watches whose search did not complete are
+// counted as skipped and left untouched.
+func runWatchPassSelected(
+	ctx context.Context,
+	watches []model.Watch,
+	selected func(model.Watch) bool,
+	search watchSearchFunc,
+	notify watchNotifyFunc,
+	history watchHistory,
+	concurrency int,
+	now time.Time,
+	verbose bool,
+	errw io.Writer,
 ) (watchRunReport, []string) {
 	report := watchRunReport{
-		Alerts: make([]model.Alert, 0),
+		Alerts:           make([]model.Alert, 0),
+		SuppressedAlerts: make([]suppressedAlert, 0),
 	}
 	notifyErrs := make([]string, 0)
 
+	picked := make([]int, 0, len(watches))
+	queries := make([]model.SearchQuery, 0, len(watches))
 	for i := range watches {
+		if selected(watches[i]) {
+			picked = append(picked, i)
+			queries = append(queries, watches[i].Query)
+		}
+	}
+	outcomes := searchConcurrently(ctx, queries, cheapestDateSearch(multiRouteSearch(search, 1), now), concurrency)
+
+	for k, i := range picked {
 		w := &watches[i]
-		if !shouldRunWatch(*w, watchID, runAll) {
+		<-outcomes[k].done
+		res, err := outcomes[k].result, outcomes[k].err
+		if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			report.Skipped++
+			report.SkippedWatchIDs = append(report.SkippedWatchIDs, w.ID)
+			report.Interrupted = true
 			continue
 		}
 		report.Evaluated++
-		res, err := search(w.Query)
+		snap := model.WatchSnapshot{WatchID: w.ID, CheckedAt: now.UTC()}
 		if err != nil {
 			report.ProviderFailures++
 			if verbose && errw != nil {
 				fmt.Fprintf(errw, "watch %s failed: %v\n", w.ID, err)
 			}
+			snap.Error = err.Error()
+			saveWatchSnapshot(history, snap, errw)
 			continue
 		}
-		alert, triggered := evaluateWatchResult(w, res, now)
-		if !triggered {
-			continue
+		if verbose && errw != nil {
+			for _, warning := range res.Warnings {
+				fmt.Fprintf(errw, "watch %s: %s\n", w.ID, warning)
+			}
 		}
-		report.Triggered++
-		report.Alerts = append(report.Alerts, alert)
-		if err := notify(*w, alert); err != nil {
-			notifyErrs = append(notifyErrs, err.Error())
-			report.NotifyFailures++
+		snap.Result = &res
+		var prior []model.PriceHistoryEntry
+		if history != nil {
+			loaded, err := history.Load(w.ID)
+			if err != nil && errw != nil {
+				fmt.Fprintf(errw, "watch %s history not loaded: %v\n", w.ID, err)
+			}
+			prior = loaded
+			if err := history.Record(*w, historyEntryFromResult(*w, res, now)); err != nil && errw != nil {
+				fmt.Fprintf(errw, "watch %s history not recorded: %v\n", w.ID, err)
+			}
 		}
+		alert, triggered := evaluateWatchResult(w, res, prior, now)
+		lowest, _ := lowestFare(res)
+		if suppressedBy := applyAlertState(w, triggered, lowest, now); suppressedBy != "" {
+			report.Suppressed++
+			report.SuppressedAlerts = append(report.SuppressedAlerts, suppressedAlert{Alert: alert, SuppressedBy: suppressedBy})
+			snap.Alert, snap.SuppressedBy = &alert, suppressedBy
+		} else if triggered {
+			report.Triggered++
+			report.Alerts = append(report.Alerts, alert)
+			snap.Alert = &alert
+			if err := notify(*w, alert); err != nil {
+				notifyErrs = append(notifyErrs, err.Error())
+				report.NotifyFailures++
+			}
+		}
+		saveWatchSnapshot(history, snap, errw)
 	}
 	return report, notifyErrs
 }
 
-func shouldRunWatch(w model.Watch, watchID string, runAll bool) bool {
-	if !w.Enabled {
-		return false
+// cheapestDateSearch searches flexible queries one date combination at a time
+// and returns the cheapest result, whose Query carries the winning dates.
+// Departure dates before now are dropped, and once none are left the window
+// has expired. Failed dates become warnings; the search fails only when every
+// date failed, auth is missing or ctx is done. Fixed-date queries pass
+// straight through.
+func cheapestDateSearch(search watchSearchFunc, now time.Time) watchSearchFunc {
+	today := utcDate(now)
+	return func(ctx context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		if !q.Flexible() {
+			return search(ctx, q)
+		}
+		g, err := queryGrid(q)
+		if err != nil {
+			return model.SearchResult{}, err
+		}
+		// validateWatch rejects --booking on date windows; older watches must
+		// not spend booking lookups on every date either.
+		q.Booking = false
+		var (
+			best      model.SearchResult
+			bestPrice int
+			found     bool
+			warnings  []string
+			firstErr  error
+		)
+		cells := g.queries(q)
+		cells = slices.DeleteFunc(cells, func(cell model.SearchQuery) bool { return cell.Depart < today })
+		if len(cells) == 0 {
+			return model.SearchResult{}, fmt.Errorf("date window %s expired", formatDateWindow(q))
+		}
+		for _, cell := range cells {
+			res, err := search(ctx, cell)
+			if err != nil {
+				if ctx.Err() != nil || errors.Is(err, provider.ErrAuthRequired) {
+					return model.SearchResult{}, err
+				}
+				if firstErr == nil {
+					firstErr = err
+				}
+				warnings = append(warnings, fmt.Sprintf("dates %s: %v", formatDateWindow(cell), err))
+				continue
+			}
+			res.Query = cell
+			price, _ := lowestFare(res)
+			if !found || (price > 0 && (bestPrice == 0 || price < bestPrice)) {
+				best, bestPrice, found = res, price, true
+			}
+		}
+		if !found {
+			return model.SearchResult{}, firstErr
+		}
+		best.Warnings = append(best.Warnings, warnings...)
+		return best, nil
 	}
-	if watchID != "" {
-		return w.ID == watchID
+}
+
+type searchOutcome struct {
+	result model.SearchResult
+	err    error
+	done   chan struct{}
+}
+
+// searchConcurrently starts searching queries on up to concurrency workers,
+// dispatched in order. Each outcome's done channel closes once it is filled;
+// queries not dispatched before ctx is done get ctx.Err().
+func searchConcurrently(ctx context.Context, queries []model.SearchQuery, search watchSearchFunc, concurrency int) []*searchOutcome {
+	outcomes := make([]*searchOutcome, len(queries))
+	for k := range outcomes {
+		outcomes[k] = &searchOutcome{done: make(chan struct{})}
 	}
-	return runAll
+	jobs := make(chan int)
+	for range max(1, min(concurrency, len(queries))) {
+		go func() {
+			for k := range jobs {
+				o := outcomes[k]
+				if o.err = ctx.Err(); o.err == nil {
+					o.result, o.err = search(ctx, queries[k])
+				}
+				close(o.done)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for k := range queries {
+			select {
+			case jobs <- k:
+			case <-ctx.Done():
+				for _, o := range outcomes[k:] {
+					o.err = ctx.Err()
+					close(o.done)
+				}
+				return
+			}
+		}
+	}()
+	return outcomes
 }
 
-func evaluateWatchResult(w *model.Watch, res model.SearchResult, now time.Time) (model.Alert, bool) {
-	lowest := 0
-	currency := "USD"
-	if len(res.Flights) > 0 {
-		lowest = res.Flights[0].Price
-		currency = res.Flights[0].Currency
+// watchConcurrency caps the requested worker count at the provider's limit.
+func watchConcurrency(requested int, p provider.Provider) int {
+	if l, ok := p.(provider.ConcurrencyLimiter); ok && l.MaxConcurrency() > 0 {
+		return min(requested, l.MaxConcurrency())
 	}
+	return requested
+}
 
-	reason := ""
-	if w.TargetPrice > 0 && lowest > 0 && lowest <= w.TargetPrice {
-		reason = fmt.Sprintf("price reached target <= %d", w.TargetPrice)
-	} else if w.LastLowestPrice > 0 && lowest > 0 && lowest < w.LastLowestPrice {
-		reason = fmt.Sprintf("price dropped from %d to %d", w.LastLowestPrice, lowest)
+// saveRunState writes the run state of the watches in ids back to the store.
+// It merges into a fresh load under the lock, so watches created, edited or
+// deleted while providers were being queried are neither lost nor revived.
+func saveRunState(store watcher.Backend, ran []model.Watch, ids map[string]bool) error {
+	return store.Update(func(ws *model.WatchStore) error {
+		byID := map[string]model.Watch{}
+		for _, w := range ran {
+			if ids[w.ID] {
+				byID[w.ID] = w
+			}
+		}
+		for i := range ws.Watches {
+			r, ok := byID[ws.Watches[i].ID]
+			if !ok || !ws.Watches[i].Query.SameFare(r.Query) {
+				continue
+			}
+			ws.Watches[i].LastRunAt = r.LastRunAt
+			ws.Watches[i].LastLowestPrice = r.LastLowestPrice
+			ws.Watches[i].AlertState = r.AlertState
+			if r.UpdatedAt.After(ws.Watches[i].UpdatedAt) {
+				ws.Watches[i].UpdatedAt = r.UpdatedAt
+			}
+		}
+		return nil
+	})
+}
+
+func saveWatchSnapshot(history watchHistory, snap model.WatchSnapshot, errw io.Writer) {
+	if history == nil {
+		return
+	}
+	if err := history.Snapshot(snap); err != nil && errw != nil {
+		fmt.Fprintf(errw, "watch %s snapshot not saved: %v\n", snap.WatchID, err)
 	}
+}
+
+func historyEntryFromResult(w model.Watch, res model.SearchResult, now time.Time) model.PriceHistoryEntry {
+	entry := model.PriceHistoryEntry{
+		CheckedAt:   now.UTC(),
+		Currency:    firstOr(res.Query.Currency, "USD"),
+		FlightCount: len(res.Flights),
+	}
+	if w.Query.Flexible() {
+		entry.Depart, entry.Return = res.Query.Depart, res.Query.Return
+	}
+	if len(res.Routes) > 0 {
+		entry.Route = cheapestRoute(res)
+	}
+	if i := cheapestFlight(res); i >= 0 {
+		top := res.Flights[i]
+		entry.LowestPrice = top.Price
+		entry.Currency = firstOr(top.Currency, entry.Currency)
+		entry.TopItinerary = &top
+	}
+	return entry
+}
+
+func shouldRunWatch(w model.Watch, watchID string, runAll bool) bool {
+	return shouldRunSelected(w, watchSelector{ID: watchID, All: runAll})
+}
+
+func shouldRunSelected(w model.Watch, sel watchSelector) bool {
+	return w.Enabled && sel.matches(w)
+}
+
+func evaluateWatchResult(w *model.Watch, res model.SearchResult, history []model.PriceHistoryEntry, now time.Time) (model.Alert, bool) {
+	lowest, currency := lowestFare(res)
+	bookingURL, seller := lowestFareBooking(res)
+	hits := evaluateAlertRules(*w, lowest, history, res.Insights, now)
+	reasons := make([]string, 0, len(hits))
+	rules := make([]string, 0, len(hits))
+	for _, h := range hits {
+		reasons = append(reasons, h.Rule+": "+h.Reason)
+		rules = append(rules, h.Rule)
+	}
+	reason := strings.Join(reasons, "; ")
 
 	w.LastRunAt = now.UTC()
 	if lowest > 0 {
@@ -97,15 +342,87 @@ func evaluateWatchResult(w *model.Watch, res model.SearchResult, now time.Time)
 		return model.Alert{}, false
 	}
 
-	return model.Alert{
-		WatchID:     w.ID,
-		WatchName:   w.Name,
-		TriggeredAt: now.UTC(),
-		Reason:      reason,
-		LowestPrice: lowest,
-		Currency:    currency,
-		URL:         res.URL,
-	}, true
+	alert := model.Alert{
+		WatchID:       w.ID,
+		WatchName:     w.Name,
+		TriggeredAt:   now.UTC(),
+		Reason:        reason,
+		Rules:         rules,
+		LowestPrice:   lowest,
+		Currency:      currency,
+		URL:           res.URL,
+		BookingURL:    bookingURL,
+		BookingSeller: seller,
+	}
+	if w.Query.Flexible() {
+		alert.Depart, alert.Return = res.Query.Depart, res.Query.Return
+	}
+	if len(res.Routes) > 0 {
+		alert.Route = cheapestRoute(res)
+	}
+	return alert, true
+}
+
+func lowestFare(res model.SearchResult) (int, string) {
+	i := cheapestFlight(res)
+	if i < 0 {
+		return 0, "USD"
+	}
+	return res.Flights[i].Price, res.Flights[i].Currency
+}
+
+// lowestFareBooking returns the deep link and seller for the cheapest flight,
+// if the watch resolved booking options for it.
+func lowestFareBooking(res model.SearchResult) (string, string) {
+	i := cheapestFlight(res)
+	if i < 0 {
+		return "", ""
+	}
+	f := bookingTarget(res.Flights[i])
+	if f.DeepLink == "" {
+		return "", ""
+	}
+	for _, b := range f.BookingOptions {
+		if b.URL == f.DeepLink {
+			return f.DeepLink, b.Seller
+		}
+	}
+	return f.DeepLink, ""
+}
+
+// cheapestFlight returns the index of the first lowest-priced flight, or -1.
+// Results are only price-ordered when the query sorts by price.
+func cheapestFlight(res model.SearchResult) int {
+	best := -1
+	for i, f := range res.Flights {
+		if best < 0 || f.Price < res.Flights[best].Price {
+			best = i
+		}
+	}
+	return best
+}
+
+// evaluatedIDs returns the selected IDs minus those the report skipped.
+func evaluatedIDs(selected map[string]bool, report watchRunReport) map[string]bool {
+	out := make(map[string]bool, len(selected))
+	for id := range selected {
+		out[id] = true
+	}
+	for _, id := range report.SkippedWatchIDs {
+		delete(out, id)
+	}
+	return out
+}
+
+// interruptedRunError explains a pass cut short by ctx. Deadlines exit like
+// provider failures; cancellation is a generic failure. Notification failures
+// from the watches that did finish take precedence, as in a full pass.
+func interruptedRunError(ctx context.Context, report watchRunReport, notifyErrs []string) error {
+	err := fmt.Errorf("watch run interrupted after evaluating %d of %d watch(es): %w", report.Evaluated, report.Evaluated+report.Skipped, ctx.Err())
+	if len(notifyErrs) > 0 {
+		return newExitError(ExitNotifyFailure, "%s; %v", strings.Join(notifyErrs, "; "), err)
+	}
+	return wrapProviderError(err)
 }
 
 func shouldReturnProviderFailure(report watchRunReport, strict bool) bool {
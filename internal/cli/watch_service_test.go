@@ -2,12 +2,16 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/provider"
 )
 
 func TestShouldRunWatch(t *testing.T) {
@@ -32,7 +36,7 @@ func TestEvaluateWatchResultTargetPrice(t *testing.T) {
 	w := model.Watch{ID: "w1", Name: "athens", TargetPrice: 700}
 	res := model.SearchResult{Flights: []model.Flight{{Price: 650, Currency: "USD"}}, URL: "https://x"}
 
-	alert, ok := evaluateWatchResult(&w, res, now)
+	alert, ok := evaluateWatchResult(&w, res, nil, now)
 	if !ok {
 		t.Fatalf("expected alert")
 	}
@@ -49,7 +53,7 @@ func TestEvaluateWatchResultPriceDrop(t *testing.T) {
 	w := model.Watch{ID: "w1", Name: "athens", LastLowestPrice: 900}
 	res := model.SearchResult{Flights: []model.Flight{{Price: 800, Currency: "USD"}}, URL: "https://x"}
 
-	alert, ok := evaluateWatchResult(&w, res, now)
+	alert, ok := evaluateWatchResult(&w, res, nil, now)
 	if !ok {
 		t.Fatalf("expected alert")
 	}
@@ -58,11 +62,30 @@ func TestEvaluateWatchResultPriceDrop(t *testing.T) {
 	}
 }
 
+func TestEvaluateWatchResultLinksCheapestBookingOption(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	w := model.Watch{ID: "w1", Name: "athens", TargetPrice: 700}
+	res := model.SearchResult{URL: "https://x", Flights: []model.Flight{
+		{Price: 690, Currency: "USD", DeepLink: "https://book/ua"},
+		{Price: 650, Currency: "USD", DeepLink: "https://book/a3", BookingOptions: []model.BookingOption{
+			{Seller: "Aegean", Price: 650, URL: "https://book/a3"},
+		}},
+	}}
+
+	alert, ok := evaluateWatchResult(&w, res, nil, now)
+	if !ok {
+		t.Fatalf("expected alert")
+	}
+	if alert.LowestPrice != 650 || alert.BookingURL != "https://book/a3" || alert.BookingSeller != "Aegean" || alert.URL != "https://x" {
+		t.Fatalf("expected cheapest fare's booking link, got %+v", alert)
+	}
+}
+
 func TestRunWatchPassCollectsNotifyErrors(t *testing.T) {
 	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
 	watches := []model.Watch{{ID: "w1", Name: "athens", Enabled: true, TargetPrice: 700, Query: model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"}}}
 
-	search := func(model.SearchQuery) (model.SearchResult, error) {
+	search := func(context.Context, model.SearchQuery) (model.SearchResult, error) {
 		return model.SearchResult{Flights: []model.Flight{{Price: 650, Currency: "USD"}}, URL: "https://x"}, nil
 	}
 	notify := func(model.Watch, model.Alert) error {
@@ -90,7 +113,7 @@ func TestRunWatchPassCollectsNotifyErrors(t *testing.T) {
 func TestRunWatchPassVerboseProviderErrors(t *testing.T) {
 	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
 	watches := []model.Watch{{ID: "w1", Name: "athens", Enabled: true, Query: model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"}}}
-	search := func(model.SearchQuery) (model.SearchResult, error) {
+	search := func(context.Context, model.SearchQuery) (model.SearchResult, error) {
 		return model.SearchResult{}, errors.New("provider timeout")
 	}
 	notify := func(model.Watch, model.Alert) error { return nil }
@@ -155,3 +178,179 @@ func TestShouldReturnProviderFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestRunWatchPassConcurrentKeepsWatchOrder(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	watches := make([]model.Watch, 12)
+	for i := range watches {
+		watches[i] = model.Watch{ID: fmt.Sprintf("w%02d", i), Enabled: true, TargetPrice: 700, Query: model.SearchQuery{From: "SFO", To: "ATH", Adults: i}}
+	}
+	var inFlight, peak atomic.Int32
+	search := func(_ context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// Later watches finish first.
+		time.Sleep(time.Duration(len(watches)-q.Adults) * time.Millisecond)
+		if q.Adults%4 == 3 {
+			return model.SearchResult{}, errors.New("provider timeout")
+		}
+		return model.SearchResult{Flights: []model.Flight{{Price: 600 + q.Adults, Currency: "USD"}}}, nil
+	}
+	notified := []string{}
+	notify := func(w model.Watch, _ model.Alert) error {
+		notified = append(notified, w.ID)
+		return nil
+	}
+	history := &fakeHistory{}
+
+	report, _ := runWatchPassSelected(context.Background(), watches, func(model.Watch) bool { return true }, search, notify, history, 3, now, false, nil)
+	if report.Evaluated != 12 || report.ProviderFailures != 3 || report.Triggered != 9 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if p := peak.Load(); p > 3 {
+		t.Fatalf("expected at most 3 concurrent searches, saw %d", p)
+	}
+	want := []string{}
+	for i, w := range watches {
+		if i%4 != 3 {
+			want = append(want, w.ID)
+			if w.LastLowestPrice != 600+i || w.AlertState.Status != alertStateFired {
+				t.Fatalf("unexpected state for %s: %+v", w.ID, w)
+			}
+		}
+	}
+	got := []string{}
+	for _, a := range report.Alerts {
+		got = append(got, a.WatchID)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") || strings.Join(notified, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected alerts and notifications in watch order %v, got alerts=%v notified=%v", want, got, notified)
+	}
+	if len(history.entries) != 9 || len(history.snapshots) != 12 {
+		t.Fatalf("expected 9 history entries and 12 snapshots, got %d/%d", len(history.entries), len(history.snapshots))
+	}
+}
+
+func TestWatchConcurrencyRespectsProviderCap(t *testing.T) {
+	if got := watchConcurrency(10, provider.SerpAPIProvider{}); got != provider.DefaultSerpAPIConcurrency {
+		t.Fatalf("expected serpapi default cap, got %d", got)
+	}
+	if got := watchConcurrency(10, provider.SerpAPIProvider{MaxConcurrent: 2}); got != 2 {
+		t.Fatalf("expected configured cap, got %d", got)
+	}
+	if got := watchConcurrency(10, provider.GoogleURLProvider{}); got != 10 {
+		t.Fatalf("expected uncapped provider, got %d", got)
+	}
+}
+
+func TestRunWatchPassCanceledReportsCompletedWatches(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	watches := make([]model.Watch, 5)
+	for i := range watches {
+		watches[i] = model.Watch{ID: fmt.Sprintf("w%d", i), Enabled: true, TargetPrice: 700, Query: model.SearchQuery{From: "SFO", To: "ATH", Adults: i}}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	search := func(ctx context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		if q.Adults == 2 {
+			cancel()
+			<-ctx.Done()
+			return model.SearchResult{}, fmt.Errorf("serpapi request abandoned: %w", ctx.Err())
+		}
+		return model.SearchResult{Flights: []model.Flight{{Price: 650, Currency: "USD"}}}, nil
+	}
+	history := &fakeHistory{}
+
+	report, _ := runWatchPassSelected(ctx, watches, func(model.Watch) bool { return true }, search, func(model.Watch, model.Alert) error { return nil }, history, 1, now, false, nil)
+	if report.Evaluated != 2 || report.Skipped != 3 || !report.Interrupted || report.ProviderFailures != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if strings.Join(report.SkippedWatchIDs, ",") != "w2,w3,w4" {
+		t.Fatalf("unexpected skipped ids: %v", report.SkippedWatchIDs)
+	}
+	if !watches[2].LastRunAt.IsZero() || watches[1].LastRunAt.IsZero() || len(history.snapshots) != 2 {
+		t.Fatalf("expected only completed watches updated: %+v snapshots=%d", watches, len(history.snapshots))
+	}
+	ran := evaluatedIDs(map[string]bool{"w0": true, "w1": true, "w2": true, "w3": true, "w4": true}, report)
+	if len(ran) != 2 || !ran["w0"] || !ran["w1"] {
+		t.Fatalf("unexpected evaluated ids: %v", ran)
+	}
+	if err := interruptedRunError(ctx, report, nil); ExitCode(err) != ExitGenericFailure || !strings.Contains(err.Error(), "2 of 5") {
+		t.Fatalf("unexpected canceled error: %v", err)
+	}
+	err := interruptedRunError(ctx, report, []string{"notify w0: smtp down"})
+	if ExitCode(err) != ExitNotifyFailure || !strings.Contains(err.Error(), "notify w0: smtp down") || !strings.Contains(err.Error(), "2 of 5") {
+		t.Fatalf("expected notify failures reported on interrupt, got %v", err)
+	}
+	expired, stop := context.WithTimeout(context.Background(), 0)
+	defer stop()
+	<-expired.Done()
+	if err := interruptedRunError(expired, report, nil); ExitCode(err) != ExitProviderFailure {
+		t.Fatalf("expected deadline to exit as provider failure, got %v", err)
+	}
+}
+
+func TestRunWatchPassFlexibleWatchAlertsWithCheapestDates(t *testing.T) {
+	now := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	q := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-01", DepartTo: "2026-06-03", TripMinDays: 6, TripMaxDays: 7}
+	watches := []model.Watch{{ID: "w1", Name: "june", Enabled: true, TargetPrice: 700, Query: q}}
+	var searched []string
+	search := func(_ context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		if q.Flexible() {
+			t.Fatalf("provider got a flexible query: %+v", q)
+		}
+		searched = append(searched, q.Depart+"/"+q.Return)
+		switch q.Depart + "/" + q.Return {
+		case "2026-06-02/2026-06-09":
+			return model.SearchResult{Query: q, Flights: []model.Flight{{Price: 640, Currency: "USD"}}}, nil
+		case "2026-06-03/2026-06-09":
+			return model.SearchResult{}, errors.New("boom")
+		}
+		return model.SearchResult{Query: q, Flights: []model.Flight{{Price: 810, Currency: "USD"}}}, nil
+	}
+	var errBuf bytes.Buffer
+	report, _ := runWatchPass(watches, "", true, search, func(model.Watch, model.Alert) error { return nil }, now, true, &errBuf)
+	if len(searched) != 6 || report.ProviderFailures != 0 || report.Triggered != 1 {
+		t.Fatalf("unexpected pass: searched=%v report=%+v", searched, report)
+	}
+	a := report.Alerts[0]
+	if a.LowestPrice != 640 || a.Depart != "2026-06-02" || a.Return != "2026-06-09" {
+		t.Fatalf("expected alert to name the cheapest dates, got %+v", a)
+	}
+	if !strings.Contains(errBuf.String(), "dates 2026-06-03 -> 2026-06-09: boom") {
+		t.Fatalf("expected failed date as a warning, got %q", errBuf.String())
+	}
+	if watches[0].Query != q {
+		t.Fatalf("watch query must keep its window: %+v", watches[0].Query)
+	}
+}
+
+func TestRunWatchPassFlexibleWatchSkipsPastDates(t *testing.T) {
+	q := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-01", DepartTo: "2026-06-03", TripMinDays: 6, TripMaxDays: 7}
+	watches := []model.Watch{{ID: "w1", Name: "june", Enabled: true, Query: q}}
+	var searched []string
+	search := func(_ context.Context, q model.SearchQuery) (model.SearchResult, error) {
+		searched = append(searched, q.Depart)
+		return model.SearchResult{Query: q, Flights: []model.Flight{{Price: 810, Currency: "USD"}}}, nil
+	}
+	notify := func(model.Watch, model.Alert) error { return nil }
+	now := time.Date(2026, 6, 2, 9, 0, 0, 0, time.UTC)
+	report, _ := runWatchPass(watches, "", true, search, notify, now, false, nil)
+	if report.ProviderFailures != 0 || strings.Join(searched, ",") != "2026-06-02,2026-06-02,2026-06-03,2026-06-03" {
+		t.Fatalf("expected only remaining dates searched, got searched=%v report=%+v", searched, report)
+	}
+
+	searched = nil
+	var errBuf bytes.Buffer
+	now = time.Date(2026, 6, 4, 9, 0, 0, 0, time.UTC)
+	report, _ = runWatchPass(watches, "", true, search, notify, now, true, &errBuf)
+	if len(searched) != 0 || report.ProviderFailures != 1 || !strings.Contains(errBuf.String(), "date window 2026-06-01..2026-06-03 +6-7d expired") {
+		t.Fatalf("expected an expired window without searches, got searched=%v report=%+v stderr=%q", searched, report, errBuf.String())
+	}
+}
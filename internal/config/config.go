@@ -9,19 +9,29 @@ import (
 	"strconv"
 )
 
+// MaxWatchConcurrency bounds watch_concurrency however it is set.
+const MaxWatchConcurrency = 64
+
 type Config struct {
-	Provider           string `json:"provider"`
-	SerpAPIKey         string `json:"serp_api_key,omitempty"`
-	ProviderTimeoutSec int    `json:"provider_timeout_seconds,omitempty"`
-	ProviderRetries    int    `json:"provider_retries,omitempty"`
-	ProviderBackoffMS  int    `json:"provider_backoff_ms,omitempty"`
-	WebhookURL         string `json:"webhook_url,omitempty"`
-	SMTPHost           string `json:"smtp_host,omitempty"`
-	SMTPPort           int    `json:"smtp_port,omitempty"`
-	SMTPUsername       string `json:"smtp_username,omitempty"`
-	SMTPPassword       string `json:"smtp_password,omitempty"`
-	SMTPSender         string `json:"smtp_sender,omitempty"`
-	DefaultNotifyEmail string `json:"default_notify_email,omitempty"`
+	Provider                  string `json:"provider"`
+	SerpAPIKey                string `json:"serp_api_key,omitempty"`
+	ProviderTimeoutSec        int    `json:"provider_timeout_seconds,omitempty"`
+	ProviderRetries           int    `json:"provider_retries,omitempty"`
+	ProviderBackoffMS         int    `json:"provider_backoff_ms,omitempty"`
+	WebhookURL                string `json:"webhook_url,omitempty"`
+	SMTPHost                  string `json:"smtp_host,omitempty"`
+	SMTPPort                  int    `json:"smtp_port,omitempty"`
+	SMTPUsername              string `json:"smtp_username,omitempty"`
+	SMTPPassword              string `json:"smtp_password,omitempty"`
+	SMTPSender                string `json:"smtp_sender,omitempty"`
+	DefaultNotifyEmail        string `json:"default_notify_email,omitempty"`
+	CheckInterval             string `json:"check_interval,omitempty"`
+	HistoryRetention          int    `json:"history_retention_days,omitempty"`
+	StorageBackend            string `json:"storage_backend,omitempty"`
+	WatchConcurrency          int    `json:"watch_concurrency,omitempty"`
+	ProviderMaxConcurrency    int    `json:"provider_max_concurrency,omitempty"`
+	ProviderMaxReturnLookups  int    `json:"provider_max_return_lookups,omitempty"`
+	ProviderMaxBookingLookups int    `json:"provider_max_booking_lookups,omitempty"`
 }
 
 func ConfigDir() (string, error) {
@@ -67,6 +77,10 @@ func Load() (Config, error) {
 		ProviderRetries:    2,
 		ProviderBackoffMS:  400,
 		SMTPPort:           587,
+		CheckInterval:      "15m",
+		HistoryRetention:   180,
+		StorageBackend:     "json",
+		WatchConcurrency:   4,
 	}
 	path, err := ConfigPath()
 	if err != nil {
@@ -99,6 +113,21 @@ func Load() (Config, error) {
 	if cfg.ProviderBackoffMS <= 0 {
 		cfg.ProviderBackoffMS = 400
 	}
+	if cfg.CheckInterval == "" {
+		cfg.CheckInterval = "15m"
+	}
+	if cfg.HistoryRetention <= 0 {
+		cfg.HistoryRetention = 180
+	}
+	if cfg.StorageBackend == "" {
+		cfg.StorageBackend = "json"
+	}
+	if cfg.WatchConcurrency <= 0 {
+		cfg.WatchConcurrency = 4
+	}
+	if cfg.WatchConcurrency > MaxWatchConcurrency {
+		cfg.WatchConcurrency = MaxWatchConcurrency
+	}
 	return cfg, nil
 }
 
@@ -159,4 +188,15 @@ func applyEnv(cfg *Config) {
 	if v := os.Getenv("GFLIGHT_NOTIFY_EMAIL"); v != "" {
 		cfg.DefaultNotifyEmail = v
 	}
+	if v := os.Getenv("GFLIGHT_CHECK_INTERVAL"); v != "" {
+		cfg.CheckInterval = v
+	}
+	if v := os.Getenv("GFLIGHT_STORAGE_BACKEND"); v != "" {
+		cfg.StorageBackend = v
+	}
+	if v := os.Getenv("GFLIGHT_WATCH_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= MaxWatchConcurrency {
+			cfg.WatchConcurrency = n
+		}
+	}
 }
@@ -3,17 +3,30 @@ package model
 import "time"
 
 type SearchQuery struct {
-	From     string `json:"from"`
-	To       string `json:"to"`
-	Depart   string `json:"depart"`
-	Return   string `json:"return,omitempty"`
-	Cabin    string `json:"cabin"`
-	Adults   int    `json:"adults"`
-	Children int    `json:"children"`
-	Nonstop  bool   `json:"nonstop"`
-	MaxPrice int    `json:"max_price,omitempty"`
-	Currency string `json:"currency"`
-	SortBy   string `json:"sort_by"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Depart string `json:"depart"`
+	Return string `json:"return,omitempty"`
+	// DepartTo and the trip-length range make the query flexible: Depart
+	// through DepartTo are searched, returning TripMinDays..TripMaxDays later.
+	DepartTo    string `json:"depart_to,omitempty"`
+	TripMinDays int    `json:"trip_min_days,omitempty"`
+	TripMaxDays int    `json:"trip_max_days,omitempty"`
+	Cabin       Cabin  `json:"cabin"`
+	Adults      int    `json:"adults"`
+	Children    int    `json:"children"`
+	// Nonstop is kept for older watches and --nonstop; Stops takes precedence.
+	Nonstop  bool      `json:"nonstop"`
+	Stops    Stops     `json:"stops,omitempty"`
+	MaxPrice int       `json:"max_price,omitempty"`
+	Currency string    `json:"currency"`
+	SortBy   SortOrder `json:"sort_by"`
+	// Booking resolves booking options for the leading results; it does not
+	// change which fares match, see SameFare.
+	Booking bool `json:"booking,omitempty"`
+	// AllowUnknownAirports accepts well-formed codes missing from the
+	// embedded airport table.
+	AllowUnknownAirports bool `json:"allow_unknown_airports,omitempty"`
 }
 
 type Flight struct {
@@ -24,39 +37,197 @@ type Flight struct {
 	To           string `json:"to"`
 	DepartTime   string `json:"depart_time,omitempty"`
 	ArriveTime   string `json:"arrive_time,omitempty"`
-	Duration     string `json:"duration,omitempty"`
-	Stops        int    `json:"stops"`
-	Price        int    `json:"price"`
-	Currency     string `json:"currency"`
-	DeepLink     string `json:"deep_link,omitempty"`
+	// Duration is the total trip time, e.g. "735m".
+	Duration             string     `json:"duration,omitempty"`
+	TotalDurationMinutes int        `json:"total_duration_minutes,omitempty"`
+	Stops                int        `json:"stops"`
+	Price                int        `json:"price"`
+	Currency             string     `json:"currency"`
+	DeepLink             string     `json:"deep_link,omitempty"`
+	Segments             []Segment  `json:"segments,omitempty"`
+	Layovers             []Layover  `json:"layovers,omitempty"`
+	Emissions            *Emissions `json:"emissions,omitempty"`
+	// DepartureToken fetches the return options of a round-trip outbound flight.
+	DepartureToken string `json:"departure_token,omitempty"`
+	// ReturnOptions are priced as the whole round trip.
+	ReturnOptions []Flight `json:"return_options,omitempty"`
+	// BookingToken fetches BookingOptions; DeepLink is the best option's URL.
+	BookingToken   string          `json:"booking_token,omitempty"`
+	BookingOptions []BookingOption `json:"booking_options,omitempty"`
+}
+
+// BookingOption is one seller offering the itinerary. Leg is set when the
+// seller only sells one direction of a separately ticketed trip.
+type BookingOption struct {
+	Seller   string `json:"seller"`
+	Price    int    `json:"price"`
+	Currency string `json:"currency"`
+	URL      string `json:"url"`
+	Leg      string `json:"leg,omitempty"`
+}
+
+// Segment is one flown leg of an itinerary.
+type Segment struct {
+	From            string `json:"from"`
+	FromName        string `json:"from_name,omitempty"`
+	To              string `json:"to"`
+	ToName          string `json:"to_name,omitempty"`
+	DepartTime      string `json:"depart_time,omitempty"`
+	ArriveTime      string `json:"arrive_time,omitempty"`
+	Airline         string `json:"airline,omitempty"`
+	FlightNumber    string `json:"flight_number,omitempty"`
+	Aircraft        string `json:"aircraft,omitempty"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+	Legroom         string `json:"legroom,omitempty"`
+	Overnight       bool   `json:"overnight,omitempty"`
+}
+
+// Layover is the connection between two segments.
+type Layover struct {
+	Airport         string `json:"airport"`
+	Name            string `json:"name,omitempty"`
+	DurationMinutes int    `json:"duration_minutes"`
+	Overnight       bool   `json:"overnight,omitempty"`
+}
+
+// Emissions is the provider's CO2 estimate in grams per passenger.
+type Emissions struct {
+	Grams             int `json:"grams"`
+	TypicalGrams      int `json:"typical_grams,omitempty"`
+	DifferencePercent int `json:"difference_percent"`
 }
 
 type SearchResult struct {
-	Query     SearchQuery `json:"query"`
-	Flights   []Flight    `json:"flights"`
-	CheckedAt time.Time   `json:"checked_at"`
-	URL       string      `json:"google_flights_url"`
+	Query     SearchQuery    `json:"query"`
+	Flights   []Flight       `json:"flights"`
+	CheckedAt time.Time      `json:"checked_at"`
+	URL       string         `json:"google_flights_url"`
+	Warnings  []string       `json:"warnings,omitempty"`
+	Insights  *PriceInsights `json:"price_insights,omitempty"`
+	// Routes is set when the query expanded to several airport pairs.
+	Routes []RouteResult `json:"routes,omitempty"`
+}
+
+// RouteResult summarizes one airport pair of a multi-route search.
+type RouteResult struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	URL         string `json:"google_flights_url,omitempty"`
+	LowestPrice int    `json:"lowest_price"`
+	Currency    string `json:"currency"`
+	FlightCount int    `json:"flight_count"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Price levels reported by Google Flights.
+const (
+	PriceLevelLow     = "low"
+	PriceLevelTypical = "typical"
+	PriceLevelHigh    = "high"
+)
+
+// PriceInsights is the provider's view of the market for a query.
+type PriceInsights struct {
+	LowestPrice int          `json:"lowest_price,omitempty"`
+	PriceLevel  string       `json:"price_level,omitempty"`
+	TypicalLow  int          `json:"typical_low,omitempty"`
+	TypicalHigh int          `json:"typical_high,omitempty"`
+	History     []PricePoint `json:"history,omitempty"`
+}
+
+type PricePoint struct {
+	Date  time.Time `json:"date"`
+	Price int       `json:"price"`
+}
+
+type AlertRule struct {
+	Type    string  `json:"type"`
+	Percent float64 `json:"percent,omitempty"`
+	Days    int     `json:"days,omitempty"`
+	Runs    int     `json:"runs,omitempty"`
+	Amount  int     `json:"amount,omitempty"`
+}
+
+type AlertState struct {
+	Status     string    `json:"status,omitempty"`
+	FiredAt    time.Time `json:"fired_at,omitzero"`
+	FiredPrice int       `json:"fired_price,omitempty"`
+	RearmedAt  time.Time `json:"rearmed_at,omitzero"`
 }
 
+// DefaultRearmPercent is how far above the fired price a watch's fare must
+// rebound before it re-arms, unless the watch sets its own.
+const DefaultRearmPercent = 5.0
+
 type Watch struct {
-	ID              string      `json:"id"`
-	Name            string      `json:"name"`
-	Query           SearchQuery `json:"query"`
-	Enabled         bool        `json:"enabled"`
-	TargetPrice     int         `json:"target_price"`
-	NotifyTerminal  bool        `json:"notify_terminal"`
-	NotifyEmail     bool        `json:"notify_email"`
-	NotifyWebhook   bool        `json:"notify_webhook"`
-	EmailTo         string      `json:"email_to,omitempty"`
-	WebhookURL      string      `json:"webhook_url,omitempty"`
-	LastLowestPrice int         `json:"last_lowest_price"`
-	LastRunAt       time.Time   `json:"last_run_at,omitempty"`
-	CreatedAt       time.Time   `json:"created_at"`
-	UpdatedAt       time.Time   `json:"updated_at"`
+	ID             string      `json:"id"`
+	Key            string      `json:"key,omitempty"`
+	Name           string      `json:"name"`
+	Tags           []string    `json:"tags,omitempty"`
+	Query          SearchQuery `json:"query"`
+	Enabled        bool        `json:"enabled"`
+	TargetPrice    int         `json:"target_price"`
+	Rules          []AlertRule `json:"rules,omitempty"`
+	Cooldown       string      `json:"cooldown,omitempty"`
+	RearmPercent   float64     `json:"rearm_percent"`
+	AlertState     AlertState  `json:"alert_state"`
+	NotifyTerminal bool        `json:"notify_terminal"`
+	NotifyEmail    bool        `json:"notify_email"`
+	NotifyWebhook  bool        `json:"notify_webhook"`
+	EmailTo        string      `json:"email_to,omitempty"`
+	WebhookURL     string      `json:"webhook_url,omitempty"`
+	CheckInterval  string      `json:"check_interval,omitempty"`
+	Schedule       string      `json:"schedule,omitempty"`
+	// MaxRequests bounds the provider requests one run may spend across the
+	// watch's routes and dates; zero uses the default.
+	MaxRequests     int       `json:"max_requests,omitempty"`
+	LastLowestPrice int       `json:"last_lowest_price"`
+	LastRunAt       time.Time `json:"last_run_at,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type PriceHistoryEntry struct {
+	CheckedAt    time.Time `json:"checked_at"`
+	LowestPrice  int       `json:"lowest_price"`
+	Currency     string    `json:"currency"`
+	FlightCount  int       `json:"flight_count"`
+	TopItinerary *Flight   `json:"top_itinerary,omitempty"`
+	// Depart and Return name the cheapest dates of a flexible watch.
+	Depart string `json:"depart,omitempty"`
+	Return string `json:"return,omitempty"`
+	// Route is the cheapest airport pair of a multi-route watch, e.g. OAK-ATH.
+	Route string `json:"route,omitempty"`
 }
 
 type WatchStore struct {
-	Watches []Watch `json:"watches"`
+	SchemaVersion int     `json:"schema_version"`
+	Watches       []Watch `json:"watches"`
+}
+
+// WatchSnapshot is the outcome of a watch's most recent evaluation.
+type WatchSnapshot struct {
+	WatchID      string        `json:"watch_id"`
+	CheckedAt    time.Time     `json:"checked_at"`
+	Result       *SearchResult `json:"result,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Alert        *Alert        `json:"alert,omitempty"`
+	SuppressedBy string        `json:"suppressed_by,omitempty"`
+}
+
+// RunLogEntry summarizes one watch run pass.
+type RunLogEntry struct {
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	Mode             string    `json:"mode"`
+	Evaluated        int       `json:"evaluated"`
+	Triggered        int       `json:"triggered"`
+	Suppressed       int       `json:"suppressed"`
+	ProviderFailures int       `json:"provider_failures"`
+	NotifyFailures   int       `json:"notify_failures"`
+	Skipped          int       `json:"skipped"`
+	Interrupted      bool      `json:"interrupted"`
+	AlertWatchIDs    []string  `json:"alert_watch_ids,omitempty"`
 }
 
 type Alert struct {
@@ -64,7 +235,17 @@ type Alert struct {
 	WatchName   string    `json:"watch_name"`
 	TriggeredAt time.Time `json:"triggered_at"`
 	Reason      string    `json:"reason"`
+	Rules       []string  `json:"rules,omitempty"`
 	LowestPrice int       `json:"lowest_price"`
 	Currency    string    `json:"currency"`
 	URL         string    `json:"google_flights_url"`
+	// BookingURL links to a seller of the lowest fare when the watch resolves
+	// booking options.
+	BookingURL    string `json:"booking_url,omitempty"`
+	BookingSeller string `json:"booking_seller,omitempty"`
+	// Depart and Return are the winning dates of a flexible watch.
+	Depart string `json:"depart,omitempty"`
+	Return string `json:"return,omitempty"`
+	// Route is the winning airport pair of a multi-route watch, e.g. OAK-ATH.
+	Route string `json:"route,omitempty"`
 }
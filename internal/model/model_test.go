@@ -20,3 +20,67 @@ func TestSearchQueryJSONTags(t *testing.T) {
 		t.Fatalf("expected normalized keys in json: %s", s)
 	}
 }
+
+func TestAlertStateOmitsUnsetTimes(t *testing.T) {
+	b, err := json.Marshal(AlertState{Status: "armed"})
+	if err != nil {
+		t.Fatalf("marshal alert state: %v", err)
+	}
+	if string(b) != `{"status":"armed"}` {
+		t.Fatalf("expected unset times omitted, got %s", b)
+	}
+}
+
+func TestSameFareIgnoresResultOptions(t *testing.T) {
+	q := SearchQuery{From: "SFO", To: "ATH", Depart: "2030-06-10", SortBy: SortPrice, Cabin: CabinEconomy, Adults: 1, Currency: "USD"}
+	same := q
+	same.Booking, same.AllowUnknownAirports, same.SortBy, same.MaxPrice = true, true, SortDuration, 900
+	if !q.SameFare(same) {
+		t.Fatalf("expected booking, airport check, sort and max price ignored")
+	}
+	other := q
+	other.Cabin = CabinBusiness
+	if q.SameFare(other) {
+		t.Fatalf("expected a cabin change to change the fare")
+	}
+}
+
+func TestParseQueryVocabulary(t *testing.T) {
+	if c, err := ParseCabin("Premium-Economy"); err != nil || c != CabinPremiumEconomy {
+		t.Fatalf("ParseCabin = %q, %v", c, err)
+	}
+	if o, err := ParseSortOrder("departure"); err != nil || o != SortDepartureTime {
+		t.Fatalf("ParseSortOrder = %q, %v", o, err)
+	}
+	if s, err := ParseStops("0"); err != nil || s != StopsNonstop {
+		t.Fatalf("ParseStops = %q, %v", s, err)
+	}
+	if _, err := ParseCabin("steerage"); err == nil || !strings.Contains(err.Error(), "premium_economy") {
+		t.Fatalf("expected unknown cabin error listing values, got %v", err)
+	}
+	if _, err := ParseStops("3"); err == nil {
+		t.Fatal("expected unknown stops error")
+	}
+	if c, err := ParseCurrency(" eur"); err != nil || c != "EUR" {
+		t.Fatalf("ParseCurrency = %q, %v", c, err)
+	}
+	if _, err := ParseCurrency("DOLLARS"); err == nil {
+		t.Fatal("expected unknown currency error")
+	}
+	if got := (SearchQuery{Nonstop: true}).MaxStops(); got != StopsNonstop {
+		t.Fatalf("MaxStops with legacy nonstop = %q", got)
+	}
+	if got := (SearchQuery{Nonstop: true, Stops: StopsMaxTwo}).MaxStops(); got != StopsMaxTwo {
+		t.Fatalf("MaxStops with stops set = %q", got)
+	}
+}
+
+func TestSplitCodes(t *testing.T) {
+	q := SearchQuery{From: " sfo, OAK,,sfo ", To: "ath"}
+	if got := strings.Join(q.Origins(), ","); got != "SFO,OAK" {
+		t.Fatalf("Origins = %q", got)
+	}
+	if got := NormalizeCodes(q.To); got != "ATH" {
+		t.Fatalf("NormalizeCodes = %q", got)
+	}
+}
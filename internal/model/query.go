@@ -0,0 +1,184 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Cabin is the requested travel class.
+type Cabin string
+
+const (
+	CabinEconomy        Cabin = "economy"
+	CabinPremiumEconomy Cabin = "premium_economy"
+	CabinBusiness       Cabin = "business"
+	CabinFirst          Cabin = "first"
+)
+
+// SortOrder is how results should be ranked.
+type SortOrder string
+
+const (
+	SortBest          SortOrder = "best"
+	SortPrice         SortOrder = "price"
+	SortDepartureTime SortOrder = "departure_time"
+	SortArrivalTime   SortOrder = "arrival_time"
+	SortDuration      SortOrder = "duration"
+	SortEmissions     SortOrder = "emissions"
+)
+
+// Stops limits the number of stops per direction.
+type Stops string
+
+const (
+	StopsAny     Stops = "any"
+	StopsNonstop Stops = "nonstop"
+	StopsMaxOne  Stops = "1"
+	StopsMaxTwo  Stops = "2"
+)
+
+var (
+	Cabins      = []Cabin{CabinEconomy, CabinPremiumEconomy, CabinBusiness, CabinFirst}
+	SortOrders  = []SortOrder{SortBest, SortPrice, SortDepartureTime, SortArrivalTime, SortDuration, SortEmissions}
+	StopsLimits = []Stops{StopsAny, StopsNonstop, StopsMaxOne, StopsMaxTwo}
+)
+
+// ParseCabin accepts a cabin name case-insensitively, with "-" or " " in
+// place of "_". The empty string means the provider default.
+func ParseCabin(s string) (Cabin, error) {
+	v := vocabKey(s)
+	if v == "premium" {
+		v = string(CabinPremiumEconomy)
+	}
+	for _, c := range Cabins {
+		if v == string(c) {
+			return c, nil
+		}
+	}
+	if v == "" {
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown cabin %q (use %s)", s, JoinVocab(Cabins))
+}
+
+// ParseSortOrder accepts a sort order like ParseCabin; "top" is an alias for best.
+func ParseSortOrder(s string) (SortOrder, error) {
+	v := vocabKey(s)
+	switch v {
+	case "top":
+		v = string(SortBest)
+	case "departure", "arrival":
+		v += "_time"
+	}
+	for _, o := range SortOrders {
+		if v == string(o) {
+			return o, nil
+		}
+	}
+	if v == "" {
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown sort order %q (use %s)", s, JoinVocab(SortOrders))
+}
+
+// ParseStops accepts any, nonstop (or 0), 1 or 2.
+func ParseStops(s string) (Stops, error) {
+	v := vocabKey(s)
+	if v == "0" {
+		v = string(StopsNonstop)
+	}
+	for _, st := range StopsLimits {
+		if v == string(st) {
+			return st, nil
+		}
+	}
+	if v == "" {
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown stops limit %q (use %s)", s, JoinVocab(StopsLimits))
+}
+
+// Currencies lists the ISO 4217 codes Google Flights can price fares in.
+var Currencies = []string{
+	"AED", "ALL", "AMD", "ARS", "AUD", "AZN", "BAM", "BGN", "BHD", "BRL", "BYN", "CAD", "CHF", "CLP", "CNY", "COP",
+	"CRC", "CZK", "DKK", "DOP", "DZD", "EGP", "EUR", "GBP", "GEL", "HKD", "HUF", "IDR", "ILS", "INR", "IRR", "ISK",
+	"JOD", "JPY", "KES", "KRW", "KWD", "KZT", "LBP", "LKR", "MAD", "MDL", "MKD", "MXN", "MYR", "NGN", "NOK", "NZD",
+	"OMR", "PAB", "PEN", "PHP", "PKR", "PLN", "QAR", "RON", "RSD", "RUB", "SAR", "SEK", "SGD", "THB", "TND", "TRY",
+	"TWD", "UAH", "USD", "UYU", "VND", "XAF", "XOF", "ZAR",
+}
+
+// ParseCurrency accepts a currency code case-insensitively. The empty string
+// means the provider default.
+func ParseCurrency(s string) (string, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	if v == "" || slices.Contains(Currencies, v) {
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown currency %q (use an ISO 4217 code such as USD, EUR or GBP)", s)
+}
+
+// MaxStops resolves the stops limit, honouring the older Nonstop flag.
+func (q SearchQuery) MaxStops() Stops {
+	if q.Stops != "" {
+		return q.Stops
+	}
+	if q.Nonstop {
+		return StopsNonstop
+	}
+	return StopsAny
+}
+
+// Flexible reports whether the query covers a departure window or a range of
+// trip lengths rather than fixed dates.
+func (q SearchQuery) Flexible() bool {
+	return q.DepartTo != "" || q.TripMaxDays > 0
+}
+
+// Origins and Destinations split From and To, which may list several
+// comma-separated codes, e.g. "SFO,OAK,SJC".
+func (q SearchQuery) Origins() []string      { return SplitCodes(q.From) }
+func (q SearchQuery) Destinations() []string { return SplitCodes(q.To) }
+
+// SplitCodes parses a comma-separated code list, upper-casing codes and
+// dropping blanks and duplicates.
+func SplitCodes(s string) []string {
+	var out []string
+	for _, c := range strings.Split(s, ",") {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if c != "" && !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// NormalizeCodes canonicalizes a code list, e.g. " sfo, oak" to "SFO,OAK".
+func NormalizeCodes(s string) string {
+	return strings.Join(SplitCodes(s), ",")
+}
+
+// SameFare reports whether two queries search the same fares, so a watch's
+// price history still applies. It ignores options that only enrich, order or
+// cap the results: SortBy never changes the cheapest fare, and MaxPrice only
+// hides fares above it, so the cheapest fare under the cap is unchanged.
+func (q SearchQuery) SameFare(o SearchQuery) bool {
+	q.Booking, o.Booking = false, false
+	q.AllowUnknownAirports, o.AllowUnknownAirports = false, false
+	q.SortBy, o.SortBy = "", ""
+	q.MaxPrice, o.MaxPrice = 0, 0
+	return q == o
+}
+
+func vocabKey(s string) string {
+	return strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// JoinVocab lists a vocabulary for error messages, e.g. "economy, business".
+func JoinVocab[T ~string](values []T) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = string(v)
+	}
+	return strings.Join(parts, ", ")
+}
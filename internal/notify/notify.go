@@ -23,16 +23,52 @@ type Notifier struct {
 }
 
 func (n Notifier) SendTerminal(alert model.Alert) {
-	fmt.Fprintf(os.Stderr, "ALERT %s (%s): %s. Lowest price: %d %s\n%s\n",
+	fmt.Fprintf(os.Stderr, "ALERT %s (%s): %s. Lowest price: %d %s%s\n%s\n",
 		alert.WatchName,
 		alert.WatchID,
 		alert.Reason,
 		alert.LowestPrice,
 		alert.Currency,
-		alert.URL,
+		winnerSuffix(alert),
+		firstOr(alert.BookingURL, alert.URL),
 	)
 }
 
+// winnerSuffix names the winning route of a multi-route watch and the
+// winning dates of a flexible one.
+func winnerSuffix(alert model.Alert) string {
+	s := ""
+	if alert.Route != "" {
+		s += " on " + alert.Route
+	}
+	switch {
+	case alert.Depart == "":
+	case alert.Return == "":
+		s += " departing " + alert.Depart
+	default:
+		s += fmt.Sprintf(" departing %s, returning %s", alert.Depart, alert.Return)
+	}
+	return s
+}
+
+// bookingLine names where the fare can be bought, if it was resolved.
+func bookingLine(alert model.Alert) string {
+	if alert.BookingURL == "" {
+		return ""
+	}
+	if alert.BookingSeller != "" {
+		return fmt.Sprintf("Book with %s: %s\n", alert.BookingSeller, alert.BookingURL)
+	}
+	return fmt.Sprintf("Book: %s\n", alert.BookingURL)
+}
+
+func firstOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
+
 func (n Notifier) SendEmail(to string, alert model.Alert) error {
 	if n.Config.SMTPHost == "" || n.Config.SMTPUsername == "" || n.Config.SMTPPassword == "" || n.Config.SMTPSender == "" {
 		return fmt.Errorf("email not configured: set smtp_host/smtp_username/smtp_password/smtp_sender")
@@ -43,10 +79,12 @@ func (n Notifier) SendEmail(to string, alert model.Alert) error {
 	addr := fmt.Sprintf("%s:%d", n.Config.SMTPHost, n.Config.SMTPPort)
 	auth := smtp.PlainAuth("", n.Config.SMTPUsername, n.Config.SMTPPassword, n.Config.SMTPHost)
 	subject := fmt.Sprintf("gflight alert: %s", alert.WatchName)
-	body := fmt.Sprintf("Reason: %s\nLowest price: %d %s\nGoogle Flights: %s\nTriggered at: %s\n",
+	body := fmt.Sprintf("Reason: %s\nLowest price: %d %s%s\n%sGoogle Flights: %s\nTriggered at: %s\n",
 		alert.Reason,
 		alert.LowestPrice,
 		alert.Currency,
+		winnerSuffix(alert),
+		bookingLine(alert),
 		alert.URL,
 		alert.TriggeredAt.Format("2006-01-02 15:04:05 MST"),
 	)
@@ -96,3 +96,23 @@ type timeoutErr struct{}
 func (timeoutErr) Error() string   { return "timeout" }
 func (timeoutErr) Timeout() bool   { return true }
 func (timeoutErr) Temporary() bool { return true }
+
+func TestBookingLine(t *testing.T) {
+	if got := bookingLine(model.Alert{}); got != "" {
+		t.Fatalf("expected no booking line, got %q", got)
+	}
+	got := bookingLine(model.Alert{BookingURL: "https://book", BookingSeller: "Aegean"})
+	if got != "Book with Aegean: https://book\n" {
+		t.Fatalf("unexpected booking line: %q", got)
+	}
+}
+
+func TestWinnerSuffix(t *testing.T) {
+	if got := winnerSuffix(model.Alert{}); got != "" {
+		t.Fatalf("expected no suffix for a fixed watch, got %q", got)
+	}
+	got := winnerSuffix(model.Alert{Route: "OAK-ATH", Depart: "2026-06-03", Return: "2026-06-10"})
+	if got != " on OAK-ATH departing 2026-06-03, returning 2026-06-10" {
+		t.Fatalf("unexpected winner suffix: %q", got)
+	}
+}
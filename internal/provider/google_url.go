@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"time"
@@ -10,7 +11,10 @@ import (
 
 type GoogleURLProvider struct{}
 
-func (p GoogleURLProvider) Search(query model.SearchQuery) (model.SearchResult, error) {
+func (p GoogleURLProvider) Search(ctx context.Context, query model.SearchQuery) (model.SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return model.SearchResult{}, err
+	}
 	result := model.SearchResult{
 		Query:     query,
 		Flights:   []model.Flight{},
@@ -28,11 +32,11 @@ func buildGoogleFlightsURL(query model.SearchQuery) string {
 	if query.Return != "" {
 		values.Set("r", query.Return)
 	}
-	if query.Nonstop {
+	if query.MaxStops() == model.StopsNonstop {
 		values.Set("sc", "1")
 	}
-	if query.Cabin != "" {
-		values.Set("c", query.Cabin)
+	if cabin, err := model.ParseCabin(string(query.Cabin)); err == nil && cabin != "" {
+		values.Set("c", string(cabin))
 	}
 	if query.Adults > 0 {
 		values.Set("ad", fmt.Sprintf("%d", query.Adults))
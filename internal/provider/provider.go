@@ -1,13 +1,28 @@
 package provider
 
-import "errors"
+import (
+	"context"
+	"errors"
 
-import "github.com/agisilaos/gflight/internal/model"
+	"github.com/agisilaos/gflight/internal/model"
+)
 
 var ErrAuthRequired = errors.New("provider authentication required")
 var ErrRateLimited = errors.New("provider rate limited")
 var ErrTransient = errors.New("provider transient failure")
 
+// ErrUnsupportedQuery is returned when a query uses a value the provider
+// cannot express.
+var ErrUnsupportedQuery = errors.New("query not supported by provider")
+
+// Provider searches for flights. Implementations must stop promptly and
+// return an error wrapping ctx.Err() once ctx is done.
 type Provider interface {
-	Search(query model.SearchQuery) (model.SearchResult, error)
+	Search(ctx context.Context, query model.SearchQuery) (model.SearchResult, error)
+}
+
+// ConcurrencyLimiter is implemented by providers that cap how many searches
+// may be in flight at once.
+type ConcurrencyLimiter interface {
+	MaxConcurrency() int
 }
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,43 +17,159 @@ import (
 	"github.com/agisilaos/gflight/internal/model"
 )
 
+// DefaultSerpAPIConcurrency caps parallel SerpAPI requests when
+// SerpAPIProvider.MaxConcurrent is unset.
+const DefaultSerpAPIConcurrency = 4
+
+// DefaultSerpAPIReturnLookups caps departure-token follow-ups per search when
+// MaxReturnLookups is unset. Each lookup is one extra billed request.
+const DefaultSerpAPIReturnLookups = 3
+
+// DefaultSerpAPIBookingLookups caps booking-token follow-ups per search when
+// MaxBookingLookups is unset.
+const DefaultSerpAPIBookingLookups = 3
+
 type SerpAPIProvider struct {
-	APIKey  string
-	Client  *http.Client
-	Timeout time.Duration
-	Retries int
-	Backoff time.Duration
-	BaseURL string
+	APIKey        string
+	Client        *http.Client
+	Timeout       time.Duration
+	Retries       int
+	Backoff       time.Duration
+	BaseURL       string
+	MaxConcurrent int
+	// ReturnLookups resolves return options for up to this many outbound
+	// flights of a round trip, capped by MaxReturnLookups.
+	ReturnLookups    int
+	MaxReturnLookups int
+	// MaxBookingLookups caps how many itineraries a query with Booking set
+	// resolves into booking options.
+	MaxBookingLookups int
+}
+
+func (p SerpAPIProvider) MaxConcurrency() int {
+	if p.MaxConcurrent > 0 {
+		return p.MaxConcurrent
+	}
+	return DefaultSerpAPIConcurrency
+}
+
+// BookingLookupLimit is the number of booking tokens Search will follow.
+func (p SerpAPIProvider) BookingLookupLimit() int {
+	if p.MaxBookingLookups > 0 {
+		return p.MaxBookingLookups
+	}
+	return DefaultSerpAPIBookingLookups
+}
+
+// ReturnLookupLimit is the number of departure tokens Search will follow.
+func (p SerpAPIProvider) ReturnLookupLimit() int {
+	limit := p.MaxReturnLookups
+	if limit <= 0 {
+		limit = DefaultSerpAPIReturnLookups
+	}
+	return min(max(p.ReturnLookups, 0), limit)
+}
+
+// returnLookupsFor is the number of departure tokens Search follows for q.
+// Round-trip booking tokens only appear on return options, so Booking
+// resolves returns for as many itineraries as it books, still within
+// MaxReturnLookups.
+func (p SerpAPIProvider) returnLookupsFor(q model.SearchQuery) int {
+	if q.Booking {
+		p.ReturnLookups = max(p.ReturnLookups, p.BookingLookupLimit())
+	}
+	return p.ReturnLookupLimit()
+}
+
+// RequestsFor is the most requests Search makes for q: the search itself plus
+// the return and booking lookups it follows.
+func (p SerpAPIProvider) RequestsFor(q model.SearchQuery) int {
+	n := 1
+	if q.Return != "" {
+		n += p.returnLookupsFor(q)
+	}
+	if q.Booking {
+		n += p.BookingLookupLimit()
+	}
+	return n
 }
 
 type serpResponse struct {
-	BestFlights  []serpFlight `json:"best_flights"`
-	OtherFlights []serpFlight `json:"other_flights"`
-	SearchMeta   struct {
+	BestFlights    []serpFlight        `json:"best_flights"`
+	OtherFlights   []serpFlight        `json:"other_flights"`
+	BookingOptions []serpBookingOption `json:"booking_options"`
+	PriceInsights  *serpPriceInsights  `json:"price_insights"`
+	SearchMeta     struct {
 		GoogleFlightsURL string `json:"google_flights_url"`
 	} `json:"search_metadata"`
 }
 
 type serpFlight struct {
-	Price       int    `json:"price"`
-	AirlineLogo string `json:"airline_logo"`
-	Flights     []struct {
-		Airline      string `json:"airline"`
-		FlightNumber string `json:"flight_number"`
-		Departure    struct {
-			Airport string `json:"airport"`
-			Time    string `json:"time"`
-		} `json:"departure_airport"`
-		Arrival struct {
-			Airport string `json:"airport"`
-			Time    string `json:"time"`
-		} `json:"arrival_airport"`
-		Duration int `json:"duration"`
-	} `json:"flights"`
-	Layovers []any `json:"layovers"`
-}
-
-func (p SerpAPIProvider) Search(query model.SearchQuery) (model.SearchResult, error) {
+	Price          int           `json:"price"`
+	DepartureToken string        `json:"departure_token"`
+	BookingToken   string        `json:"booking_token"`
+	AirlineLogo    string        `json:"airline_logo"`
+	Flights        []serpSegment `json:"flights"`
+	Layovers       []serpLayover `json:"layovers"`
+	TotalDuration  int           `json:"total_duration"`
+	Emissions      *struct {
+		ThisFlight        int `json:"this_flight"`
+		TypicalForRoute   int `json:"typical_for_this_route"`
+		DifferencePercent int `json:"difference_percent"`
+	} `json:"carbon_emissions"`
+}
+
+type serpPriceInsights struct {
+	LowestPrice       int        `json:"lowest_price"`
+	PriceLevel        string     `json:"price_level"`
+	TypicalPriceRange []int      `json:"typical_price_range"`
+	PriceHistory      [][2]int64 `json:"price_history"`
+}
+
+// serpBookingOption sells the whole trip (Together) or, for separate tickets,
+// one direction each.
+type serpBookingOption struct {
+	Together  *serpBookingOffer `json:"together"`
+	Departing *serpBookingOffer `json:"departing"`
+	Returning *serpBookingOffer `json:"returning"`
+}
+
+type serpBookingOffer struct {
+	BookWith       string `json:"book_with"`
+	Price          int    `json:"price"`
+	BookingRequest struct {
+		URL      string `json:"url"`
+		PostData string `json:"post_data"`
+	} `json:"booking_request"`
+}
+
+type serpAirport struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Time string `json:"time"`
+}
+
+type serpSegment struct {
+	Airline      string      `json:"airline"`
+	FlightNumber string      `json:"flight_number"`
+	Airplane     string      `json:"airplane"`
+	Legroom      string      `json:"legroom"`
+	Overnight    bool        `json:"overnight"`
+	Departure    serpAirport `json:"departure_airport"`
+	Arrival      serpAirport `json:"arrival_airport"`
+	Duration     int         `json:"duration"`
+}
+
+type serpLayover struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Duration  int    `json:"duration"`
+	Overnight bool   `json:"overnight"`
+}
+
+// Search honours ctx across all attempts and backoff sleeps; Timeout only
+// bounds each individual HTTP attempt.
+func (p SerpAPIProvider) Search(ctx context.Context, query model.SearchQuery) (model.SearchResult, error) {
 	if p.APIKey == "" {
 		return model.SearchResult{}, fmt.Errorf("%w: serpapi key missing: set GFLIGHT_SERPAPI_KEY or config.serp_api_key", ErrAuthRequired)
 	}
@@ -61,10 +178,13 @@ func (p SerpAPIProvider) Search(query model.SearchQuery) (model.SearchResult, er
 	if client == nil {
 		client = &http.Client{Timeout: p.resolvedTimeout()}
 	}
-	endpoint := buildSerpURL(p.baseURL(), query, p.APIKey)
+	endpoint, err := buildSerpURL(p.baseURL(), query, p.APIKey)
+	if err != nil {
+		return model.SearchResult{}, err
+	}
 
 	var payload serpResponse
-	if err := p.fetchWithRetry(client, endpoint, &payload); err != nil {
+	if err := p.fetchWithRetry(ctx, client, endpoint, &payload); err != nil {
 		return model.SearchResult{}, err
 	}
 
@@ -76,9 +196,12 @@ func (p SerpAPIProvider) Search(query model.SearchQuery) (model.SearchResult, er
 		}
 		flights = append(flights, f)
 	}
-	sort.Slice(flights, func(i, j int) bool {
-		return flights[i].Price < flights[j].Price
-	})
+	// Other orders come back ranked by SerpAPI itself.
+	if order, _ := model.ParseSortOrder(string(query.SortBy)); order == "" || order == model.SortPrice {
+		sort.SliceStable(flights, func(i, j int) bool {
+			return flights[i].Price < flights[j].Price
+		})
+	}
 	result := model.SearchResult{
 		Query:     query,
 		Flights:   flights,
@@ -88,31 +211,175 @@ func (p SerpAPIProvider) Search(query model.SearchQuery) (model.SearchResult, er
 	if result.URL == "" {
 		result.URL = buildGoogleFlightsURL(query)
 	}
+	result.Insights = mapSerpInsights(payload.PriceInsights)
+	if query.Return != "" {
+		if err := p.resolveReturns(ctx, client, endpoint, &result, p.returnLookupsFor(query)); err != nil {
+			return model.SearchResult{}, err
+		}
+	}
+	if query.Booking {
+		if err := p.resolveBookings(ctx, client, endpoint, &result); err != nil {
+			return model.SearchResult{}, err
+		}
+	}
 	return result, nil
 }
 
-func (p SerpAPIProvider) fetchWithRetry(client *http.Client, endpoint string, out *serpResponse) error {
+// resolveBookings follows the booking tokens of the leading itineraries. For
+// round trips the token sits on the first resolved return option, which
+// Search fetches automatically when Booking is set. Failures are handled like
+// resolveReturns.
+func (p SerpAPIProvider) resolveBookings(ctx context.Context, client *http.Client, endpoint string, res *model.SearchResult) error {
+	lookups := p.BookingLookupLimit()
+	for i := range res.Flights {
+		if lookups == 0 {
+			break
+		}
+		f := &res.Flights[i]
+		if len(f.ReturnOptions) > 0 {
+			f = &f.ReturnOptions[0]
+		}
+		if f.BookingToken == "" {
+			continue
+		}
+		lookups--
+		var payload serpResponse
+		err := p.fetchWithRetry(ctx, client, endpoint+"&booking_token="+url.QueryEscape(f.BookingToken), &payload)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, ErrAuthRequired) {
+				return err
+			}
+			res.Warnings = append(res.Warnings, fmt.Sprintf("booking options for %s %s: %v", f.Airline, f.DepartTime, err))
+			continue
+		}
+		f.BookingOptions = mapSerpBookingOptions(payload.BookingOptions, f.Currency)
+		f.DeepLink = bestBookingURL(f.BookingOptions)
+	}
+	return nil
+}
+
+func mapSerpInsights(raw *serpPriceInsights) *model.PriceInsights {
+	if raw == nil {
+		return nil
+	}
+	in := &model.PriceInsights{
+		LowestPrice: raw.LowestPrice,
+		PriceLevel:  strings.ToLower(raw.PriceLevel),
+	}
+	if len(raw.TypicalPriceRange) == 2 {
+		in.TypicalLow, in.TypicalHigh = raw.TypicalPriceRange[0], raw.TypicalPriceRange[1]
+	}
+	for _, p := range raw.PriceHistory {
+		in.History = append(in.History, model.PricePoint{Date: time.Unix(p[0], 0).UTC(), Price: int(p[1])})
+	}
+	return in
+}
+
+func mapSerpBookingOptions(raw []serpBookingOption, currency string) []model.BookingOption {
+	out := []model.BookingOption{}
+	add := func(o *serpBookingOffer, leg string) {
+		if o == nil {
+			return
+		}
+		link := o.BookingRequest.URL
+		if link != "" && o.BookingRequest.PostData != "" {
+			link += "?" + o.BookingRequest.PostData
+		}
+		out = append(out, model.BookingOption{Seller: o.BookWith, Price: o.Price, Currency: currency, URL: link, Leg: leg})
+	}
+	for _, r := range raw {
+		add(r.Together, "")
+		add(r.Departing, "outbound")
+		add(r.Returning, "return")
+	}
+	return out
+}
+
+// bestBookingURL prefers the cheapest seller of the whole trip.
+func bestBookingURL(opts []model.BookingOption) string {
+	best := -1
+	for i, o := range opts {
+		if o.URL == "" || o.Leg != "" {
+			continue
+		}
+		if best < 0 || (o.Price > 0 && (opts[best].Price == 0 || o.Price < opts[best].Price)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		for _, o := range opts {
+			if o.URL != "" {
+				return o.URL
+			}
+		}
+		return ""
+	}
+	return opts[best].URL
+}
+
+// resolveReturns follows the departure tokens of up to lookups leading
+// outbound flights and attaches their return options. A failed lookup becomes
+// a warning; only auth failures and cancellation abort the search.
+func (p SerpAPIProvider) resolveReturns(ctx context.Context, client *http.Client, endpoint string, res *model.SearchResult, lookups int) error {
+	returnQuery := res.Query
+	returnQuery.From, returnQuery.To = res.Query.To, res.Query.From
+	for i := range res.Flights {
+		if lookups == 0 {
+			break
+		}
+		f := &res.Flights[i]
+		if f.DepartureToken == "" {
+			continue
+		}
+		lookups--
+		var payload serpResponse
+		err := p.fetchWithRetry(ctx, client, endpoint+"&departure_token="+url.QueryEscape(f.DepartureToken), &payload)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, ErrAuthRequired) {
+				return err
+			}
+			res.Warnings = append(res.Warnings, fmt.Sprintf("return options for %s %s: %v", f.Airline, f.DepartTime, err))
+			continue
+		}
+		f.ReturnOptions = []model.Flight{}
+		for _, item := range append(payload.BestFlights, payload.OtherFlights...) {
+			f.ReturnOptions = append(f.ReturnOptions, mapSerpFlight(returnQuery, item))
+		}
+	}
+	return nil
+}
+
+func (p SerpAPIProvider) fetchWithRetry(ctx context.Context, client *http.Client, endpoint string, out *serpResponse) error {
 	attempts := p.resolvedRetries() + 1
 	for attempt := 0; attempt < attempts; attempt++ {
-		err := p.fetchOnce(client, endpoint, out)
+		err := p.fetchOnce(ctx, client, endpoint, out)
 		if err == nil {
 			return nil
 		}
 		if !isRetryable(err) || attempt == attempts-1 {
 			return err
 		}
-		time.Sleep(p.retryDelay(attempt))
+		t := time.NewTimer(p.retryDelay(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return fmt.Errorf("serpapi request abandoned during retry backoff: %w", ctx.Err())
+		case <-t.C:
+		}
 	}
 	return fmt.Errorf("%w: exhausted retries", ErrTransient)
 }
 
-func (p SerpAPIProvider) fetchOnce(client *http.Client, endpoint string, out *serpResponse) error {
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+func (p SerpAPIProvider) fetchOnce(ctx context.Context, client *http.Client, endpoint string, out *serpResponse) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return err
 	}
 	resp, err := client.Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("serpapi request abandoned: %w", ctx.Err())
+		}
 		if isNetworkTransient(err) {
 			return fmt.Errorf("%w: %v", ErrTransient, err)
 		}
@@ -192,7 +459,43 @@ func (p SerpAPIProvider) baseURL() string {
 	return "https://serpapi.com"
 }
 
-func buildSerpURL(baseURL string, query model.SearchQuery, apiKey string) string {
+// SerpAPI google_flights codes for travel_class, sort_by and stops.
+var (
+	serpTravelClass = map[model.Cabin]string{
+		model.CabinEconomy:        "1",
+		model.CabinPremiumEconomy: "2",
+		model.CabinBusiness:       "3",
+		model.CabinFirst:          "4",
+	}
+	serpSortBy = map[model.SortOrder]string{
+		model.SortBest:          "1",
+		model.SortPrice:         "2",
+		model.SortDepartureTime: "3",
+		model.SortArrivalTime:   "4",
+		model.SortDuration:      "5",
+		model.SortEmissions:     "6",
+	}
+	serpStops = map[model.Stops]string{
+		model.StopsAny:     "0",
+		model.StopsNonstop: "1",
+		model.StopsMaxOne:  "2",
+		model.StopsMaxTwo:  "3",
+	}
+)
+
+func buildSerpURL(baseURL string, query model.SearchQuery, apiKey string) (string, error) {
+	cabin, err := model.ParseCabin(string(query.Cabin))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsupportedQuery, err)
+	}
+	order, err := model.ParseSortOrder(string(query.SortBy))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsupportedQuery, err)
+	}
+	stops, err := model.ParseStops(string(query.MaxStops()))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsupportedQuery, err)
+	}
 	v := url.Values{}
 	v.Set("engine", "google_flights")
 	v.Set("api_key", apiKey)
@@ -204,35 +507,81 @@ func buildSerpURL(baseURL string, query model.SearchQuery, apiKey string) string
 	}
 	v.Set("adults", strconv.Itoa(maxInt(query.Adults, 1)))
 	v.Set("children", strconv.Itoa(maxInt(query.Children, 0)))
-	if query.Cabin != "" {
-		v.Set("travel_class", query.Cabin)
+	if cabin != "" {
+		v.Set("travel_class", serpTravelClass[cabin])
+	}
+	if order != "" {
+		v.Set("sort_by", serpSortBy[order])
 	}
-	if query.Nonstop {
-		v.Set("stops", "0")
+	if stops != "" && stops != model.StopsAny {
+		v.Set("stops", serpStops[stops])
 	}
 	if query.Currency != "" {
 		v.Set("currency", query.Currency)
 	}
-	return strings.TrimRight(baseURL, "/") + "/search.json?" + v.Encode()
+	return strings.TrimRight(baseURL, "/") + "/search.json?" + v.Encode(), nil
 }
 
 func mapSerpFlight(query model.SearchQuery, raw serpFlight) model.Flight {
 	f := model.Flight{
-		Provider: "serpapi",
-		From:     query.From,
-		To:       query.To,
-		Price:    raw.Price,
-		Currency: firstOr(query.Currency, "USD"),
-		Stops:    len(raw.Layovers),
-	}
-	if len(raw.Flights) > 0 {
-		f.Airline = raw.Flights[0].Airline
-		f.FlightNumber = raw.Flights[0].FlightNumber
-		f.DepartTime = raw.Flights[0].Departure.Time
-		f.ArriveTime = raw.Flights[len(raw.Flights)-1].Arrival.Time
-		dur := raw.Flights[0].Duration
-		if dur > 0 {
-			f.Duration = fmt.Sprintf("%dm", dur)
+		Provider:       "serpapi",
+		From:           query.From,
+		To:             query.To,
+		Price:          raw.Price,
+		Currency:       firstOr(query.Currency, "USD"),
+		Stops:          len(raw.Layovers),
+		DepartureToken: raw.DepartureToken,
+		BookingToken:   raw.BookingToken,
+	}
+	legs := 0
+	for _, s := range raw.Flights {
+		f.Segments = append(f.Segments, model.Segment{
+			From:            s.Departure.ID,
+			FromName:        s.Departure.Name,
+			To:              s.Arrival.ID,
+			ToName:          s.Arrival.Name,
+			DepartTime:      s.Departure.Time,
+			ArriveTime:      s.Arrival.Time,
+			Airline:         s.Airline,
+			FlightNumber:    s.FlightNumber,
+			Aircraft:        s.Airplane,
+			DurationMinutes: s.Duration,
+			Legroom:         s.Legroom,
+			Overnight:       s.Overnight,
+		})
+		legs += s.Duration
+	}
+	for _, l := range raw.Layovers {
+		f.Layovers = append(f.Layovers, model.Layover{
+			Airport:         l.ID,
+			Name:            l.Name,
+			DurationMinutes: l.Duration,
+			Overnight:       l.Overnight,
+		})
+		legs += l.Duration
+	}
+	if n := len(f.Segments); n > 0 {
+		first, last := f.Segments[0], f.Segments[n-1]
+		f.Airline = first.Airline
+		f.FlightNumber = first.FlightNumber
+		f.DepartTime = first.DepartTime
+		f.ArriveTime = last.ArriveTime
+		f.From = firstOr(first.From, f.From)
+		f.To = firstOr(last.To, f.To)
+	}
+	// Older responses omit total_duration; segments plus layovers add up to it.
+	f.TotalDurationMinutes = raw.TotalDuration
+	if f.TotalDurationMinutes == 0 {
+		f.TotalDurationMinutes = legs
+	}
+	if f.TotalDurationMinutes > 0 {
+		f.Duration = fmt.Sprintf("%dm", f.TotalDurationMinutes)
+	}
+	if e := raw.Emissions; e != nil && e.ThisFlight > 0 {
+		f.Emissions = &model.Emissions{
+			Grams:             e.ThisFlight,
+			TypicalGrams:      e.TypicalForRoute,
+			DifferencePercent: e.DifferencePercent,
 		}
 	}
 	if f.Airline == "" && raw.AirlineLogo != "" {
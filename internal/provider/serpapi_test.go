@@ -1,9 +1,12 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -34,7 +37,7 @@ func TestSerpAPIRetriesTransientAndSucceeds(t *testing.T) {
 		Timeout: 2 * time.Second,
 		Client:  &http.Client{Timeout: 2 * time.Second},
 	}
-	res, err := p.Search(model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10", Currency: "USD"})
+	res, err := p.Search(context.Background(), model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10", Currency: "USD"})
 	if err != nil {
 		t.Fatalf("search should succeed after retry: %v", err)
 	}
@@ -54,7 +57,7 @@ func TestSerpAPIClassifiesAuthError(t *testing.T) {
 	defer srv.Close()
 
 	p := SerpAPIProvider{APIKey: "bad", BaseURL: srv.URL, Retries: 2, Backoff: time.Millisecond}
-	_, err := p.Search(model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"})
+	_, err := p.Search(context.Background(), model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"})
 	if err == nil {
 		t.Fatalf("expected auth error")
 	}
@@ -73,7 +76,7 @@ func TestSerpAPIClassifiesRateLimitAndRetries(t *testing.T) {
 	defer srv.Close()
 
 	p := SerpAPIProvider{APIKey: "k", BaseURL: srv.URL, Retries: 1, Backoff: time.Millisecond}
-	_, err := p.Search(model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"})
+	_, err := p.Search(context.Background(), model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"})
 	if err == nil {
 		t.Fatalf("expected rate limit error")
 	}
@@ -94,7 +97,7 @@ func TestSerpAPITimeoutIsTransient(t *testing.T) {
 	defer srv.Close()
 
 	p := SerpAPIProvider{APIKey: "k", BaseURL: srv.URL, Retries: 0, Timeout: 20 * time.Millisecond, Client: &http.Client{Timeout: 20 * time.Millisecond}}
-	_, err := p.Search(model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"})
+	_, err := p.Search(context.Background(), model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"})
 	if err == nil {
 		t.Fatalf("expected timeout error")
 	}
@@ -103,8 +106,52 @@ func TestSerpAPITimeoutIsTransient(t *testing.T) {
 	}
 }
 
+func TestSerpAPICancelStopsRetryBackoff(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	p := SerpAPIProvider{APIKey: "k", BaseURL: srv.URL, Retries: 3, Backoff: time.Hour}
+	start := time.Now()
+	_, err := p.Search(ctx, model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("cancel did not interrupt backoff (took %s)", elapsed)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected no retry after cancel, got %d attempts", calls)
+	}
+}
+
+func TestSerpAPIDeadlineAbandonsInFlightRequest(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	p := SerpAPIProvider{APIKey: "k", BaseURL: srv.URL, Retries: 2, Backoff: time.Millisecond, Timeout: time.Minute}
+	_, err := p.Search(ctx, model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"})
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTransient) {
+		t.Fatalf("expected a non-retryable deadline error, got %v", err)
+	}
+}
+
 func TestBuildSerpURLUsesBasePath(t *testing.T) {
-	got := buildSerpURL("https://example.com", model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10", Adults: 1}, "key")
+	got, _ := buildSerpURL("https://example.com", model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10", Adults: 1}, "key")
 	wantPrefix := "https://example.com/search.json?"
 	if got[:len(wantPrefix)] != wantPrefix {
 		t.Fatalf("expected prefix %q, got %q", wantPrefix, got)
@@ -113,3 +160,251 @@ func TestBuildSerpURLUsesBasePath(t *testing.T) {
 		t.Fatalf("expected api key in url: %s", got)
 	}
 }
+
+func TestBuildSerpURLMapsCabinSortAndStops(t *testing.T) {
+	tests := []struct {
+		name  string
+		query model.SearchQuery
+		want  map[string]string
+	}{
+		{
+			name:  "business by duration",
+			query: model.SearchQuery{Cabin: "business", SortBy: "duration"},
+			want:  map[string]string{"travel_class": "3", "sort_by": "5", "stops": ""},
+		},
+		{
+			name:  "premium alias sorted by price",
+			query: model.SearchQuery{Cabin: "Premium-Economy", SortBy: "price"},
+			want:  map[string]string{"travel_class": "2", "sort_by": "2"},
+		},
+		{
+			name:  "legacy nonstop flag",
+			query: model.SearchQuery{Nonstop: true},
+			want:  map[string]string{"stops": "1", "travel_class": "", "sort_by": ""},
+		},
+		{
+			name:  "stops overrides nonstop",
+			query: model.SearchQuery{Nonstop: true, Stops: model.StopsMaxOne},
+			want:  map[string]string{"stops": "2"},
+		},
+		{
+			name:  "any stops is omitted",
+			query: model.SearchQuery{Stops: model.StopsAny, SortBy: "emissions"},
+			want:  map[string]string{"stops": "", "sort_by": "6"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.query.From, tc.query.To, tc.query.Depart = "SFO", "ATH", "2026-06-10"
+			raw, err := buildSerpURL("https://example.com", tc.query, "key")
+			if err != nil {
+				t.Fatalf("buildSerpURL: %v", err)
+			}
+			u, err := url.Parse(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, want := range tc.want {
+				if got := u.Query().Get(k); got != want {
+					t.Fatalf("%s = %q, want %q (%s)", k, got, want, raw)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildSerpURLRejectsUnknownValues(t *testing.T) {
+	_, err := buildSerpURL("https://example.com", model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10", Cabin: "steerage"}, "key")
+	if !errors.Is(err, ErrUnsupportedQuery) {
+		t.Fatalf("expected ErrUnsupportedQuery, got %v", err)
+	}
+}
+
+func TestMapSerpFlightKeepsSegmentsLayoversAndEmissions(t *testing.T) {
+	var raw serpFlight
+	body := `{
+		"price": 812,
+		"flights": [
+			{"airline":"Lufthansa","flight_number":"LH 455","airplane":"Airbus A350","legroom":"31 in","duration":660,
+			 "departure_airport":{"id":"SFO","name":"San Francisco","time":"2026-06-10 15:40"},
+			 "arrival_airport":{"id":"FRA","name":"Frankfurt","time":"2026-06-11 11:40"}},
+			{"airline":"Lufthansa","flight_number":"LH 1284","airplane":"Airbus A321","duration":170,
+			 "departure_airport":{"id":"FRA","name":"Frankfurt","time":"2026-06-11 13:15"},
+			 "arrival_airport":{"id":"ATH","name":"Athens","time":"2026-06-11 17:05"}}
+		],
+		"layovers": [{"id":"FRA","name":"Frankfurt","duration":95}],
+		"total_duration": 925,
+		"carbon_emissions": {"this_flight":612000,"typical_for_this_route":580000,"difference_percent":6}
+	}`
+	if err := json.Unmarshal([]byte(body), &raw); err != nil {
+		t.Fatal(err)
+	}
+	f := mapSerpFlight(model.SearchQuery{From: "SFO", To: "ATH", Currency: "EUR"}, raw)
+	if len(f.Segments) != 2 || f.Segments[1].From != "FRA" || f.Segments[0].Aircraft != "Airbus A350" || f.Segments[1].DurationMinutes != 170 {
+		t.Fatalf("unexpected segments: %+v", f.Segments)
+	}
+	if len(f.Layovers) != 1 || f.Layovers[0].Airport != "FRA" || f.Layovers[0].DurationMinutes != 95 || f.Stops != 1 {
+		t.Fatalf("unexpected layovers: %+v", f.Layovers)
+	}
+	if f.TotalDurationMinutes != 925 || f.Duration != "925m" {
+		t.Fatalf("expected total duration, got %d %q", f.TotalDurationMinutes, f.Duration)
+	}
+	if f.Emissions == nil || f.Emissions.Grams != 612000 || f.Emissions.DifferencePercent != 6 {
+		t.Fatalf("unexpected emissions: %+v", f.Emissions)
+	}
+	if f.DepartTime != "2026-06-10 15:40" || f.ArriveTime != "2026-06-11 17:05" || f.FlightNumber != "LH 455" {
+		t.Fatalf("unexpected summary fields: %+v", f)
+	}
+
+	raw.TotalDuration = 0
+	if got := mapSerpFlight(model.SearchQuery{}, raw).TotalDurationMinutes; got != 925 {
+		t.Fatalf("expected duration summed from segments and layovers, got %d", got)
+	}
+}
+
+func TestSerpAPIFollowsDepartureTokensUpToCap(t *testing.T) {
+	var lookups atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tok := r.URL.Query().Get("departure_token"); tok != "" {
+			lookups.Add(1)
+			if tok == "bad" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"best_flights":[{"price":1400,"flights":[{"airline":"Aegean","departure_airport":{"id":"ATH","time":"2026-06-24 08:00"},"arrival_airport":{"id":"SFO","time":"2026-06-24 19:00"}}]}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"best_flights":[
+			{"price":650,"departure_token":"t1","flights":[{"airline":"Aegean"}]},
+			{"price":700,"departure_token":"bad","flights":[{"airline":"United"}]},
+			{"price":720,"departure_token":"t3","flights":[{"airline":"Delta"}]}
+		]}`))
+	}))
+	defer srv.Close()
+
+	p := SerpAPIProvider{APIKey: "k", BaseURL: srv.URL, Client: srv.Client(), ReturnLookups: 5, MaxReturnLookups: 2}
+	res, err := p.Search(context.Background(), model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10", Return: "2026-06-24"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if lookups.Load() != 2 {
+		t.Fatalf("expected lookups capped at 2, got %d", lookups.Load())
+	}
+	ret := res.Flights[0].ReturnOptions
+	if len(ret) != 1 || ret[0].Price != 1400 || ret[0].From != "ATH" || ret[0].To != "SFO" {
+		t.Fatalf("unexpected return options: %+v", ret)
+	}
+	if res.Flights[1].ReturnOptions != nil || res.Flights[2].ReturnOptions != nil || len(res.Warnings) != 1 {
+		t.Fatalf("expected failed lookup as warning and third option unresolved: %+v %v", res.Flights, res.Warnings)
+	}
+
+	lookups.Store(0)
+	p.ReturnLookups = 0
+	if _, err := p.Search(context.Background(), model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10", Return: "2026-06-24"}); err != nil || lookups.Load() != 0 {
+		t.Fatalf("expected no lookups by default, got %d (%v)", lookups.Load(), err)
+	}
+}
+
+func TestSerpAPIResolvesBookingOptions(t *testing.T) {
+	var lookups atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("booking_token") != "" {
+			lookups.Add(1)
+			_, _ = w.Write([]byte(`{"booking_options":[
+				{"together":{"book_with":"Expedia","price":660,"booking_request":{"url":"https://www.google.com/travel/clk/f","post_data":"u=exp"}}},
+				{"together":{"book_with":"Aegean","price":650,"booking_request":{"url":"https://www.google.com/travel/clk/f","post_data":"u=a3"}}},
+				{"separate_tickets":true,"departing":{"book_with":"Ryanair","price":300,"booking_request":{"url":"https://r.example"}}}
+			]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"best_flights":[
+			{"price":650,"booking_token":"b1","flights":[{"airline":"Aegean"}]},
+			{"price":700,"booking_token":"b2","flights":[{"airline":"United"}]}
+		]}`))
+	}))
+	defer srv.Close()
+
+	p := SerpAPIProvider{APIKey: "k", BaseURL: srv.URL, Client: srv.Client(), MaxBookingLookups: 1}
+	q := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10", Currency: "EUR"}
+	res, err := p.Search(context.Background(), q)
+	if err != nil || lookups.Load() != 0 || res.Flights[0].BookingOptions != nil {
+		t.Fatalf("expected no booking lookups without Booking, got %d (%v)", lookups.Load(), err)
+	}
+
+	q.Booking = true
+	res, err = p.Search(context.Background(), q)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if lookups.Load() != 1 {
+		t.Fatalf("expected one capped booking lookup, got %d", lookups.Load())
+	}
+	f := res.Flights[0]
+	if len(f.BookingOptions) != 3 || f.BookingOptions[2].Leg != "outbound" || f.BookingOptions[0].Currency != "EUR" {
+		t.Fatalf("unexpected booking options: %+v", f.BookingOptions)
+	}
+	if f.DeepLink != "https://www.google.com/travel/clk/f?u=a3" {
+		t.Fatalf("expected cheapest whole-trip seller as deep link, got %q", f.DeepLink)
+	}
+	if res.Flights[1].DeepLink != "" {
+		t.Fatalf("expected second flight unresolved: %+v", res.Flights[1])
+	}
+}
+
+func TestSerpAPIBookingFollowsReturnsOnRoundTrips(t *testing.T) {
+	var returns, bookings atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Query().Get("booking_token") != "":
+			bookings.Add(1)
+			_, _ = w.Write([]byte(`{"booking_options":[{"together":{"book_with":"Aegean","price":1400,"booking_request":{"url":"https://www.google.com/travel/clk/f","post_data":"u=a3"}}}]}`))
+		case r.URL.Query().Get("departure_token") != "":
+			returns.Add(1)
+			_, _ = w.Write([]byte(`{"best_flights":[{"price":1400,"booking_token":"b1","flights":[{"airline":"Aegean"}]}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"best_flights":[
+				{"price":650,"departure_token":"t1","flights":[{"airline":"Aegean"}]},
+				{"price":700,"departure_token":"t2","flights":[{"airline":"United"}]},
+				{"price":720,"departure_token":"t3","flights":[{"airline":"Delta"}]}
+			]}`))
+		}
+	}))
+	defer srv.Close()
+
+	p := SerpAPIProvider{APIKey: "k", BaseURL: srv.URL, Client: srv.Client(), MaxReturnLookups: 2}
+	q := model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10", Return: "2026-06-24", Booking: true}
+	res, err := p.Search(context.Background(), q)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if returns.Load() != 2 || bookings.Load() != 2 {
+		t.Fatalf("expected booking to follow 2 departure tokens within MaxReturnLookups, got returns=%d bookings=%d", returns.Load(), bookings.Load())
+	}
+	if got := res.Flights[0].ReturnOptions; len(got) != 1 || got[0].DeepLink != "https://www.google.com/travel/clk/f?u=a3" {
+		t.Fatalf("expected booking options on the first return option, got %+v", got)
+	}
+	if res.Flights[2].ReturnOptions != nil {
+		t.Fatalf("expected third outbound unresolved: %+v", res.Flights[2])
+	}
+}
+
+func TestSerpAPIMapsPriceInsights(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"best_flights":[{"price":650,"flights":[{"airline":"Aegean"}]}],
+			"price_insights":{"lowest_price":650,"price_level":"Low","typical_price_range":[700,900],"price_history":[[1780000000,880],[1780086400,720]]}}`))
+	}))
+	defer srv.Close()
+
+	p := SerpAPIProvider{APIKey: "k", BaseURL: srv.URL, Client: srv.Client()}
+	res, err := p.Search(context.Background(), model.SearchQuery{From: "SFO", To: "ATH", Depart: "2026-06-10"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	in := res.Insights
+	if in == nil || in.LowestPrice != 650 || in.PriceLevel != model.PriceLevelLow || in.TypicalLow != 700 || in.TypicalHigh != 900 {
+		t.Fatalf("unexpected insights: %+v", in)
+	}
+	if len(in.History) != 2 || in.History[1].Price != 720 || !in.History[0].Date.Equal(time.Unix(1780000000, 0)) {
+		t.Fatalf("unexpected price history: %+v", in.History)
+	}
+}
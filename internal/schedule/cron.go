@@ -0,0 +1,206 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed five-field cron expression
+// (minute hour day-of-month month day-of-week).
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	domStar bool
+	dowStar bool
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day-of-month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day-of-week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// searchLimit bounds Next for expressions that can never fire (e.g. "0 0 31 2 *").
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+func Parse(expr string) (Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if m, ok := macros[strings.ToLower(spec)]; ok {
+		spec = m
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return Schedule{}, fmt.Errorf("invalid cron schedule %q: expected 5 fields or a macro like @hourly", expr)
+	}
+	var s Schedule
+	var err error
+	if s.minute, err = parseField(parts[0], minuteField); err != nil {
+		return Schedule{}, fmt.Errorf("invalid cron schedule %q: %w", expr, err)
+	}
+	if s.hour, err = parseField(parts[1], hourField); err != nil {
+		return Schedule{}, fmt.Errorf("invalid cron schedule %q: %w", expr, err)
+	}
+	if s.dom, err = parseField(parts[2], domField); err != nil {
+		return Schedule{}, fmt.Errorf("invalid cron schedule %q: %w", expr, err)
+	}
+	if s.month, err = parseField(parts[3], monthField); err != nil {
+		return Schedule{}, fmt.Errorf("invalid cron schedule %q: %w", expr, err)
+	}
+	if s.dow, err = parseField(parts[4], dowField); err != nil {
+		return Schedule{}, fmt.Errorf("invalid cron schedule %q: %w", expr, err)
+	}
+	// Sunday may be written as 0 or 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = strings.HasPrefix(parts[2], "*")
+	s.dowStar = strings.HasPrefix(parts[4], "*")
+	return s, nil
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		b, err := parseRange(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func parseRange(expr string, f field) (uint64, error) {
+	if expr == "" {
+		return 0, fmt.Errorf("empty %s value", f.name)
+	}
+	rangePart, stepPart, hasStep := strings.Cut(expr, "/")
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepPart)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid %s step %q", f.name, stepPart)
+		}
+		step = n
+	}
+
+	var lo, hi int
+	switch {
+	case rangePart == "*":
+		lo, hi = f.min, f.max
+		if f.name == dowField.name {
+			hi = 6
+		}
+	case strings.Contains(rangePart, "-"):
+		a, b, _ := strings.Cut(rangePart, "-")
+		var err error
+		if lo, err = parseValue(a, f); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(b, f); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("invalid %s range %q", f.name, rangePart)
+		}
+	default:
+		v, err := parseValue(rangePart, f)
+		if err != nil {
+			return 0, err
+		}
+		lo, hi = v, v
+		if hasStep {
+			hi = f.max
+		}
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func parseValue(v string, f field) (int, error) {
+	if n, ok := f.names[strings.ToLower(v)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", f.name, v)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("%s value %d out of range %d-%d", f.name, n, f.min, f.max)
+	}
+	return n, nil
+}
+
+// Next returns the first fire time strictly after t, in t's location.
+// It returns the zero time when the schedule never fires.
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches follows Vixie cron: when both day fields are restricted, a day
+// matches if either one does.
+func (s Schedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
@@ -0,0 +1,84 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	cases := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@every 5m",
+		"a * * * *",
+	}
+	for _, expr := range cases {
+		if _, err := Parse(expr); err == nil {
+			t.Fatalf("expected parse error for %q", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	base := time.Date(2026, 2, 19, 22, 7, 30, 0, time.UTC)
+	cases := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{expr: "* * * * *", from: base, want: time.Date(2026, 2, 19, 22, 8, 0, 0, time.UTC)},
+		{expr: "@hourly", from: base, want: time.Date(2026, 2, 19, 23, 0, 0, 0, time.UTC)},
+		{expr: "0 */4 * * *", from: base, want: time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 */4 * * *", from: time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC), want: time.Date(2026, 2, 20, 4, 0, 0, 0, time.UTC)},
+		{expr: "30 9 * * mon-fri", from: base, want: time.Date(2026, 2, 20, 9, 30, 0, 0, time.UTC)},
+		{expr: "30 9 * * mon-fri", from: time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC), want: time.Date(2026, 2, 23, 9, 30, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", from: base, want: time.Date(2026, 2, 22, 0, 0, 0, 0, time.UTC)},
+		{expr: "@monthly", from: base, want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 12 1,15 jun *", from: base, want: time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", from: base, want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "15-45/15 * * * *", from: base, want: time.Date(2026, 2, 19, 22, 15, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match.
+		{expr: "0 0 1 * fri", from: base, want: time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tc.expr, err)
+			}
+			if got := s.Next(tc.from); !got.Equal(tc.want) {
+				t.Fatalf("Next(%s) = %s, want %s", tc.from, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNextNeverFires(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Fatalf("expected zero time, got %s", got)
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("EET", 2*60*60)
+	s, err := Parse("@daily")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	got := s.Next(time.Date(2026, 2, 19, 22, 0, 0, 0, loc))
+	want := time.Date(2026, 2, 20, 0, 0, 0, 0, loc)
+	if !got.Equal(want) || got.Location() != loc {
+		t.Fatalf("expected %s in %s, got %s", want, loc, got)
+	}
+}
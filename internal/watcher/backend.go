@@ -0,0 +1,150 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+// Storage backends selectable with the storage_backend config key.
+const (
+	BackendJSON     = "json"
+	BackendJSONLDir = "jsonl-dir"
+)
+
+// Backend persists the watch set. Save is only safe while holding Lock;
+// Update wraps a whole read-modify-write transaction.
+type Backend interface {
+	Load() (model.WatchStore, error)
+	Save(ws model.WatchStore) error
+	Lock() (func(), error)
+	Update(fn func(*model.WatchStore) error) error
+	PendingMigrations() (MigrationResult, error)
+	Migrate() (MigrationResult, error)
+	// Location is the file or directory holding the watches.
+	Location() string
+}
+
+// HistoryBackend keeps each watch's price observations in recording order.
+type HistoryBackend interface {
+	Append(watchID string, entry model.PriceHistoryEntry) error
+	Load(watchID string) ([]model.PriceHistoryEntry, error)
+	// Oldest is the check time of the first entry, zero when there is none.
+	Oldest(watchID string) (time.Time, error)
+	Prune(watchID string, cutoff time.Time) (int, error)
+	Delete(watchID string) error
+}
+
+// SnapshotBackend keeps the latest evaluation of each watch.
+type SnapshotBackend interface {
+	Save(snap model.WatchSnapshot) error
+	Load(watchID string) (*model.WatchSnapshot, error)
+	Delete(watchID string) error
+}
+
+// RunLogBackend records a summary of every watch run pass, oldest first.
+type RunLogBackend interface {
+	Append(entry model.RunLogEntry) error
+	Load() ([]model.RunLogEntry, error)
+	Prune(cutoff time.Time) error
+}
+
+// Storage is everything gflight keeps in the state directory: the watch set,
+// per-watch price history, last-evaluation snapshots and the run log.
+type Storage struct {
+	Dir       string
+	Backend   string
+	Watches   Backend
+	History   HistoryBackend
+	Snapshots SnapshotBackend
+	RunLog    RunLogBackend
+	// roots are the files and directories this layout owns.
+	roots []string
+}
+
+// Backends lists the selectable storage backends.
+var Backends = []string{BackendJSON, BackendJSONLDir}
+
+// Open lays out storage for backend under stateDir:
+//
+//	json:      watches.json, history/<id>.jsonl, snapshots/<id>.json, runs.jsonl
+//	jsonl-dir: watches/<id>.jsonl, watches/<id>/history.jsonl,
+//	           watches/<id>/snapshot.json, runs/<YYYY-MM-DD>.jsonl
+func Open(stateDir, backend string) (Storage, error) {
+	switch backend {
+	case "", BackendJSON:
+		return Storage{
+			Backend:   BackendJSON,
+			Watches:   Store{Path: filepath.Join(stateDir, "watches.json")},
+			History:   HistoryStore{Dir: filepath.Join(stateDir, "history")},
+			Snapshots: SnapshotStore{Dir: filepath.Join(stateDir, "snapshots")},
+			RunLog:    RunLogFile{Path: filepath.Join(stateDir, "runs.jsonl")},
+			roots:     []string{"watches.json", "history", "snapshots", "runs.jsonl"},
+		}.rooted(stateDir), nil
+	case BackendJSONLDir:
+		dir := filepath.Join(stateDir, "watches")
+		return Storage{
+			Backend:   BackendJSONLDir,
+			Watches:   DirStore{Dir: dir},
+			History:   HistoryStore{Dir: dir, File: "history.jsonl"},
+			Snapshots: SnapshotStore{Dir: dir, File: "snapshot.json"},
+			RunLog:    RunLogDir{Dir: filepath.Join(stateDir, "runs")},
+			roots:     []string{"watches", "runs"},
+		}.rooted(stateDir), nil
+	}
+	return Storage{}, fmt.Errorf("unknown storage backend %q (use %s or %s)", backend, BackendJSON, BackendJSONLDir)
+}
+
+func (st Storage) rooted(stateDir string) Storage {
+	st.Dir = stateDir
+	for i, r := range st.roots {
+		st.roots[i] = filepath.Join(stateDir, r)
+	}
+	return st
+}
+
+// StrayWatches reports the other backend under stateDir that still holds
+// watches, and how many, so switching storage_backend does not silently hide
+// them. It returns "" when there is none.
+func StrayWatches(stateDir, backend string) (string, int, error) {
+	for _, other := range Backends {
+		if other == firstNonEmpty(backend, BackendJSON) {
+			continue
+		}
+		st, _ := Open(stateDir, other)
+		if _, err := os.Stat(st.Watches.Location()); err != nil {
+			continue
+		}
+		ws, err := st.Watches.Load()
+		if err != nil {
+			return other, 0, err
+		}
+		if len(ws.Watches) > 0 {
+			return other, len(ws.Watches), nil
+		}
+	}
+	return "", 0, nil
+}
+
+func firstNonEmpty(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
+
+// WithLockTimeout returns b with its lock wait bounded by d.
+func WithLockTimeout(b Backend, d time.Duration) Backend {
+	switch s := b.(type) {
+	case Store:
+		s.LockTimeout = d
+		return s
+	case DirStore:
+		s.LockTimeout = d
+		return s
+	}
+	return b
+}
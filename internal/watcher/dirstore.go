@@ -0,0 +1,294 @@
+package watcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+const (
+	tailChunk = 4096
+	// A watch file is compacted to its last keepRevisions revisions once it
+	// grows past compactBytes, so frequent runs cannot grow it without bound.
+	compactBytes  = 64 * 1024
+	keepRevisions = 10
+)
+
+// DirStore keeps each watch in its own append-only <Dir>/<id>.jsonl file.
+// Saving appends a revision line only for watches that changed, so unchanged
+// watches are never rewritten and the files diff cleanly under version
+// control. The last complete line is the current revision; older ones are
+// compacted away once the file grows large. Deleting a watch removes its file
+// and its directory of history and snapshots.
+type DirStore struct {
+	Dir string
+	// LockTimeout bounds how long Lock waits; zero means DefaultLockTimeout.
+	LockTimeout time.Duration
+}
+
+type watchRevision struct {
+	SchemaVersion int             `json:"schema_version"`
+	SavedAt       time.Time       `json:"saved_at"`
+	Watch         json.RawMessage `json:"watch"`
+}
+
+func (d DirStore) Load() (model.WatchStore, error) {
+	ws := model.WatchStore{SchemaVersion: CurrentSchemaVersion, Watches: []model.Watch{}}
+	revs, err := d.revisions()
+	if err != nil {
+		return ws, err
+	}
+	for id, rev := range revs {
+		w, err := decodeRevision(rev)
+		if err != nil {
+			return ws, fmt.Errorf("%s: %w", filepath.Join(d.Dir, id+".jsonl"), err)
+		}
+		if w.ID != id {
+			return ws, fmt.Errorf("%s: holds watch id %q", filepath.Join(d.Dir, id+".jsonl"), w.ID)
+		}
+		ws.Watches = append(ws.Watches, w)
+	}
+	sort.Slice(ws.Watches, func(i, j int) bool {
+		a, b := ws.Watches[i], ws.Watches[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return ws, nil
+}
+
+// Save appends a revision for every new or changed watch and removes the
+// files of watches no longer in ws.
+func (d DirStore) Save(ws model.WatchStore) error {
+	revs, err := d.revisions()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d.Dir, 0o755); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	keep := map[string]bool{}
+	for _, w := range ws.Watches {
+		path, err := watchFile(d.Dir, w.ID, ".jsonl")
+		if err != nil {
+			return err
+		}
+		keep[w.ID] = true
+		b, err := json.Marshal(w)
+		if err != nil {
+			return err
+		}
+		if old, ok := revs[w.ID]; ok && old.SchemaVersion == CurrentSchemaVersion && bytes.Equal(old.Watch, b) {
+			continue
+		}
+		line, err := json.Marshal(watchRevision{SchemaVersion: CurrentSchemaVersion, SavedAt: now, Watch: b})
+		if err != nil {
+			return err
+		}
+		if err := appendLine(path, line); err != nil {
+			return err
+		}
+		if err := compactRevisions(path); err != nil {
+			return err
+		}
+	}
+	for id := range revs {
+		if keep[id] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(d.Dir, id+".jsonl")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := os.RemoveAll(filepath.Join(d.Dir, id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d DirStore) Lock() (func(), error) {
+	return lockFile(d.Dir+".lock", d.LockTimeout)
+}
+
+func (d DirStore) Update(fn func(*model.WatchStore) error) error {
+	return update(d, fn)
+}
+
+// PendingMigrations reports the oldest revision schema in the directory.
+// Older revision lines are kept, so migrating needs no backup.
+func (d DirStore) PendingMigrations() (MigrationResult, error) {
+	revs, err := d.revisions()
+	if err != nil {
+		return MigrationResult{}, err
+	}
+	version := CurrentSchemaVersion
+	for _, rev := range revs {
+		version = min(version, rev.SchemaVersion)
+		if rev.SchemaVersion > CurrentSchemaVersion {
+			version = rev.SchemaVersion
+			break
+		}
+	}
+	pending, err := pendingMigrations(version)
+	if err != nil {
+		return MigrationResult{}, err
+	}
+	return MigrationResult{FromVersion: version, ToVersion: CurrentSchemaVersion, Pending: pending}, nil
+}
+
+func (d DirStore) Migrate() (MigrationResult, error) {
+	return migrate(d)
+}
+
+func (d DirStore) Location() string {
+	return d.Dir
+}
+
+// revisions returns the current revision of every watch file keyed by ID.
+func (d DirStore) revisions() (map[string]watchRevision, error) {
+	entries, err := os.ReadDir(d.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]watchRevision{}, nil
+		}
+		return nil, err
+	}
+	revs := map[string]watchRevision{}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if !ok || e.IsDir() {
+			continue
+		}
+		path := filepath.Join(d.Dir, e.Name())
+		line, err := lastLine(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			return nil, fmt.Errorf("%s: no complete revision", path)
+		}
+		var rev watchRevision
+		if err := json.Unmarshal(line, &rev); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		revs[id] = rev
+	}
+	return revs, nil
+}
+
+// decodeRevision migrates a revision to the current schema and decodes it.
+func decodeRevision(rev watchRevision) (model.Watch, error) {
+	var w model.Watch
+	pending, err := pendingMigrations(rev.SchemaVersion)
+	if err != nil {
+		return w, err
+	}
+	raw := []byte(rev.Watch)
+	if len(pending) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var watch any
+		if err := dec.Decode(&watch); err != nil {
+			return w, err
+		}
+		doc := map[string]any{"schema_version": rev.SchemaVersion, "watches": []any{watch}}
+		if err := applyMigrations(doc, pending); err != nil {
+			return w, err
+		}
+		if raw, err = json.Marshal(doc["watches"].([]any)[0]); err != nil {
+			return w, err
+		}
+	}
+	err = json.Unmarshal(raw, &w)
+	return w, err
+}
+
+// lastLine returns the last newline-terminated line of path, ignoring a torn
+// final write.
+func lastLine(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var buf []byte
+	for off := info.Size(); off > 0; {
+		n := min(int64(tailChunk), off)
+		off -= n
+		chunk := make([]byte, n)
+		if _, err := f.ReadAt(chunk, off); err != nil {
+			return nil, err
+		}
+		buf = append(chunk, buf...)
+		end := bytes.LastIndexByte(buf, '\n')
+		if end < 0 {
+			continue
+		}
+		start := bytes.LastIndexByte(buf[:end], '\n')
+		if start >= 0 || off == 0 {
+			return bytes.TrimSpace(buf[start+1 : end]), nil
+		}
+	}
+	return nil, nil
+}
+
+// compactRevisions rewrites a watch file that has grown past compactBytes to
+// its last keepRevisions complete revisions.
+func compactRevisions(path string) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() <= compactBytes {
+		return err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	end := bytes.LastIndexByte(b, '\n')
+	if end < 0 {
+		return nil
+	}
+	lines := bytes.Split(b[:end], []byte("\n"))
+	if len(lines) <= keepRevisions {
+		return nil
+	}
+	kept := bytes.Join(lines[len(lines)-keepRevisions:], []byte("\n"))
+	return writeFileAtomic(path, append(kept, '\n'), 0o600)
+}
+
+// appendLine appends line to path, starting a fresh line if a previous write
+// was torn.
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
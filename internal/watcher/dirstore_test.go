@@ -0,0 +1,116 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestDirStoreAppendsOnlyChangedWatches(t *testing.T) {
+	d := DirStore{Dir: filepath.Join(t.TempDir(), "watches")}
+	base := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	ws := model.WatchStore{Watches: []model.Watch{
+		{ID: "w_2", Name: "rome", CreatedAt: base.Add(time.Hour)},
+		{ID: "w_1", Name: "athens", CreatedAt: base},
+	}}
+	if err := d.Save(ws); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	ws.Watches[0].LastLowestPrice = 640
+	if err := d.Save(ws); err != nil {
+		t.Fatalf("save changed: %v", err)
+	}
+	lines := func(id string) int {
+		b, err := os.ReadFile(filepath.Join(d.Dir, id+".jsonl"))
+		if err != nil {
+			t.Fatalf("read %s: %v", id, err)
+		}
+		return strings.Count(string(b), "\n")
+	}
+	if lines("w_1") != 1 || lines("w_2") != 2 {
+		t.Fatalf("expected only w_2 to gain a revision, got w_1=%d w_2=%d", lines("w_1"), lines("w_2"))
+	}
+
+	got, err := d.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got.Watches) != 2 || got.Watches[0].ID != "w_1" || got.Watches[1].LastLowestPrice != 640 {
+		t.Fatalf("unexpected load: %+v", got.Watches)
+	}
+
+	ws.Watches = ws.Watches[:1]
+	if err := d.Save(ws); err != nil {
+		t.Fatalf("save after delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(d.Dir, "w_1.jsonl")); !os.IsNotExist(err) {
+		t.Fatalf("expected deleted watch file removed, got %v", err)
+	}
+}
+
+func TestDirStoreIgnoresTornWriteAndMigratesOldRevisions(t *testing.T) {
+	d := DirStore{Dir: t.TempDir()}
+	legacy := `{"schema_version":0,"saved_at":"2026-02-19T22:00:00Z","watch":{"id":"w_1","name":"athens","last_lowest_price":812}}` + "\n" + `{"schema_version":1,"watch":{"id":"w_1","na`
+	if err := os.WriteFile(filepath.Join(d.Dir, "w_1.jsonl"), []byte(legacy), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	res, err := d.PendingMigrations()
+	if err != nil || res.FromVersion != 0 || len(res.Pending) != 1 {
+		t.Fatalf("unexpected pending: %+v err=%v", res, err)
+	}
+	if _, err := d.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ws, err := d.Load()
+	if err != nil || len(ws.Watches) != 1 {
+		t.Fatalf("load: %+v err=%v", ws, err)
+	}
+	if w := ws.Watches[0]; w.AlertState.Status != "armed" || w.LastLowestPrice != 812 {
+		t.Fatalf("unexpected migrated watch: %+v", w)
+	}
+	b, _ := os.ReadFile(filepath.Join(d.Dir, "w_1.jsonl"))
+	if !strings.HasPrefix(string(b), legacy+"\n{\"schema_version\":1,") {
+		t.Fatalf("expected migrated revision appended after the torn line, got %q", b)
+	}
+	if res, _ := d.PendingMigrations(); len(res.Pending) != 0 {
+		t.Fatalf("expected no pending migrations, got %+v", res)
+	}
+}
+
+func TestDirStoreCompactsRevisionsAndRemovesWatchDir(t *testing.T) {
+	st, _ := Open(t.TempDir(), BackendJSONLDir)
+	d := st.Watches.(DirStore)
+	ws := model.WatchStore{Watches: []model.Watch{{ID: "w_1", Name: strings.Repeat("a", 1024)}}}
+	for i := range 100 {
+		ws.Watches[0].LastLowestPrice = i + 1
+		if err := d.Save(ws); err != nil {
+			t.Fatalf("save %d: %v", i, err)
+		}
+	}
+	path := filepath.Join(d.Dir, "w_1.jsonl")
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > compactBytes {
+		t.Fatalf("expected a compacted watch file, got %v err=%v", info, err)
+	}
+	got, err := d.Load()
+	if err != nil || got.Watches[0].LastLowestPrice != 100 {
+		t.Fatalf("expected the latest revision kept, got %+v err=%v", got.Watches, err)
+	}
+
+	if err := st.History.Append("w_1", model.PriceHistoryEntry{LowestPrice: 640}); err != nil {
+		t.Fatalf("append history: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(d.Dir, "w_1", "history.jsonl")); err != nil {
+		t.Fatalf("expected history in the watch directory: %v", err)
+	}
+	if err := d.Save(model.WatchStore{}); err != nil {
+		t.Fatalf("save after delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(d.Dir, "w_1")); !os.IsNotExist(err) {
+		t.Fatalf("expected the watch directory removed, got %v", err)
+	}
+}
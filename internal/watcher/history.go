@@ -0,0 +1,207 @@
+package watcher
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+// HistoryStore keeps one append-only JSONL file of price observations per
+// watch: <Dir>/<id>.jsonl, or <Dir>/<id>/<File> when File is set.
+type HistoryStore struct {
+	Dir  string
+	File string
+}
+
+func (h HistoryStore) path(watchID string) (string, error) {
+	return watchPath(h.Dir, watchID, ".jsonl", h.File)
+}
+
+// watchFile maps a watch ID to a file inside dir, rejecting IDs that would
+// escape it.
+func watchFile(dir, watchID, ext string) (string, error) {
+	if watchID == "" || filepath.Base(watchID) != watchID || watchID == "." || watchID == ".." {
+		return "", fmt.Errorf("invalid watch id: %q", watchID)
+	}
+	return filepath.Join(dir, watchID+ext), nil
+}
+
+// watchPath is watchFile, or <dir>/<id>/<name> for layouts that give each
+// watch its own directory.
+func watchPath(dir, watchID, ext, name string) (string, error) {
+	if name == "" {
+		return watchFile(dir, watchID, ext)
+	}
+	sub, err := watchFile(dir, watchID, "")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(sub, name), nil
+}
+
+// removeWatchFile deletes path and, in the per-watch directory layout, the
+// watch's directory once it is empty.
+func removeWatchFile(path, name string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if name != "" {
+		_ = os.Remove(filepath.Dir(path))
+	}
+	return nil
+}
+
+// Append records entry through appendLine, which syncs the write and starts
+// a fresh line after one a crash cut short.
+func (h HistoryStore) Append(watchID string, entry model.PriceHistoryEntry) error {
+	path, err := h.path(watchID)
+	if err != nil {
+		return err
+	}
+	unlock, err := h.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return appendEntry(path, entry)
+}
+
+// Load returns a watch's entries in the order they were recorded. Lines cut
+// short by a crash mid-write are skipped; any other bad line is an error.
+func (h HistoryStore) Load(watchID string) ([]model.PriceHistoryEntry, error) {
+	path, err := h.path(watchID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []model.PriceHistoryEntry{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []model.PriceHistoryEntry{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e model.PriceHistoryEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			if tornLine(err) {
+				continue
+			}
+			return nil, fmt.Errorf("parse %s line %d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Prune drops entries checked before cutoff and reports how many were removed.
+// The file is only rewritten when something is removed.
+func (h HistoryStore) Prune(watchID string, cutoff time.Time) (int, error) {
+	path, err := h.path(watchID)
+	if err != nil {
+		return 0, err
+	}
+	unlock, err := h.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	entries, err := h.Load(watchID)
+	if err != nil {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	kept := 0
+	for _, e := range entries {
+		if e.CheckedAt.Before(cutoff) {
+			continue
+		}
+		b, err := json.Marshal(e)
+		if err != nil {
+			return 0, err
+		}
+		buf.Write(append(b, '\n'))
+		kept++
+	}
+	removed := len(entries) - kept
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := writeFileAtomic(path, buf.Bytes(), 0o600); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// Oldest returns when the first entry of a watch's history was checked, or
+// the zero time when it has none.
+func (h HistoryStore) Oldest(watchID string) (time.Time, error) {
+	path, err := h.path(watchID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e model.PriceHistoryEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			if tornLine(err) {
+				continue
+			}
+			return time.Time{}, fmt.Errorf("parse %s: %w", path, err)
+		}
+		return e.CheckedAt, nil
+	}
+	return time.Time{}, sc.Err()
+}
+
+// tornLine reports whether err came from a JSON line that ends early, as a
+// write interrupted by a crash leaves it.
+func tornLine(err error) bool {
+	var syntax *json.SyntaxError
+	return errors.As(err, &syntax) && syntax.Error() == "unexpected end of JSON input"
+}
+
+// lock serializes writers of the history directory, so an Append cannot land
+// between Prune's read and its rewrite.
+func (h HistoryStore) lock() (func(), error) {
+	return lockFile(filepath.Join(h.Dir, ".lock"), 0)
+}
+
+func (h HistoryStore) Delete(watchID string) error {
+	path, err := h.path(watchID)
+	if err != nil {
+		return err
+	}
+	return removeWatchFile(path, h.File)
+}
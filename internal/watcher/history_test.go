@@ -0,0 +1,167 @@
+package watcher
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestHistoryStoreAppendLoadAndPrune(t *testing.T) {
+	h := HistoryStore{Dir: t.TempDir()}
+	base := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	for i, price := range []int{900, 850, 800} {
+		entry := model.PriceHistoryEntry{
+			CheckedAt:    base.AddDate(0, 0, i),
+			LowestPrice:  price,
+			Currency:     "USD",
+			FlightCount:  3,
+			TopItinerary: &model.Flight{Airline: "Aegean", Price: price},
+		}
+		if err := h.Append("w_1", entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	entries, err := h.Load("w_1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(entries) != 3 || entries[2].LowestPrice != 800 || entries[2].TopItinerary == nil {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	removed, err := h.Prune("w_1", base.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected 1 pruned entry, got %d", removed)
+	}
+	entries, _ = h.Load("w_1")
+	if len(entries) != 2 || entries[0].LowestPrice != 850 {
+		t.Fatalf("unexpected entries after prune: %+v", entries)
+	}
+
+	if err := h.Delete("w_1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	entries, err = h.Load("w_1")
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected empty history after delete, got %+v err=%v", entries, err)
+	}
+}
+
+func TestHistoryStoreSurvivesTornWrite(t *testing.T) {
+	h := HistoryStore{Dir: t.TempDir()}
+	base := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	if err := h.Append("w_1", model.PriceHistoryEntry{CheckedAt: base, LowestPrice: 900}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	path, _ := h.path("w_1")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := f.WriteString(`{"checked_at":"2026-02-20T22:00:00Z","lowest_pr`); err != nil {
+		t.Fatalf("write torn line: %v", err)
+	}
+	_ = f.Close()
+	entries, err := h.Load("w_1")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected the torn tail skipped, got %+v err=%v", entries, err)
+	}
+	if err := h.Append("w_1", model.PriceHistoryEntry{CheckedAt: base.AddDate(0, 0, 2), LowestPrice: 800}); err != nil {
+		t.Fatalf("append after torn write: %v", err)
+	}
+	entries, err = h.Load("w_1")
+	if err != nil || len(entries) != 2 || entries[1].LowestPrice != 800 {
+		t.Fatalf("expected the next append on a fresh line, got %+v err=%v", entries, err)
+	}
+
+	if err := os.WriteFile(path, []byte("{\"checked_at\":42}\n"), 0o600); err != nil {
+		t.Fatalf("write bad line: %v", err)
+	}
+	if _, err := h.Load("w_1"); err == nil {
+		t.Fatalf("expected a malformed complete line to fail")
+	}
+}
+
+func TestHistoryStorePruneKeepsConcurrentAppends(t *testing.T) {
+	h := HistoryStore{Dir: t.TempDir()}
+	base := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		if err := h.Append("w_1", model.PriceHistoryEntry{CheckedAt: base.AddDate(0, 0, -30-i)}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if oldest, err := h.Oldest("w_1"); err != nil || !oldest.Equal(base.AddDate(0, 0, -30)) {
+		t.Fatalf("Oldest = %v, %v", oldest, err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := h.Append("w_1", model.PriceHistoryEntry{CheckedAt: base.Add(time.Duration(i) * time.Minute)}); err != nil {
+				t.Errorf("append: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := h.Prune("w_1", base.AddDate(0, 0, -1)); err != nil {
+				t.Errorf("prune: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	entries, err := h.Load("w_1")
+	if err != nil || len(entries) != 20 {
+		t.Fatalf("expected the 20 recent entries to survive pruning, got %d err=%v", len(entries), err)
+	}
+	files, _ := os.ReadDir(h.Dir)
+	for _, f := range files {
+		if f.Name() != "w_1.jsonl" && f.Name() != ".lock" {
+			t.Fatalf("unexpected leftover file %s", f.Name())
+		}
+	}
+}
+
+func TestHistoryStoreRejectsPathLikeIDs(t *testing.T) {
+	h := HistoryStore{Dir: t.TempDir()}
+	for _, id := range []string{"", "..", "../w_1", "a/b"} {
+		if err := h.Append(id, model.PriceHistoryEntry{}); err == nil {
+			t.Fatalf("expected error for id %q", id)
+		}
+	}
+}
+
+func TestSnapshotStoreRoundTrip(t *testing.T) {
+	s := SnapshotStore{Dir: t.TempDir()}
+	if snap, err := s.Load("w_1"); err != nil || snap != nil {
+		t.Fatalf("expected no snapshot, got %+v err=%v", snap, err)
+	}
+	want := model.WatchSnapshot{
+		WatchID:   "w_1",
+		CheckedAt: time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC),
+		Result:    &model.SearchResult{Flights: []model.Flight{{Airline: "Aegean", Price: 640}}},
+		Alert:     &model.Alert{WatchID: "w_1", Reason: "target_price: price reached target <= 700"},
+	}
+	if err := s.Save(want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := s.Load("w_1")
+	if err != nil || got == nil {
+		t.Fatalf("load: %+v err=%v", got, err)
+	}
+	if !got.CheckedAt.Equal(want.CheckedAt) || got.Result.Flights[0].Price != 640 || got.Alert.Reason != want.Alert.Reason {
+		t.Fatalf("unexpected snapshot: %+v", got)
+	}
+	if err := s.Delete("w_1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := s.Save(model.WatchSnapshot{WatchID: "../x"}); err == nil {
+		t.Fatalf("expected path-like id to be rejected")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package watcher
+
+import "os"
+
+// Advisory locking is only implemented on unix; elsewhere writes are still
+// atomic but concurrent commands are not serialized.
+func tryLockFile(f *os.File) (bool, error) { return true, nil }
+
+func unlockFile(f *os.File) error { return nil }
@@ -0,0 +1,190 @@
+package watcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+// CurrentSchemaVersion is the watch store schema written by this build.
+const CurrentSchemaVersion = 1
+
+// ErrUnsupportedSchema is returned when the store was written by a newer gflight.
+var ErrUnsupportedSchema = errors.New("watch store schema is newer than this gflight supports")
+
+// Migration upgrades a raw store document from Version-1 to Version.
+type Migration struct {
+	Version     int                            `json:"version"`
+	Description string                         `json:"description"`
+	Apply       func(doc map[string]any) error `json:"-"`
+}
+
+// migrations must stay ordered by Version with no gaps.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "add schema_version, arm watches without an alert state, default rearm_percent",
+		Apply:       migrateV1,
+	},
+}
+
+// MigrationResult describes a store upgrade.
+type MigrationResult struct {
+	FromVersion int         `json:"from_version"`
+	ToVersion   int         `json:"to_version"`
+	Pending     []Migration `json:"pending"`
+	BackupPath  string      `json:"backup_path,omitempty"`
+}
+
+// PendingMigrations reports the on-disk schema version and the migrations
+// Load would apply. A missing store needs none.
+func (s Store) PendingMigrations() (MigrationResult, error) {
+	b, err := os.ReadFile(s.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return MigrationResult{FromVersion: CurrentSchemaVersion, ToVersion: CurrentSchemaVersion, Pending: []Migration{}}, nil
+		}
+		return MigrationResult{}, err
+	}
+	version, err := schemaVersion(b)
+	if err != nil {
+		return MigrationResult{}, err
+	}
+	pending, err := pendingMigrations(version)
+	if err != nil {
+		return MigrationResult{}, err
+	}
+	return MigrationResult{FromVersion: version, ToVersion: CurrentSchemaVersion, Pending: pending}, nil
+}
+
+// Migrate upgrades the store file on disk under the lock, backing up the
+// original first.
+func (s Store) Migrate() (MigrationResult, error) {
+	res, err := migrate(s)
+	if err == nil && len(res.Pending) > 0 {
+		res.BackupPath = s.BackupPath(res.FromVersion)
+	}
+	return res, err
+}
+
+// migrate persists the upgrade Load performs in memory.
+func migrate(b Backend) (MigrationResult, error) {
+	unlock, err := b.Lock()
+	if err != nil {
+		return MigrationResult{}, err
+	}
+	defer unlock()
+	res, err := b.PendingMigrations()
+	if err != nil || len(res.Pending) == 0 {
+		return res, err
+	}
+	ws, err := b.Load()
+	if err != nil {
+		return res, err
+	}
+	return res, b.Save(ws)
+}
+
+// BackupPath is where Load keeps the original file before upgrading from version.
+func (s Store) BackupPath(version int) string {
+	return fmt.Sprintf("%s.v%d.bak", s.Path, version)
+}
+
+// upgrade migrates raw store bytes to CurrentSchemaVersion, backing up the
+// original first. Current files are returned unchanged.
+func (s Store) upgrade(b []byte) ([]byte, error) {
+	version, err := schemaVersion(b)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := pendingMigrations(version)
+	if err != nil || len(pending) == 0 {
+		return b, err
+	}
+	if err := s.backup(version, b); err != nil {
+		return nil, fmt.Errorf("back up watch store before migrating: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := applyMigrations(doc, pending); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// applyMigrations upgrades a raw {"schema_version", "watches"} document in place.
+func applyMigrations(doc map[string]any, pending []Migration) error {
+	for _, m := range pending {
+		if err := m.Apply(doc); err != nil {
+			return fmt.Errorf("migrate watch store to schema v%d: %w", m.Version, err)
+		}
+		doc["schema_version"] = m.Version
+	}
+	return nil
+}
+
+func (s Store) backup(version int, original []byte) error {
+	path := s.BackupPath(version)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, original) {
+		return nil
+	}
+	return writeFileAtomic(path, original, 0o600)
+}
+
+func schemaVersion(b []byte) (int, error) {
+	var head struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(b, &head); err != nil {
+		return 0, err
+	}
+	return head.SchemaVersion, nil
+}
+
+func pendingMigrations(version int) ([]Migration, error) {
+	if version > CurrentSchemaVersion {
+		return nil, fmt.Errorf("%w (file is v%d, supported up to v%d)", ErrUnsupportedSchema, version, CurrentSchemaVersion)
+	}
+	pending := []Migration{}
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// migrateV1 upgrades stores written before schema_version existed.
+func migrateV1(doc map[string]any) error {
+	watches, _ := doc["watches"].([]any)
+	if watches == nil {
+		watches = []any{}
+	}
+	for i, raw := range watches {
+		w, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("watches[%d] is not an object", i)
+		}
+		state, _ := w["alert_state"].(map[string]any)
+		if state == nil {
+			state = map[string]any{}
+		}
+		if status, _ := state["status"].(string); status == "" {
+			state["status"] = "armed"
+		}
+		w["alert_state"] = state
+		if _, ok := w["rearm_percent"]; !ok {
+			w["rearm_percent"] = model.DefaultRearmPercent
+		}
+	}
+	doc["watches"] = watches
+	return nil
+}
@@ -0,0 +1,64 @@
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+const legacyStore = `{
+  "watches": [
+    {"id": "w_1", "name": "athens", "query": {"from": "SFO", "to": "ATH", "depart": "2026-06-10"}, "enabled": true, "target_price": 700, "last_lowest_price": 812}
+  ]
+}
+`
+
+func TestStoreLoadMigratesLegacyStore(t *testing.T) {
+	s := Store{Path: filepath.Join(t.TempDir(), "watches.json")}
+	if err := os.WriteFile(s.Path, []byte(legacyStore), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	res, err := s.PendingMigrations()
+	if err != nil || res.FromVersion != 0 || len(res.Pending) != 1 {
+		t.Fatalf("unexpected pending migrations: %+v err=%v", res, err)
+	}
+
+	ws, err := s.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if ws.SchemaVersion != CurrentSchemaVersion || len(ws.Watches) != 1 {
+		t.Fatalf("unexpected migrated store: %+v", ws)
+	}
+	w := ws.Watches[0]
+	if w.AlertState.Status != "armed" || w.RearmPercent != model.DefaultRearmPercent || w.LastLowestPrice != 812 || w.Query.To != "ATH" {
+		t.Fatalf("unexpected migrated watch: %+v", w)
+	}
+	backup, err := os.ReadFile(s.BackupPath(0))
+	if err != nil || string(backup) != legacyStore {
+		t.Fatalf("expected original backed up, got %q err=%v", backup, err)
+	}
+
+	res, err = s.Migrate()
+	if err != nil || res.BackupPath != s.BackupPath(0) {
+		t.Fatalf("migrate: %+v err=%v", res, err)
+	}
+	if res, _ := s.PendingMigrations(); len(res.Pending) != 0 || res.FromVersion != CurrentSchemaVersion {
+		t.Fatalf("expected store up to date after migrate, got %+v", res)
+	}
+}
+
+func TestStoreLoadRejectsNewerSchema(t *testing.T) {
+	s := Store{Path: filepath.Join(t.TempDir(), "watches.json")}
+	if err := os.WriteFile(s.Path, []byte(`{"schema_version": 99, "watches": []}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, err := s.Load()
+	if !errors.Is(err, ErrUnsupportedSchema) || !strings.Contains(err.Error(), "v99") {
+		t.Fatalf("expected unsupported schema error, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+// MoveResult describes moving a state directory from one backend to another.
+type MoveResult struct {
+	From           string   `json:"from_backend"`
+	To             string   `json:"to_backend"`
+	Watches        int      `json:"watches"`
+	HistoryEntries int      `json:"history_entries"`
+	Snapshots      int      `json:"snapshots"`
+	RunLogEntries  int      `json:"run_log_entries"`
+	MovedAside     []string `json:"moved_aside,omitempty"`
+}
+
+// movedSuffix marks the files of a backend that has been moved, so they are
+// kept for reference but no longer read or detected as stray.
+const movedSuffix = ".moved"
+
+// MoveStorage copies the watches, history, snapshots and run log of src into
+// dst, then renames src's files aside with a .moved suffix. Nothing is copied
+// when a watch ID exists in both or a .moved file is in the way.
+func MoveStorage(src, dst Storage, dryRun bool) (MoveResult, error) {
+	res := MoveResult{From: src.Backend, To: dst.Backend}
+	if src.Backend == dst.Backend {
+		return res, fmt.Errorf("storage is already %s", dst.Backend)
+	}
+	unlockSrc, err := src.Watches.Lock()
+	if err != nil {
+		return res, err
+	}
+	defer unlockSrc()
+	unlockDst, err := dst.Watches.Lock()
+	if err != nil {
+		return res, err
+	}
+	defer unlockDst()
+
+	in, err := src.Watches.Load()
+	if err != nil {
+		return res, err
+	}
+	cur, err := dst.Watches.Load()
+	if err != nil {
+		return res, err
+	}
+	var conflicts []string
+	for _, w := range in.Watches {
+		if slices.ContainsFunc(cur.Watches, func(c model.Watch) bool { return c.ID == w.ID }) {
+			conflicts = append(conflicts, w.ID)
+		}
+	}
+	if len(conflicts) > 0 {
+		return res, fmt.Errorf("watch ids already in %s storage: %s", dst.Backend, strings.Join(conflicts, ", "))
+	}
+	for _, root := range src.roots {
+		if _, err := os.Stat(root + movedSuffix); err == nil {
+			return res, fmt.Errorf("%s already exists; move it away first", root+movedSuffix)
+		}
+	}
+
+	res.Watches = len(in.Watches)
+	histories := make([][]model.PriceHistoryEntry, len(in.Watches))
+	snapshots := make([]*model.WatchSnapshot, len(in.Watches))
+	for i, w := range in.Watches {
+		if histories[i], err = src.History.Load(w.ID); err != nil {
+			return res, err
+		}
+		if snapshots[i], err = src.Snapshots.Load(w.ID); err != nil {
+			return res, err
+		}
+		res.HistoryEntries += len(histories[i])
+		if snapshots[i] != nil {
+			res.Snapshots++
+		}
+	}
+	runs, err := src.RunLog.Load()
+	if err != nil {
+		return res, err
+	}
+	res.RunLogEntries = len(runs)
+	if dryRun {
+		return res, nil
+	}
+
+	for i, w := range in.Watches {
+		for _, e := range histories[i] {
+			if err := dst.History.Append(w.ID, e); err != nil {
+				return res, err
+			}
+		}
+		if snapshots[i] != nil {
+			if err := dst.Snapshots.Save(*snapshots[i]); err != nil {
+				return res, err
+			}
+		}
+	}
+	for _, e := range runs {
+		if err := dst.RunLog.Append(e); err != nil {
+			return res, err
+		}
+	}
+	cur.Watches = append(cur.Watches, in.Watches...)
+	if err := dst.Watches.Save(cur); err != nil {
+		return res, err
+	}
+	for _, root := range src.roots {
+		if err := os.Rename(root, root+movedSuffix); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return res, err
+		}
+		res.MovedAside = append(res.MovedAside, root+movedSuffix)
+	}
+	return res, nil
+}
@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestMoveStorageFromJSONToDir(t *testing.T) {
+	stateDir := t.TempDir()
+	src, _ := Open(stateDir, BackendJSON)
+	dst, _ := Open(stateDir, BackendJSONLDir)
+	checked := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	if err := src.Watches.Save(model.WatchStore{Watches: []model.Watch{{ID: "w_1", Name: "athens"}}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := src.History.Append("w_1", model.PriceHistoryEntry{CheckedAt: checked, LowestPrice: 640}); err != nil {
+		t.Fatalf("append history: %v", err)
+	}
+	if err := src.Snapshots.Save(model.WatchSnapshot{WatchID: "w_1", CheckedAt: checked}); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+	if err := src.RunLog.Append(model.RunLogEntry{StartedAt: checked, Mode: "run"}); err != nil {
+		t.Fatalf("append run: %v", err)
+	}
+	if other, n, err := StrayWatches(stateDir, BackendJSONLDir); other != BackendJSON || n != 1 || err != nil {
+		t.Fatalf("expected 1 stray json watch, got %q %d err=%v", other, n, err)
+	}
+
+	res, err := MoveStorage(src, dst, true)
+	if err != nil || res.Watches != 1 || res.HistoryEntries != 1 || res.Snapshots != 1 || res.RunLogEntries != 1 || len(res.MovedAside) != 0 {
+		t.Fatalf("unexpected dry run: %+v err=%v", res, err)
+	}
+	if _, err := os.Stat(dst.Watches.Location()); !os.IsNotExist(err) {
+		t.Fatalf("dry run wrote the target store: %v", err)
+	}
+
+	if res, err = MoveStorage(src, dst, false); err != nil || len(res.MovedAside) != 4 {
+		t.Fatalf("move: %+v err=%v", res, err)
+	}
+	ws, err := dst.Watches.Load()
+	if err != nil || len(ws.Watches) != 1 || ws.Watches[0].Name != "athens" {
+		t.Fatalf("unexpected moved watches: %+v err=%v", ws, err)
+	}
+	if h, _ := dst.History.Load("w_1"); len(h) != 1 || h[0].LowestPrice != 640 {
+		t.Fatalf("unexpected moved history: %+v", h)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "watches", "w_1", "snapshot.json")); err != nil {
+		t.Fatalf("expected snapshot in the watch directory: %v", err)
+	}
+	if runs, _ := dst.RunLog.Load(); len(runs) != 1 {
+		t.Fatalf("unexpected moved run log: %+v", runs)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "watches.json.moved")); err != nil {
+		t.Fatalf("expected the old store moved aside: %v", err)
+	}
+	if other, n, _ := StrayWatches(stateDir, BackendJSONLDir); other != "" || n != 0 {
+		t.Fatalf("expected no stray watches after the move, got %q %d", other, n)
+	}
+	if _, err := MoveStorage(src, dst, false); err == nil {
+		t.Fatalf("expected a second move to refuse the existing .moved files")
+	}
+}
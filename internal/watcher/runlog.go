@@ -0,0 +1,174 @@
+package watcher
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+// RunLogFile keeps every watch run pass in one append-only JSONL file.
+type RunLogFile struct {
+	Path string
+}
+
+func (r RunLogFile) Append(entry model.RunLogEntry) error {
+	unlock, err := lockFile(r.Path+".lock", 0)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return appendEntry(r.Path, entry)
+}
+
+func (r RunLogFile) Load() ([]model.RunLogEntry, error) {
+	return loadRunLog(r.Path)
+}
+
+// Prune drops passes that started before cutoff, rewriting the file only when
+// its oldest pass is that old.
+func (r RunLogFile) Prune(cutoff time.Time) error {
+	unlock, err := lockFile(r.Path+".lock", 0)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	entries, err := loadRunLog(r.Path)
+	if err != nil || len(entries) == 0 || !entries[0].StartedAt.Before(cutoff) {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, e := range entries {
+		if e.StartedAt.Before(cutoff) {
+			continue
+		}
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(b, '\n'))
+	}
+	return writeFileAtomic(r.Path, buf.Bytes(), 0o600)
+}
+
+// RunLogDir keeps one JSONL file of run passes per UTC day,
+// <Dir>/<YYYY-MM-DD>.jsonl, so pruning deletes whole files and past days are
+// never rewritten.
+type RunLogDir struct {
+	Dir string
+}
+
+func (r RunLogDir) Append(entry model.RunLogEntry) error {
+	unlock, err := lockFile(filepath.Join(r.Dir, ".lock"), 0)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return appendEntry(filepath.Join(r.Dir, entry.StartedAt.UTC().Format(time.DateOnly)+".jsonl"), entry)
+}
+
+func (r RunLogDir) Load() ([]model.RunLogEntry, error) {
+	days, err := r.days()
+	if err != nil {
+		return nil, err
+	}
+	out := []model.RunLogEntry{}
+	for _, day := range days {
+		entries, err := loadRunLog(filepath.Join(r.Dir, day+".jsonl"))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, entries...)
+	}
+	return out, nil
+}
+
+// Prune deletes the files of days that ended before cutoff.
+func (r RunLogDir) Prune(cutoff time.Time) error {
+	days, err := r.days()
+	if err != nil {
+		return err
+	}
+	for _, day := range days {
+		d, _ := time.Parse(time.DateOnly, day)
+		if d.AddDate(0, 0, 1).After(cutoff) {
+			break
+		}
+		if err := os.Remove(filepath.Join(r.Dir, day+".jsonl")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// days lists the dates that have a run log file, oldest first.
+func (r RunLogDir) days() ([]string, error) {
+	entries, err := os.ReadDir(r.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var days []string
+	for _, e := range entries {
+		day, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, day); err == nil {
+			days = append(days, day)
+		}
+	}
+	slices.Sort(days)
+	return days, nil
+}
+
+func appendEntry(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return appendLine(path, b)
+}
+
+func loadRunLog(path string) ([]model.RunLogEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []model.RunLogEntry{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	entries := []model.RunLogEntry{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e model.RunLogEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			if tornLine(err) {
+				continue
+			}
+			return nil, fmt.Errorf("parse %s line %d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
@@ -0,0 +1,83 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestRunLogAppendLoadAndPrune(t *testing.T) {
+	base := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	for name, log := range map[string]RunLogBackend{
+		"file": RunLogFile{Path: filepath.Join(dir, "runs.jsonl")},
+		"dir":  RunLogDir{Dir: filepath.Join(dir, "runs")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got, err := log.Load(); err != nil || len(got) != 0 {
+				t.Fatalf("expected empty log, got %+v err=%v", got, err)
+			}
+			for i := range 3 {
+				start := base.AddDate(0, 0, i)
+				entry := model.RunLogEntry{StartedAt: start, FinishedAt: start.Add(time.Second), Mode: "run", Evaluated: i + 1}
+				if err := log.Append(entry); err != nil {
+					t.Fatalf("append: %v", err)
+				}
+			}
+			got, err := log.Load()
+			if err != nil || len(got) != 3 || got[0].Evaluated != 1 || got[2].Evaluated != 3 {
+				t.Fatalf("unexpected log: %+v err=%v", got, err)
+			}
+			if err := log.Prune(base.AddDate(0, 0, 1)); err != nil {
+				t.Fatalf("prune: %v", err)
+			}
+			got, _ = log.Load()
+			if len(got) != 2 || got[0].Evaluated != 2 {
+				t.Fatalf("expected the first pass pruned, got %+v", got)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(dir, "runs", "2026-02-19.jsonl")); !os.IsNotExist(err) {
+		t.Fatalf("expected pruned day file removed, got %v", err)
+	}
+}
+
+func TestRunLogSurvivesTornWrite(t *testing.T) {
+	base := time.Date(2026, 2, 19, 22, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	for name, tc := range map[string]struct {
+		log  RunLogBackend
+		path string
+	}{
+		"file": {RunLogFile{Path: filepath.Join(dir, "runs.jsonl")}, filepath.Join(dir, "runs.jsonl")},
+		"dir":  {RunLogDir{Dir: filepath.Join(dir, "runs")}, filepath.Join(dir, "runs", "2026-02-19.jsonl")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.log.Append(model.RunLogEntry{StartedAt: base, Mode: "run", Evaluated: 1}); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			f, err := os.OpenFile(tc.path, os.O_APPEND|os.O_WRONLY, 0o600)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if _, err := f.WriteString(`{"started_at":"2026-02-19T23:00:00Z","mo`); err != nil {
+				t.Fatalf("write torn line: %v", err)
+			}
+			_ = f.Close()
+			got, err := tc.log.Load()
+			if err != nil || len(got) != 1 {
+				t.Fatalf("expected the torn tail skipped, got %+v err=%v", got, err)
+			}
+			if err := tc.log.Append(model.RunLogEntry{StartedAt: base.Add(2 * time.Hour), Mode: "run", Evaluated: 2}); err != nil {
+				t.Fatalf("append after torn write: %v", err)
+			}
+			got, err = tc.log.Load()
+			if err != nil || len(got) != 2 || got[1].Evaluated != 2 {
+				t.Fatalf("expected the next append on a fresh line, got %+v err=%v", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package watcher
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+// SnapshotStore keeps the latest evaluation of each watch in <Dir>/<id>.json,
+// or <Dir>/<id>/<File> when File is set.
+type SnapshotStore struct {
+	Dir  string
+	File string
+}
+
+func (s SnapshotStore) path(watchID string) (string, error) {
+	return watchPath(s.Dir, watchID, ".json", s.File)
+}
+
+func (s SnapshotStore) Save(snap model.WatchSnapshot) error {
+	path, err := s.path(snap.WatchID)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(b, '\n'), 0o600)
+}
+
+// Load returns the watch's latest snapshot, or nil if it has never been evaluated.
+func (s SnapshotStore) Load(watchID string) (*model.WatchSnapshot, error) {
+	path, err := s.path(watchID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snap model.WatchSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func (s SnapshotStore) Delete(watchID string) error {
+	path, err := s.path(watchID)
+	if err != nil {
+		return err
+	}
+	return removeWatchFile(path, s.File)
+}
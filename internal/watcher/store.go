@@ -3,16 +3,31 @@ package watcher
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/agisilaos/gflight/internal/model"
 )
 
+const (
+	DefaultLockTimeout = 10 * time.Second
+	lockRetryInterval  = 50 * time.Millisecond
+)
+
+// ErrLocked is returned when another process holds the store lock for longer
+// than the lock timeout.
+var ErrLocked = errors.New("watch store is locked by another gflight process")
+
 type Store struct {
 	Path string
+	// LockTimeout bounds how long Lock waits; zero means DefaultLockTimeout.
+	LockTimeout time.Duration
 }
 
+// Load reads the store, migrating older schemas in memory. The upgraded file
+// is written by the next Save; the original is backed up first.
 func (s Store) Load() (model.WatchStore, error) {
 	var ws model.WatchStore
 	b, err := os.ReadFile(s.Path)
@@ -23,6 +38,9 @@ func (s Store) Load() (model.WatchStore, error) {
 		}
 		return ws, err
 	}
+	if b, err = s.upgrade(b); err != nil {
+		return ws, err
+	}
 	if err := json.Unmarshal(b, &ws); err != nil {
 		return ws, err
 	}
@@ -33,13 +51,78 @@ func (s Store) Load() (model.WatchStore, error) {
 }
 
 func (s Store) Save(ws model.WatchStore) error {
-	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
-		return err
-	}
+	ws.SchemaVersion = CurrentSchemaVersion
 	b, err := json.MarshalIndent(ws, "", "  ")
 	if err != nil {
 		return err
 	}
 	b = append(b, '\n')
-	return os.WriteFile(s.Path, b, 0o600)
+	return writeFileAtomic(s.Path, b, 0o600)
+}
+
+// Lock takes the advisory lock guarding read-modify-write transactions on the
+// store. Callers must hold it from Load until Save and call the returned
+// function when done.
+func (s Store) Lock() (func(), error) {
+	return lockFile(s.Path+".lock", s.LockTimeout)
+}
+
+func (s Store) Location() string {
+	return s.Path
+}
+
+// lockFile takes an advisory lock on lockPath, retrying until timeout (zero
+// means DefaultLockTimeout).
+func lockFile(lockPath string, timeout time.Duration) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if ok {
+			return func() {
+				_ = unlockFile(f)
+				_ = f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, fmt.Errorf("%w (waited %s for %s)", ErrLocked, timeout, lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Update runs fn on the current store contents under the lock and saves the
+// result unless fn returns an error.
+func (s Store) Update(fn func(*model.WatchStore) error) error {
+	return update(s, fn)
+}
+
+func update(b Backend, fn func(*model.WatchStore) error) error {
+	unlock, err := b.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	ws, err := b.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(&ws); err != nil {
+		return err
+	}
+	return b.Save(ws)
 }
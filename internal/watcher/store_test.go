@@ -0,0 +1,60 @@
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agisilaos/gflight/internal/model"
+)
+
+func TestStoreLockTimesOutWhileHeld(t *testing.T) {
+	s := Store{Path: filepath.Join(t.TempDir(), "watches.json"), LockTimeout: 100 * time.Millisecond}
+	unlock, err := s.Lock()
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if _, err := s.Lock(); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked while held, got %v", err)
+	}
+	unlock()
+	again, err := s.Lock()
+	if err != nil {
+		t.Fatalf("lock after release: %v", err)
+	}
+	again()
+}
+
+func TestStoreUpdateSavesAtomically(t *testing.T) {
+	dir := t.TempDir()
+	s := Store{Path: filepath.Join(dir, "watches.json")}
+	for _, id := range []string{"w_1", "w_2"} {
+		err := s.Update(func(ws *model.WatchStore) error {
+			ws.Watches = append(ws.Watches, model.Watch{ID: id})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+	boom := errors.New("boom")
+	if err := s.Update(func(ws *model.WatchStore) error { ws.Watches = nil; return boom }); !errors.Is(err, boom) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+	ws, err := s.Load()
+	if err != nil || len(ws.Watches) != 2 {
+		t.Fatalf("expected both watches kept, got %+v err=%v", ws.Watches, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".tmp" {
+			t.Fatalf("temp file left behind: %s", e.Name())
+		}
+	}
+	info, err := os.Stat(s.Path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 store file, got %v err=%v", info.Mode(), err)
+	}
+}
@@ -58,6 +58,9 @@ fi
 echo "[release-check] running tests"
 go test ./...
 
+echo "[release-check] running concurrency tests under the race detector"
+go test -race ./internal/cli/ ./internal/watcher/
+
 echo "[release-check] running vet"
 go vet ./...
 